@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/nicholasraynes/northwind-api/internal/auth"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// authenticators lists the ways a caller may prove who they are: always an
+// API key, and an OAuth bearer token when JWT_JWKS names a key set.
+//
+//	JWT_JWKS       URL or file path of the JWKS tokens are signed with
+//	JWT_ISSUER     required iss, e.g. https://login.microsoftonline.com/<tenant>/v2.0
+//	JWT_AUDIENCE   accepted aud values, comma-separated
+//	JWT_SCOPE_MAP  claim=scope pairs, comma-separated, granting scopes for
+//	               values of the scp, scope and roles claims
+//	JWT_ROW_CLAIMS claim=dimension pairs, comma-separated, confining callers
+//	               whose tokens carry the claim to the rows it names
+func authenticators(ctx context.Context, st store.KeyStore) ([]auth.Authenticator, error) {
+	auths := []auth.Authenticator{auth.NewAPIKeys(st)}
+
+	source := os.Getenv("JWT_JWKS")
+	if source == "" {
+		return auths, nil
+	}
+	keys, err := auth.LoadKeySet(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	scopeMap, err := auth.ParseScopeMap(os.Getenv("JWT_SCOPE_MAP"))
+	if err != nil {
+		return nil, err
+	}
+	rowClaims, err := auth.ParseRowClaims(os.Getenv("JWT_ROW_CLAIMS"))
+	if err != nil {
+		return nil, err
+	}
+	var audiences []string
+	for _, aud := range strings.Split(os.Getenv("JWT_AUDIENCE"), ",") {
+		if aud = strings.TrimSpace(aud); aud != "" {
+			audiences = append(audiences, aud)
+		}
+	}
+
+	bearer, err := auth.NewBearer(auth.BearerConfig{
+		Keys:      keys,
+		Issuer:    os.Getenv("JWT_ISSUER"),
+		Audiences: audiences,
+		ScopeMap:  scopeMap,
+		RowClaims: rowClaims,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(auths, bearer), nil
+}
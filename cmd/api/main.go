@@ -1,53 +1,190 @@
 package main
 
 import (
-	"log"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 
+	"github.com/nicholasraynes/northwind-api/internal/auth"
 	"github.com/nicholasraynes/northwind-api/internal/db"
 	"github.com/nicholasraynes/northwind-api/internal/handlers"
+	"github.com/nicholasraynes/northwind-api/internal/logging"
+	"github.com/nicholasraynes/northwind-api/internal/metrics"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+	"github.com/nicholasraynes/northwind-api/internal/store/postgres"
+	"github.com/nicholasraynes/northwind-api/internal/tracing"
 )
 
 func main() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("Warning: .env file not found, using environment variables")
+	transport := flag.String("transport", "http", `"http" serves REST and MCP at /mcp; "stdio" speaks MCP on stdin/stdout`)
+	createKey := flag.String("create-key", "", "create an API key with this name, print it and exit")
+	scopes := flag.String("scopes", string(auth.Admin), "comma-separated scopes of the key made by -create-key")
+	rowScope := flag.String("row-scope", "", "dimension=value pairs confining the key made by -create-key, e.g. employee_id=5")
+	flag.Parse()
+
+	out := os.Stdout
+	if *transport == "stdio" {
+		// stdout carries the MCP stream, so everything else goes to stderr.
+		out = os.Stderr
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// LOG_LEVEL may come from .env, so load it before logging anything.
+	envErr := godotenv.Load()
+	if err := logging.Setup(out, os.Getenv("LOG_LEVEL")); err != nil {
+		fatal("Failed to configure logging", err)
+	}
+	if envErr != nil {
+		slog.Info(".env file not found, using environment variables")
 	}
 
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
+	timeout := handlers.DefaultTimeout
+	if v := os.Getenv("QUERY_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			fatal("Invalid QUERY_TIMEOUT", fmt.Errorf("%q is not a positive duration such as 30s", v))
+		}
+		timeout = d
+	}
+	var proxies []string
+	for _, p := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	db.Connect()
-
-	r := gin.Default()
-	r.GET("/health", handlers.Health)
-	r.GET("/customers", handlers.GetCustomers)
-	r.GET("/orders", handlers.GetOrders)
-	r.GET("/products", handlers.GetProducts)
-	r.GET("/suppliers", handlers.GetSuppliers)
-	r.GET("/orders/details", handlers.GetOrderDetails)
-	r.GET("/summary/sales-by-country", handlers.GetSalesByCountry)
-	r.GET("/summary/sales-by-category", handlers.GetSalesByCategory)
-	r.GET("/summary/sales-by-employee", handlers.GetSalesByEmployee)
-	r.GET("/summary/sales-by-year", handlers.GetSalesByYear)
-	r.GET("/summary/sales-by-shipper", handlers.GetSalesByShipper)
-	r.GET("/analytics/top-customers", handlers.GetTopCustomers)
-	r.GET("/analytics/customer-orders", handlers.GetCustomerOrders)
-	r.GET("/analytics/customer-ltv", handlers.GetCustomerLTV)
-	r.GET("/analytics/customer-retention", handlers.GetCustomerRetention)
-	r.GET("/analytics/top-products", handlers.GetTopProducts)
-	r.GET("/analytics/supplier-performance", handlers.GetSupplierPerformance)
-	r.GET("/analytics/inventory-status", handlers.GetInventoryStatus)
-	r.GET("/analytics/employee-performance", handlers.GetEmployeePerformance)
-	r.GET("/analytics/shipping-costs", handlers.GetShippingCosts)
-
-	err = r.Run(":" + port)
+	shutdownTracing, err := tracing.Setup(ctx, out)
 	if err != nil {
-		log.Fatal(err)
+		fatal("Failed to configure tracing", err)
+	}
+	defer func() {
+		// Flush the spans still batched, even though ctx is done.
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("Failed to flush traces", "error", err)
+		}
+	}()
+
+	database, err := db.Connect(ctx, metrics.DB, tracing.DB, logging.DB)
+	if err != nil {
+		fatal("Failed to connect to database", err)
+	}
+	metrics.Pool(database)
+	st := postgres.New(database)
+	if err := st.Migrate(ctx); err != nil {
+		fatal("Failed to migrate database", err)
+	}
+
+	if *createKey != "" {
+		if err := printNewKey(ctx, st, *createKey, *scopes, *rowScope); err != nil {
+			fatal("Failed to create API key", err)
+		}
+		return
+	}
+
+	auths, err := authenticators(ctx, st)
+	if err != nil {
+		fatal("Failed to configure authentication", err)
+	}
+
+	r, mcpServer, err := newRouter(st, auths, timeout, proxies)
+	if err != nil {
+		fatal("Failed to build router", err)
+	}
+
+	switch *transport {
+	case "stdio":
+		// Whoever can start the process already holds the database
+		// credentials, so the stdio session reads without a key.
+		ctx = auth.WithPrincipal(ctx, &auth.Principal{Subject: "stdio", Name: "stdio", Scopes: []auth.Scope{auth.ReadCore, auth.ReadAnalytics}})
+		if err := mcpServer.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil && err != context.Canceled {
+			fatal("MCP session failed", err)
+		}
+	case "http":
+		// Each tool call checks the scope of its route, so any
+		// authenticated caller may open a session.
+		r.POST("/mcp", handlers.Require(), gin.WrapH(mcpServer))
+		r.GET("/mcp", handlers.Require(), gin.WrapH(mcpServer))
+		r.DELETE("/mcp", handlers.Require(), gin.WrapH(mcpServer))
+
+		// Slow clients cannot hold connections open by trickling headers or
+		// idling between requests. A whole-request read or write deadline
+		// would cut off long reports, exports and MCP streams; the routes'
+		// query timeouts bound those instead.
+		srv := &http.Server{
+			Addr:              ":" + port,
+			Handler:           r,
+			ReadHeaderTimeout: 10 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+		}
+		go func() {
+			<-ctx.Done()
+			srv.Shutdown(context.Background())
+		}()
+		slog.Info("listening", "addr", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fatal("Server failed", err)
+		}
+	default:
+		fatal("Unknown transport", fmt.Errorf("%q is neither http nor stdio", *transport))
+	}
+}
+
+// fatal logs msg with err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// printNewKey creates an API key named name with the comma-separated scopes
+// and optional row scope and prints it, for bootstrapping the first admin key.
+func printNewKey(ctx context.Context, st *postgres.Store, name, scopes, rowScope string) error {
+	var list models.Scopes
+	for _, s := range strings.Split(scopes, ",") {
+		s = strings.TrimSpace(s)
+		if !slices.Contains(auth.Scopes, auth.Scope(s)) {
+			return fmt.Errorf("unknown scope %q", s)
+		}
+		list = append(list, s)
+	}
+	rows, err := models.ParseRowScope(rowScope)
+	if err != nil {
+		return err
+	}
+	if err := store.CheckRowScope(rows); err != nil {
+		return err
+	}
+
+	key, prefix, hash, err := auth.NewKey()
+	if err != nil {
+		return err
+	}
+	k, err := st.CreateAPIKey(ctx, models.APIKey{Name: name, Prefix: prefix, Scopes: list, RowScope: rows}, hash)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created API key %d (%s) with scopes %s", k.KeyID, k.Name, strings.Join(k.Scopes, ","))
+	if len(k.RowScope) > 0 {
+		fmt.Printf(" confined to %s", k.RowScope)
 	}
+	fmt.Printf(":\n%s\n", key)
+	return nil
 }
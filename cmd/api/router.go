@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nicholasraynes/northwind-api/internal/auth"
+	"github.com/nicholasraynes/northwind-api/internal/graph"
+	"github.com/nicholasraynes/northwind-api/internal/handlers"
+	"github.com/nicholasraynes/northwind-api/internal/logging"
+	"github.com/nicholasraynes/northwind-api/internal/mcp"
+	"github.com/nicholasraynes/northwind-api/internal/metrics"
+	"github.com/nicholasraynes/northwind-api/internal/odata"
+	"github.com/nicholasraynes/northwind-api/internal/openapi"
+	"github.com/nicholasraynes/northwind-api/internal/origin"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+	"github.com/nicholasraynes/northwind-api/internal/tracing"
+)
+
+// newRouter registers the REST routes over st, their OpenAPI document, the
+// GraphQL and OData services and the Prometheus metrics at /metrics. Every
+// request is logged, traced, counted and timed by its route, and
+// authenticated by the first of auths to recognise its credentials; each
+// route requires the scope it declares, and /metrics requires read:metrics.
+// A route's queries are canceled once its Timeout passes, or timeout for
+// routes that declare none, as are those of GraphQL and OData requests. The
+// MCP server it returns dispatches to the router; main mounts it at /mcp or
+// runs it on stdio. Forwarded headers, for client addresses and the origins
+// of absolute links, are read only from the peers in proxies.
+func newRouter(st store.Store, auths []auth.Authenticator, timeout time.Duration, proxies []string) (*gin.Engine, *mcp.Server, error) {
+	h := handlers.New(st)
+	routes := h.Routes()
+
+	origins, err := origin.NewResolver(proxies)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := gin.New()
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		return nil, nil, err
+	}
+	r.Use(logging.Middleware(), logging.Recovery(), tracing.Middleware(), metrics.Middleware(), handlers.Authenticate(auths...))
+	for _, rt := range routes {
+		d := rt.Timeout
+		if d == 0 {
+			d = timeout
+		}
+		if rt.Scope == "" {
+			r.Handle(rt.Method, rt.Path, handlers.Timeout(d), rt.Handler)
+			continue
+		}
+		r.Handle(rt.Method, rt.Path, handlers.Require(rt.Scope), handlers.Timeout(d), rt.Handler)
+	}
+
+	mcpServer := mcp.NewServer(routes, r)
+
+	spec := openapi.NewServer(routes, origins)
+	r.GET("/openapi.json", gin.WrapF(spec.ServeSpec))
+	r.GET("/docs", gin.WrapF(spec.ServeDocs))
+	r.GET("/metrics", handlers.Require(auth.ReadMetrics), gin.WrapH(metrics.Handler()))
+
+	graphServer, err := graph.NewServer(st)
+	if err != nil {
+		return nil, nil, fmt.Errorf("build GraphQL schema: %w", err)
+	}
+	r.GET("/graphql", handlers.Require(auth.ReadCore), handlers.Timeout(timeout), gin.WrapH(graphServer))
+	r.POST("/graphql", handlers.Require(auth.ReadCore), handlers.Timeout(timeout), gin.WrapH(graphServer))
+
+	odataServer, err := odata.NewServer(st, "/odata", origins)
+	if err != nil {
+		return nil, nil, fmt.Errorf("build OData metadata: %w", err)
+	}
+	r.GET("/odata/*path", handlers.Require(auth.ReadCore), handlers.Timeout(timeout), gin.WrapH(odataServer))
+
+	return r, mcpServer, nil
+}
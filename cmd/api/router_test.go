@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nicholasraynes/northwind-api/internal/auth"
+	"github.com/nicholasraynes/northwind-api/internal/handlers"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store/postgres"
+)
+
+// unspecified are the routes that speak their own protocol, with their own
+// self-description, and so stay out of the OpenAPI document.
+var unspecified = []string{"/mcp", "/graphql", "/odata/*path", "/openapi.json", "/docs", "/metrics"}
+
+// public are the routes anyone may call without credentials.
+var public = []string{"/livez", "/readyz", "/health"}
+
+// actions are the POST routes that act on a record without reading a body.
+var actions = []string{"/admin/keys/:id/rotate"}
+
+// TestRoutesHaveSpec fails when a route on the router is missing from the
+// OpenAPI document or lacks the metadata a client needs to call it.
+func TestRoutesHaveSpec(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	st := postgres.New(nil)
+	r, _, err := newRouter(st, []auth.Authenticator{auth.NewAPIKeys(st)}, handlers.DefaultTimeout, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /openapi.json: status %d", w.Code)
+	}
+	var doc models.APISchema
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("GET /openapi.json: %v", err)
+	}
+
+	described := map[string]bool{}
+	for _, tag := range doc.Tags {
+		described[tag.Name] = tag.Description != ""
+	}
+
+	for _, rt := range r.Routes() {
+		if slices.Contains(unspecified, rt.Path) {
+			continue
+		}
+		name := rt.Method + " " + rt.Path
+
+		path, params := specPath(rt.Path)
+		op := doc.Paths[path][strings.ToLower(rt.Method)]
+		if op == nil {
+			t.Errorf("%s is not in the OpenAPI document; add it to handlers.Routes", name)
+			continue
+		}
+
+		if op.OperationID == "" || op.Summary == "" || op.Description == "" {
+			t.Errorf("%s needs a Name, Summary and Description", name)
+		}
+		if slices.Contains(public, rt.Path) {
+			if len(op.Security) > 0 {
+				t.Errorf("%s is public but declares security", name)
+			}
+		} else if len(op.Security) == 0 || op.RequiredScope == "" {
+			t.Errorf("%s needs a Scope", name)
+		}
+		for _, tag := range op.Tags {
+			if !described[tag] {
+				t.Errorf("%s is tagged %q, which has no description", name, tag)
+			}
+		}
+
+		for _, p := range params {
+			declared := false
+			for _, q := range op.Parameters {
+				declared = declared || (q.In == "path" && q.Name == p)
+			}
+			if !declared {
+				t.Errorf("%s does not declare its path parameter %q", name, p)
+			}
+		}
+
+		switch rt.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+			if op.RequestBody == nil && !slices.Contains(actions, rt.Path) {
+				t.Errorf("%s reads a body but declares no Body", name)
+			}
+		}
+
+		success := false
+		for status, res := range op.Responses {
+			if !strings.HasPrefix(status, "2") {
+				continue
+			}
+			success = true
+			if status != "204" && res.Content == nil {
+				t.Errorf("%s answers %s but declares no Response", name, status)
+			}
+		}
+		if !success {
+			t.Errorf("%s declares no successful response", name)
+		}
+	}
+}
+
+// specPath spells a Gin path as the document does and lists its parameters.
+func specPath(path string) (string, []string) {
+	parts := strings.Split(path, "/")
+	var params []string
+	for i, p := range parts {
+		if name, ok := strings.CutPrefix(p, ":"); ok {
+			parts[i] = "{" + name + "}"
+			params = append(params, name)
+		}
+	}
+	return strings.Join(parts, "/"), params
+}
+
+// scopeHeader authenticates test requests with the scopes their
+// X-Test-Scopes header lists.
+type scopeHeader struct{}
+
+func (scopeHeader) Authenticate(r *http.Request) (*auth.Principal, error) {
+	v := r.Header.Get("X-Test-Scopes")
+	if v == "" {
+		return nil, nil
+	}
+	p := &auth.Principal{Subject: "test"}
+	for _, s := range strings.Split(v, ",") {
+		p.Scopes = append(p.Scopes, auth.Scope(s))
+	}
+	return p, nil
+}
+
+// TestMetricsNeedScope fails when /metrics answers callers without the
+// read:metrics scope.
+func TestMetricsNeedScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r, _, err := newRouter(postgres.New(nil), []auth.Authenticator{scopeHeader{}}, handlers.DefaultTimeout, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		scopes string
+		want   int
+	}{
+		{"", http.StatusUnauthorized},
+		{"read:core,read:analytics", http.StatusForbidden},
+		{"read:metrics", http.StatusOK},
+		{"admin", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if tt.scopes != "" {
+			req.Header.Set("X-Test-Scopes", tt.scopes)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("GET /metrics with scopes %q: status %d, want %d", tt.scopes, w.Code, tt.want)
+		}
+	}
+}
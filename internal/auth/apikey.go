@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// KeyHeader carries an API key.
+const KeyHeader = "X-API-Key"
+
+// keyMarker starts every key, so leaked keys are easy to recognise.
+const keyMarker = "nwk_"
+
+// prefixLength is how much of a key is kept in clear to tell keys apart.
+const prefixLength = len(keyMarker) + 8
+
+// NewKey generates a key and returns it with the prefix and hash to store.
+// The key itself is shown to its owner once and never stored.
+func NewKey() (key, prefix, hash string, err error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	key = keyMarker + base64.RawURLEncoding.EncodeToString(b)
+	return key, key[:prefixLength], HashKey(key), nil
+}
+
+// HashKey returns the stored form of key. Keys are random, so a fast hash is
+// enough.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeys authenticates requests by the key in their X-API-Key header.
+type APIKeys struct {
+	keys store.KeyStore
+}
+
+// NewAPIKeys checks keys against those in ks.
+func NewAPIKeys(ks store.KeyStore) *APIKeys {
+	return &APIKeys{keys: ks}
+}
+
+func (a *APIKeys) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get(KeyHeader)
+	if key == "" {
+		return nil, nil
+	}
+	k, err := a.keys.UseAPIKey(r.Context(), HashKey(key))
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	return keyPrincipal(k), nil
+}
+
+// keyPrincipal is the principal authenticated by k.
+func keyPrincipal(k models.APIKey) *Principal {
+	p := &Principal{Subject: fmt.Sprintf("api_key:%d", k.KeyID), Name: k.Name, Rows: k.RowScope}
+	for _, s := range k.Scopes {
+		p.Scopes = append(p.Scopes, Scope(s))
+	}
+	return p
+}
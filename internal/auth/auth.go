@@ -0,0 +1,94 @@
+// Package auth identifies the callers of the API and the scopes they hold.
+//
+// An Authenticator turns one kind of credential into a Principal. The
+// handlers package runs the configured authenticators on every request and
+// checks the principal's scopes against the scope each route declares.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/nicholasraynes/northwind-api/internal/models"
+)
+
+// Scope is a permission a route requires and a principal holds.
+type Scope string
+
+const (
+	// ReadCore reads customers, orders, products and suppliers.
+	ReadCore Scope = "read:core"
+	// ReadAnalytics reads the summaries, analytics and metrics queries.
+	ReadAnalytics Scope = "read:analytics"
+	// WriteCore writes customers, products and suppliers.
+	WriteCore Scope = "write:core"
+	// WriteOrders writes orders and their line items.
+	WriteOrders Scope = "write:orders"
+	// ReadMetrics scrapes the service's Prometheus metrics.
+	ReadMetrics Scope = "read:metrics"
+	// Admin manages API keys and implies every other scope.
+	Admin Scope = "admin"
+)
+
+// Read reports whether s only reads data that can be narrowed to a
+// caller's rows. Metrics count every caller's requests, so ReadMetrics is
+// not one.
+func (s Scope) Read() bool {
+	return s == ReadCore || s == ReadAnalytics
+}
+
+// Scopes lists every scope, for validating input.
+var Scopes = []Scope{ReadCore, ReadAnalytics, WriteCore, WriteOrders, ReadMetrics, Admin}
+
+// Principal is an authenticated caller.
+type Principal struct {
+	// Subject identifies the caller, e.g. api_key:3.
+	Subject string
+	// Name is a readable label for logs.
+	Name   string
+	Scopes []Scope
+	// Rows, when set, confines the caller to one employee's or customer's
+	// rows.
+	Rows models.RowScope
+}
+
+// Allows reports whether p holds scope, directly or through Admin. A caller
+// confined by Rows may only read: writes, key management and metrics cannot
+// be narrowed to their rows.
+func (p *Principal) Allows(scope Scope) bool {
+	if p.Confined() && !scope.Read() {
+		return false
+	}
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, Admin)
+}
+
+// Confined reports whether p is limited to some rows.
+func (p *Principal) Confined() bool {
+	return len(p.Rows) > 0
+}
+
+// ErrInvalidCredentials is returned by an Authenticator for a credential
+// that is present but not valid.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Authenticator identifies the caller of r from one kind of credential. It
+// returns a nil principal and no error when r carries none of that kind.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns ctx carrying p. Requests made in process on behalf
+// of p, such as MCP tool calls, keep it.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal ctx carries, if any.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// signingMethods are the asymmetric algorithms a token may be signed with.
+// Symmetric ones are refused: the key set holds public keys only.
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// clockSkew is how far token lifetimes are stretched to allow for clocks
+// that disagree.
+const clockSkew = time.Minute
+
+// BearerConfig says which OAuth bearer tokens to accept.
+type BearerConfig struct {
+	Keys *KeySet
+	// Issuer must equal the token's iss, e.g.
+	// https://login.microsoftonline.com/<tenant>/v2.0 for Entra ID.
+	Issuer string
+	// Audiences lists the values of aud accepted, such as the application
+	// ID URI and client ID of the API's app registration.
+	Audiences []string
+	// ScopeMap grants scopes for values of the token's scp, scope and roles
+	// claims. A value spelling a scope, such as read:core, grants it without
+	// an entry.
+	ScopeMap map[string][]Scope
+	// RowClaims maps claims to the row scope dimensions they confine the
+	// caller to, e.g. {"extension_EmployeeID": "employee_id"}. A token
+	// carrying none of them is not confined.
+	RowClaims map[string]string
+}
+
+// Bearer authenticates requests by the JWT in their Authorization header,
+// as Entra ID issues them to Copilot Studio and Power Automate connectors.
+type Bearer struct {
+	cfg    BearerConfig
+	parser *jwt.Parser
+}
+
+// NewBearer accepts tokens signed by a key in cfg.Keys for cfg.Issuer and
+// one of cfg.Audiences.
+func NewBearer(cfg BearerConfig) (*Bearer, error) {
+	if cfg.Keys == nil || cfg.Issuer == "" || len(cfg.Audiences) == 0 {
+		return nil, errors.New("bearer tokens need a key set, an issuer and an audience")
+	}
+	return &Bearer{
+		cfg: cfg,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods(signingMethods),
+			jwt.WithIssuer(cfg.Issuer),
+			jwt.WithAudience(cfg.Audiences...),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(clockSkew),
+		),
+	}, nil
+}
+
+// tokenClaims are the claims read from a token. Entra ID names the caller
+// by oid and, for applications, azp or appid, and carries delegated
+// permissions in scp and application roles in roles.
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	OID               string   `json:"oid"`
+	Name              string   `json:"name"`
+	PreferredUsername string   `json:"preferred_username"`
+	AZP               string   `json:"azp"`
+	AppID             string   `json:"appid"`
+	Scp               string   `json:"scp"`
+	Scope             string   `json:"scope"`
+	Roles             []string `json:"roles"`
+
+	// all holds every claim, for BearerConfig.RowClaims.
+	all map[string]any
+}
+
+func (c *tokenClaims) UnmarshalJSON(b []byte) error {
+	type plain tokenClaims
+	if err := json.Unmarshal(b, (*plain)(c)); err != nil {
+		return err
+	}
+	return json.Unmarshal(b, &c.all)
+}
+
+func (b *Bearer) Authenticate(r *http.Request) (*Principal, error) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, nil
+	}
+
+	var claims tokenClaims
+	_, err := b.parser.ParseWithClaims(strings.TrimSpace(token), &claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return b.cfg.Keys.Key(r.Context(), kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	return b.principal(claims)
+}
+
+// principal is the caller claims describes.
+func (b *Bearer) principal(claims tokenClaims) (*Principal, error) {
+	p := &Principal{Subject: "jwt:" + firstOf(claims.OID, claims.Subject)}
+	p.Name = firstOf(claims.Name, claims.PreferredUsername, claims.AZP, claims.AppID, claims.Subject)
+
+	values := append(strings.Fields(claims.Scp), strings.Fields(claims.Scope)...)
+	values = append(values, claims.Roles...)
+	grant := func(s Scope) {
+		if !slices.Contains(p.Scopes, s) {
+			p.Scopes = append(p.Scopes, s)
+		}
+	}
+	for _, v := range values {
+		for _, s := range b.cfg.ScopeMap[v] {
+			grant(s)
+		}
+		if slices.Contains(Scopes, Scope(v)) {
+			grant(Scope(v))
+		}
+	}
+
+	rows := models.RowScope{}
+	for claim, dim := range b.cfg.RowClaims {
+		switch v := claims.all[claim].(type) {
+		case nil:
+		case string:
+			rows[dim] = v
+		case float64:
+			rows[dim] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return nil, fmt.Errorf("%w: claim %s is neither a string nor a number", ErrInvalidCredentials, claim)
+		}
+	}
+	if err := store.CheckRowScope(rows); err != nil {
+		return nil, fmt.Errorf("%w: row claims: %v", ErrInvalidCredentials, err)
+	}
+	if len(rows) > 0 {
+		p.Rows = rows
+	}
+	return p, nil
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ParseScopeMap reads a BearerConfig.ScopeMap written as comma-separated
+// claim=scope pairs, such as "Orders.ReadWrite=read:core,Orders.ReadWrite=write:orders".
+func ParseScopeMap(s string) (map[string][]Scope, error) {
+	m := map[string][]Scope{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		claim, scope, ok := strings.Cut(pair, "=")
+		if !ok || claim == "" {
+			return nil, fmt.Errorf("scope mapping %q is not claim=scope", pair)
+		}
+		if !slices.Contains(Scopes, Scope(scope)) {
+			return nil, fmt.Errorf("scope mapping %q names unknown scope %q", pair, scope)
+		}
+		m[claim] = append(m[claim], Scope(scope))
+	}
+	return m, nil
+}
+
+// ParseRowClaims reads a BearerConfig.RowClaims written as comma-separated
+// claim=dimension pairs, such as "extension_EmployeeID=employee_id".
+func ParseRowClaims(s string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		claim, dim, ok := strings.Cut(pair, "=")
+		if !ok || claim == "" {
+			return nil, fmt.Errorf("row claim %q is not claim=dimension", pair)
+		}
+		if !slices.Contains(store.RowDimensions, dim) {
+			return nil, fmt.Errorf("row claim %q names unknown dimension %q", pair, dim)
+		}
+		m[claim] = dim
+	}
+	return m, nil
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "https://login.example.com/tenant/v2.0"
+	testAudience = "api://northwind"
+)
+
+// newTestBearer returns a Bearer trusting a generated RSA key and an EC
+// key, loaded from a local JWKS file, along with the private keys.
+func newTestBearer(t *testing.T) (*Bearer, map[string]any) {
+	t.Helper()
+	rsaKey, ecKey := newRSAKey(t), newECKey(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwksJSON(t, rsaJWK("rsa", &rsaKey.PublicKey), ecJWK("ec", &ecKey.PublicKey)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ks, err := LoadKeySet(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewBearer(BearerConfig{
+		Keys:      ks,
+		Issuer:    testIssuer,
+		Audiences: []string{testAudience},
+		ScopeMap:  map[string][]Scope{"Orders.Read": {ReadCore}},
+		RowClaims: map[string]string{"employee": "employee_id"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b, map[string]any{"rsa": rsaKey, "ec": ecKey}
+}
+
+// validClaims are claims every check accepts; tests change one at a time.
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":  testIssuer,
+		"aud":  testAudience,
+		"sub":  "user-1",
+		"oid":  "oid-1",
+		"name": "Nancy Davolio",
+		"scp":  "Orders.Read read:analytics",
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+	tok := jwt.NewWithClaims(method, claims)
+	tok.Header["kid"] = kid
+	s, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func authenticate(b *Bearer, token string) (*Principal, error) {
+	r := httptest.NewRequest("GET", "/orders", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	return b.Authenticate(r)
+}
+
+func TestBearerAccepts(t *testing.T) {
+	b, keys := newTestBearer(t)
+	tokens := map[string]string{
+		"RS256": sign(t, jwt.SigningMethodRS256, "rsa", keys["rsa"], validClaims()),
+		"PS256": sign(t, jwt.SigningMethodPS256, "rsa", keys["rsa"], validClaims()),
+		"ES256": sign(t, jwt.SigningMethodES256, "ec", keys["ec"], validClaims()),
+	}
+	for alg, token := range tokens {
+		p, err := authenticate(b, token)
+		if err != nil {
+			t.Errorf("%s: %v", alg, err)
+			continue
+		}
+		if p.Subject != "jwt:oid-1" || p.Name != "Nancy Davolio" {
+			t.Errorf("%s: principal %q (%q)", alg, p.Subject, p.Name)
+		}
+		if want := []Scope{ReadCore, ReadAnalytics}; !slices.Equal(p.Scopes, want) {
+			t.Errorf("%s: scopes %v, want %v", alg, p.Scopes, want)
+		}
+		if p.Confined() {
+			t.Errorf("%s: confined without row claims", alg)
+		}
+	}
+}
+
+func TestBearerRejects(t *testing.T) {
+	b, keys := newTestBearer(t)
+	with := func(name string, v any) jwt.MapClaims {
+		c := validClaims()
+		if v == nil {
+			delete(c, name)
+		} else {
+			c[name] = v
+		}
+		return c
+	}
+	otherKey := newRSAKey(t)
+
+	tests := map[string]string{
+		"wrong audience":   sign(t, jwt.SigningMethodRS256, "rsa", keys["rsa"], with("aud", "api://other")),
+		"wrong issuer":     sign(t, jwt.SigningMethodRS256, "rsa", keys["rsa"], with("iss", "https://evil.example.com")),
+		"expired":          sign(t, jwt.SigningMethodRS256, "rsa", keys["rsa"], with("exp", time.Now().Add(-time.Hour).Unix())),
+		"no expiry":        sign(t, jwt.SigningMethodRS256, "rsa", keys["rsa"], with("exp", nil)),
+		"not yet valid":    sign(t, jwt.SigningMethodRS256, "rsa", keys["rsa"], with("nbf", time.Now().Add(time.Hour).Unix())),
+		"unknown key":      sign(t, jwt.SigningMethodRS256, "missing", keys["rsa"], validClaims()),
+		"untrusted key":    sign(t, jwt.SigningMethodRS256, "rsa", otherKey, validClaims()),
+		"symmetric HS256":  sign(t, jwt.SigningMethodHS256, "rsa", []byte("secret"), validClaims()),
+		"unsigned":         sign(t, jwt.SigningMethodNone, "rsa", jwt.UnsafeAllowNoneSignatureType, validClaims()),
+		"bad row claim":    sign(t, jwt.SigningMethodRS256, "rsa", keys["rsa"], with("employee", "five")),
+		"row claim object": sign(t, jwt.SigningMethodRS256, "rsa", keys["rsa"], with("employee", map[string]any{"id": 5})),
+		"garbage":          "not.a.token",
+	}
+	for name, token := range tests {
+		if _, err := authenticate(b, token); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("%s: err = %v, want ErrInvalidCredentials", name, err)
+		}
+	}
+}
+
+func TestBearerRowClaims(t *testing.T) {
+	b, keys := newTestBearer(t)
+	for _, v := range []any{5, "5"} {
+		claims := validClaims()
+		claims["employee"] = v
+		p, err := authenticate(b, sign(t, jwt.SigningMethodRS256, "rsa", keys["rsa"], claims))
+		if err != nil {
+			t.Fatalf("employee %v: %v", v, err)
+		}
+		if p.Rows["employee_id"] != "5" {
+			t.Errorf("employee %v: rows %v", v, p.Rows)
+		}
+	}
+}
+
+func TestBearerIgnoresOtherSchemes(t *testing.T) {
+	b, _ := newTestBearer(t)
+	r := httptest.NewRequest("GET", "/orders", nil)
+	r.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	if p, err := b.Authenticate(r); p != nil || err != nil {
+		t.Errorf("Basic credentials: %v, %v", p, err)
+	}
+}
+
+func TestNewBearerNeedsIssuerAndAudience(t *testing.T) {
+	ks := &KeySet{}
+	for _, cfg := range []BearerConfig{
+		{Issuer: testIssuer, Audiences: []string{testAudience}},
+		{Keys: ks, Audiences: []string{testAudience}},
+		{Keys: ks, Issuer: testIssuer},
+	} {
+		if _, err := NewBearer(cfg); err == nil {
+			t.Errorf("NewBearer(%+v) succeeded", cfg)
+		}
+	}
+}
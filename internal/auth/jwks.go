@@ -0,0 +1,249 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// refetchInterval is the least time between two fetches of a remote key
+// set, so tokens with unknown key IDs cannot make the server hammer it.
+const refetchInterval = time.Minute
+
+// KeySet is a JSON Web Key Set holding the public keys tokens are signed
+// with. A set loaded from a URL is fetched again when a token names a key it
+// does not hold, which picks up keys the issuer has rotated in.
+type KeySet struct {
+	source string
+	client *http.Client
+	// refetch lets one caller fetch the set while others with unknown key
+	// IDs wait for it; callers whose keys are held need not wait.
+	refetch singleflight.Group
+
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+// LoadKeySet reads the key set at source, an http(s) URL or a file path.
+func LoadKeySet(ctx context.Context, source string) (*KeySet, error) {
+	ks := &KeySet{source: source, client: &http.Client{Timeout: 10 * time.Second}}
+	keys, err := ks.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ks.keys, ks.fetched = keys, time.Now()
+	return ks, nil
+}
+
+func (ks *KeySet) remote() bool {
+	return strings.HasPrefix(ks.source, "https://") || strings.HasPrefix(ks.source, "http://")
+}
+
+// Key returns the key with ID kid. An empty kid matches the only key of a
+// set holding one.
+func (ks *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	k, ok, stale := ks.cached(kid)
+	if ok {
+		return k, nil
+	}
+	if ks.remote() && stale {
+		// The fetch outlives a caller that gives up on it, so the callers
+		// waiting with it still get the new keys.
+		done := ks.refetch.DoChan("", func() (any, error) {
+			return nil, ks.refresh(context.WithoutCancel(ctx))
+		})
+		select {
+		case res := <-done:
+			if res.Err != nil {
+				return nil, res.Err
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if k, ok, _ := ks.cached(kid); ok {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("no signing key %q", kid)
+}
+
+// cached looks kid up in the keys held, reporting too whether they are old
+// enough to be fetched again.
+func (ks *KeySet) cached(kid string) (key crypto.PublicKey, ok, stale bool) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	key, ok = ks.lookup(kid)
+	return key, ok, time.Since(ks.fetched) >= refetchInterval
+}
+
+// refresh fetches the set again unless it was fetched within
+// refetchInterval. A failed fetch waits out the interval too, so an issuer
+// that is down is not retried on every request.
+func (ks *KeySet) refresh(ctx context.Context) error {
+	ks.mu.Lock()
+	fresh := time.Since(ks.fetched) < refetchInterval
+	ks.mu.Unlock()
+	if fresh {
+		// Fetched by the flight that just ended.
+		return nil
+	}
+
+	keys, err := ks.fetch(ctx)
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.fetched = time.Now()
+	if err != nil {
+		return err
+	}
+	ks.keys = keys
+	return nil
+}
+
+// lookup finds kid in ks.keys; ks.mu must be held.
+func (ks *KeySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(ks.keys) == 1 {
+		for _, k := range ks.keys {
+			return k, true
+		}
+	}
+	k, ok := ks.keys[kid]
+	return k, ok
+}
+
+func (ks *KeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	var body []byte
+	if ks.remote() {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.source, nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := ks.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("fetch JWKS: %w", err)
+		}
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetch JWKS: %s", res.Status)
+		}
+		if body, err = io.ReadAll(io.LimitReader(res.Body, 1<<20)); err != nil {
+			return nil, fmt.Errorf("fetch JWKS: %w", err)
+		}
+	} else {
+		var err error
+		if body, err = os.ReadFile(ks.source); err != nil {
+			return nil, fmt.Errorf("read JWKS: %w", err)
+		}
+	}
+	return parseKeySet(body)
+}
+
+// jwk is one key of a JWKS, per RFC 7517 and RFC 7518.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseKeySet decodes the signing keys of a JWKS document. Encryption keys
+// and key types other than RSA and EC are skipped.
+func parseKeySet(body []byte) (map[string]crypto.PublicKey, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("parse JWKS: %w", err)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var (
+			key crypto.PublicKey
+			err error
+		)
+		switch k.Kty {
+		case "RSA":
+			key, err = k.rsa()
+		case "EC":
+			key, err = k.ec()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse JWKS key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("parse JWKS: no RSA or EC signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) rsa() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("exponent: %w", err)
+	}
+	exp := new(big.Int).SetBytes(e)
+	if !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("exponent out of range")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+}
+
+func (k jwk) ec() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("x: %w", err)
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("y: %w", err)
+	}
+	key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	// ECDH rejects points that are not on the curve.
+	if _, err := key.ECDH(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
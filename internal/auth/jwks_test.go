@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var b64 = base64.RawURLEncoding.EncodeToString
+
+func rsaJWK(kid string, k *rsa.PublicKey) jwk {
+	return jwk{Kty: "RSA", Kid: kid, Use: "sig", N: b64(k.N.Bytes()), E: b64(big.NewInt(int64(k.E)).Bytes())}
+}
+
+func ecJWK(kid string, k *ecdsa.PublicKey) jwk {
+	return jwk{Kty: "EC", Kid: kid, Crv: k.Curve.Params().Name, X: b64(k.X.Bytes()), Y: b64(k.Y.Bytes())}
+}
+
+func jwksJSON(t *testing.T, keys ...jwk) []byte {
+	t.Helper()
+	b, err := json.Marshal(map[string][]jwk{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func newECKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestParseKeySet(t *testing.T) {
+	rsaKey, ecKey := newRSAKey(t), newECKey(t)
+	offCurve := ecJWK("bad", &ecKey.PublicKey)
+	offCurve.Y = b64(big.NewInt(1).Bytes())
+	smallExp := rsaJWK("small", &rsaKey.PublicKey)
+	smallExp.E = b64([]byte{1})
+	unknownCurve := ecJWK("p224", &ecKey.PublicKey)
+	unknownCurve.Crv = "P-224"
+	enc := rsaJWK("enc", &rsaKey.PublicKey)
+	enc.Use = "enc"
+
+	tests := []struct {
+		name string
+		body []byte
+		kids []string
+		ok   bool
+	}{
+		{"rsa and ec", jwksJSON(t, rsaJWK("r", &rsaKey.PublicKey), ecJWK("e", &ecKey.PublicKey)), []string{"r", "e"}, true},
+		{"skips encryption and symmetric keys", jwksJSON(t, enc, jwk{Kty: "oct", Kid: "h"}, rsaJWK("r", &rsaKey.PublicKey)), []string{"r"}, true},
+		{"no signing keys", jwksJSON(t, enc), nil, false},
+		{"point off the curve", jwksJSON(t, offCurve), nil, false},
+		{"exponent too small", jwksJSON(t, smallExp), nil, false},
+		{"unknown curve", jwksJSON(t, unknownCurve), nil, false},
+		{"not JSON", []byte("<html>"), nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := parseKeySet(tt.body)
+			if (err == nil) != tt.ok {
+				t.Fatalf("err = %v, want ok = %v", err, tt.ok)
+			}
+			if len(keys) != len(tt.kids) {
+				t.Errorf("got %d keys, want %v", len(keys), tt.kids)
+			}
+			for _, kid := range tt.kids {
+				if keys[kid] == nil {
+					t.Errorf("key %q missing", kid)
+				}
+			}
+		})
+	}
+}
+
+func TestKeySetFromFile(t *testing.T) {
+	key := newRSAKey(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwksJSON(t, rsaJWK("only", &key.PublicKey)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ks, err := LoadKeySet(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kid := range []string{"only", ""} {
+		if _, err := ks.Key(context.Background(), kid); err != nil {
+			t.Errorf("Key(%q): %v", kid, err)
+		}
+	}
+	if _, err := ks.Key(context.Background(), "other"); err == nil {
+		t.Error(`Key("other") found a key`)
+	}
+}
+
+// TestKeySetRefetch fails when a remote set is fetched more than once for a
+// burst of unknown key IDs, or when known keys wait for the fetch.
+func TestKeySetRefetch(t *testing.T) {
+	old, rotated := newRSAKey(t), newRSAKey(t)
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) == 1 {
+			w.Write(jwksJSON(t, rsaJWK("old", &old.PublicKey)))
+			return
+		}
+		<-release
+		w.Write(jwksJSON(t, rsaJWK("old", &old.PublicKey), rsaJWK("new", &rotated.PublicKey)))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	ks, err := LoadKeySet(ctx, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.Key(ctx, "new"); err == nil {
+		t.Fatal("found a key not yet published")
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("refetched within refetchInterval: %d fetches", n)
+	}
+
+	ks.mu.Lock()
+	ks.fetched = time.Now().Add(-refetchInterval)
+	ks.mu.Unlock()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ks.Key(ctx, "new")
+			errs <- err
+		}()
+	}
+	// Wait for the fetch to start, then check known keys do not wait on it.
+	for fetches.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	known := make(chan error, 1)
+	go func() {
+		_, err := ks.Key(ctx, "old")
+		known <- err
+	}()
+	select {
+	case err := <-known:
+		if err != nil {
+			t.Errorf(`Key("old"): %v`, err)
+		}
+	case <-time.After(time.Second):
+		t.Error(`Key("old") waited for the fetch`)
+	}
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf(`Key("new"): %v`, err)
+		}
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("%d fetches, want 2", n)
+	}
+}
@@ -1,38 +1,46 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"strconv"
 
-	"github.com/joho/godotenv"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
-var DB *sql.DB
-
-func Connect() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("Warning: .env file not found, using environment variables")
-	}
-
+// Connect opens and verifies the Postgres connection named by DATABASE_URL.
+// DB_MAX_OPEN_CONNS, when set, bounds the connections open at once; the
+// pool is unbounded otherwise. Every statement run over it is reported to
+// obs.
+func Connect(ctx context.Context, obs ...Observer) (*sql.DB, error) {
 	connStr := os.Getenv("DATABASE_URL")
 	if connStr == "" {
-		log.Fatal("DATABASE_URL not set")
+		return nil, errors.New("DATABASE_URL not set")
 	}
 
-	database, err := sql.Open("postgres", connStr)
+	connector, err := pq.NewConnector(connStr)
 	if err != nil {
-		log.Fatalf("Error opening DB connection: %v", err)
+		return nil, fmt.Errorf("open database connection: %w", err)
+	}
+	database := sql.OpenDB(observedConnector{Connector: connector, obs: obs})
+	if v := os.Getenv("DB_MAX_OPEN_CONNS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			database.Close()
+			return nil, fmt.Errorf("DB_MAX_OPEN_CONNS: %q is not a positive integer", v)
+		}
+		database.SetMaxOpenConns(n)
 	}
 
-	err = database.Ping()
-	if err != nil {
-		log.Fatalf("Cannot connect to database: %v", err)
+	if err := database.PingContext(ctx); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("connect to database: %w", err)
 	}
 
-	DB = database
-	fmt.Println("✅ Connected to Supabase (Postgres Northwind DB)")
+	slog.InfoContext(ctx, "connected to database")
+	return database, nil
 }
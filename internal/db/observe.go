@@ -0,0 +1,199 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Observer is told about every statement run over a connection Connect
+// opens, for metrics and the like.
+type Observer interface {
+	// Statement is called as query starts. It returns a func to call once
+	// the statement is done: when its rows are closed for a query, or when
+	// it returns otherwise. rows is the number of rows read, or affected by a
+	// statement that returns none.
+	Statement(ctx context.Context, query string) func(rows int64, err error)
+}
+
+// observedConnector wraps the connections of a driver.Connector so every
+// statement run over them is reported to obs.
+type observedConnector struct {
+	driver.Connector
+	obs []Observer
+}
+
+func (c observedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &observedConn{Conn: conn, obs: c.obs}, nil
+}
+
+// observedConn forwards to the driver's connection, which must implement
+// the context-aware interfaces database/sql prefers, as lib/pq's does.
+type observedConn struct {
+	driver.Conn
+	obs []Observer
+}
+
+// start reports query to every observer and returns a func reporting its
+// end to them all.
+func (c *observedConn) start(ctx context.Context, query string) func(int64, error) {
+	done := make([]func(int64, error), len(c.obs))
+	for i, o := range c.obs {
+		done[i] = o.Statement(ctx, query)
+	}
+	return func(rows int64, err error) {
+		for _, d := range done {
+			d(rows, err)
+		}
+	}
+}
+
+func (c *observedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	done := c.start(ctx, query)
+	rs, err := q.QueryContext(ctx, query, args)
+	if err != nil {
+		done(0, err)
+		return nil, err
+	}
+	return &observedRows{Rows: rs, done: done}, nil
+}
+
+func (c *observedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	done := c.start(ctx, query)
+	res, err := e.ExecContext(ctx, query, args)
+	var n int64
+	if err == nil {
+		n, _ = res.RowsAffected()
+	}
+	done(n, err)
+	return res, err
+}
+
+func (c *observedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *observedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	b, ok := c.Conn.(driver.ConnBeginTx)
+	if !ok {
+		return nil, errors.New("driver does not support BeginTx")
+	}
+	return b.BeginTx(ctx, opts)
+}
+
+func (c *observedConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *observedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *observedConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// observedRows counts the rows read and reports the statement done when
+// they are closed.
+type observedRows struct {
+	driver.Rows
+	done func(int64, error)
+	n    int64
+	err  error
+}
+
+func (r *observedRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	switch {
+	case err == nil:
+		r.n++
+	case !errors.Is(err, io.EOF):
+		r.err = err
+	}
+	return err
+}
+
+func (r *observedRows) Close() error {
+	err := r.Rows.Close()
+	if r.done != nil {
+		r.done(r.n, r.err)
+		r.done = nil
+	}
+	return err
+}
+
+// Sanitize collapses the whitespace of query and replaces its literals with
+// ?, so traces and logs never hold values such as a caller's row scope. Bound
+// parameters ($1) are kept; their values are not recorded.
+func Sanitize(query string) string {
+	var b strings.Builder
+	space := false
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			space = b.Len() > 0
+			continue
+		case space:
+			b.WriteByte(' ')
+			space = false
+		}
+		switch {
+		case ch == '\'':
+			// Skip to the closing quote; '' is an escaped quote.
+			for i++; i < len(query); i++ {
+				if query[i] == '\'' {
+					if i+1 < len(query) && query[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			b.WriteByte('?')
+		case isDigit(ch) && (i == 0 || !isWord(query[i-1])):
+			for i+1 < len(query) && (isDigit(query[i+1]) || query[i+1] == '.') {
+				i++
+			}
+			b.WriteByte('?')
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return b.String()
+}
+
+func isDigit(ch byte) bool { return '0' <= ch && ch <= '9' }
+
+// isWord reports whether ch continues an identifier or a $n parameter, in
+// which digits are not literals.
+func isWord(ch byte) bool {
+	return ch == '_' || ch == '$' || isDigit(ch) || unicode.IsLetter(rune(ch))
+}
@@ -0,0 +1,142 @@
+// Package export negotiates a response format and writes collection rows as
+// CSV, newline-delimited JSON or an XLSX workbook, one row at a time.
+package export
+
+import (
+	"fmt"
+	"mime"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Param is the query parameter that overrides the Accept header.
+const Param = "format"
+
+// Format is a response encoding.
+type Format string
+
+const (
+	JSON   Format = "json"
+	CSV    Format = "csv"
+	NDJSON Format = "ndjson"
+	XLSX   Format = "xlsx"
+)
+
+// Formats lists every supported format, JSON first.
+var Formats = []Format{JSON, CSV, NDJSON, XLSX}
+
+var mediaTypes = map[Format]string{
+	JSON:   "application/json",
+	CSV:    "text/csv",
+	NDJSON: "application/x-ndjson",
+	XLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// MediaType returns the Content-Type for f.
+func (f Format) MediaType() string {
+	return mediaTypes[f]
+}
+
+// Error reports an unsupported format parameter.
+type Error struct {
+	Value string
+}
+
+func (e *Error) Error() string {
+	return Param + " " + e.Message()
+}
+
+// Message describes the problem without naming the parameter.
+func (e *Error) Message() string {
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return fmt.Sprintf("%q is not supported; use one of %s", e.Value, strings.Join(names, ", "))
+}
+
+// Negotiate picks the response format. An explicit format parameter wins;
+// otherwise the most preferred supported type in the Accept header is used,
+// falling back to JSON.
+func Negotiate(param, accept string) (Format, error) {
+	if param = strings.ToLower(strings.TrimSpace(param)); param != "" {
+		for _, f := range Formats {
+			if string(f) == param {
+				return f, nil
+			}
+		}
+		return "", &Error{Value: param}
+	}
+
+	type candidate struct {
+		format Format
+		q      float64
+	}
+	candidates := []candidate{}
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		for _, f := range Formats {
+			if mediaTypes[f] == mt {
+				candidates = append(candidates, candidate{f, q})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return JSON, nil
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].format, nil
+}
+
+// formula reports whether a spreadsheet would evaluate the text cell s as a
+// formula, such as a company name that starts with "=" or "@". Numbers are
+// not text cells, so a negative one is never mistaken for a formula.
+func formula(s string) bool {
+	return s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0]))
+}
+
+// text renders a cell value. Pointers are followed, nil is empty and dates
+// without a time of day drop the clock.
+func text(v any) string {
+	if v == nil {
+		return ""
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return ""
+		}
+		return text(rv.Elem().Interface())
+	}
+
+	switch val := v.(type) {
+	case string:
+		return val
+	case time.Time:
+		if val.Hour() == 0 && val.Minute() == 0 && val.Second() == 0 && val.Nanosecond() == 0 {
+			return val.Format(time.DateOnly)
+		}
+		return val.Format(time.RFC3339)
+	case bool:
+		return strconv.FormatBool(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32)
+	}
+	return fmt.Sprint(v)
+}
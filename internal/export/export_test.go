@@ -0,0 +1,85 @@
+package export
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		param, accept string
+		want          Format
+	}{
+		{"", "", JSON},
+		{"", "text/html, */*", JSON},
+		{"", "text/csv", CSV},
+		{"", "application/json;q=0.5, application/x-ndjson", NDJSON},
+		{"", "text/csv;q=0.2, application/vnd.openxmlformats-officedocument.spreadsheetml.sheet;q=0.9", XLSX},
+		{"", "text/csv;q=0", JSON},
+		{"CSV", "application/x-ndjson", CSV},
+		{" xlsx ", "", XLSX},
+	}
+	for _, tt := range tests {
+		got, err := Negotiate(tt.param, tt.accept)
+		if err != nil || got != tt.want {
+			t.Errorf("Negotiate(%q, %q) = %s, %v, want %s", tt.param, tt.accept, got, err, tt.want)
+		}
+	}
+
+	var fe *Error
+	if _, err := Negotiate("pdf", ""); !errors.As(err, &fe) || fe.Value != "pdf" {
+		t.Errorf("Negotiate(pdf) = %v, want an Error", err)
+	}
+}
+
+func TestText(t *testing.T) {
+	s := "Berlin"
+	var none *string
+	tests := []struct {
+		v    any
+		want string
+	}{
+		{nil, ""},
+		{none, ""},
+		{&s, "Berlin"},
+		{12.5, "12.5"},
+		{float32(0.25), "0.25"},
+		{42, "42"},
+		{true, "true"},
+		{time.Date(1997, 1, 2, 0, 0, 0, 0, time.UTC), "1997-01-02"},
+		{time.Date(1997, 1, 2, 3, 4, 5, 0, time.UTC), "1997-01-02T03:04:05Z"},
+	}
+	for _, tt := range tests {
+		if got := text(tt.v); got != tt.want {
+			t.Errorf("text(%#v) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestFormula(t *testing.T) {
+	for s, want := range map[string]bool{
+		"=HYPERLINK(\"x\")": true,
+		"+1":                true,
+		"-2+3":              true,
+		"@SUM(A1)":          true,
+		"\tcmd":             true,
+		"\rcmd":             true,
+		"":                  false,
+		"Alfreds":           false,
+		"a=b":               false,
+		"'=quoted":          false,
+	} {
+		if got := formula(s); got != want {
+			t.Errorf("formula(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/nicholasraynes/northwind-api/internal/fieldset"
+)
+
+// Writer encodes rows as they are produced. Close must be called to finish
+// the document; it does not close the underlying io.Writer.
+type Writer interface {
+	// Write encodes one row whose values line up with the columns the writer
+	// was created with.
+	Write(values []any) error
+	Close() error
+}
+
+// NewWriter returns a writer for f that emits columns as its header. JSON is
+// not streamed row by row and has no writer.
+func NewWriter(f Format, w io.Writer, columns []string) (Writer, error) {
+	switch f {
+	case CSV:
+		return newCSVWriter(w, columns)
+	case NDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w), columns: columns}, nil
+	case XLSX:
+		return newXLSXWriter(w, columns)
+	}
+	return nil, fmt.Errorf("export: no row writer for %s", f)
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w)}
+	if err := cw.w.Write(columns); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) Write(values []any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = text(v)
+		// A leading quote keeps the cell text when the file is opened in a
+		// spreadsheet, which has no other way to tell from a CSV.
+		if cellKind(v) == "s" && formula(record[i]) {
+			record[i] = "'" + record[i]
+		}
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// ndjsonWriter writes one JSON object per line. Each object keeps the column
+// order, which matches the JSON response.
+type ndjsonWriter struct {
+	enc     *json.Encoder
+	columns []string
+}
+
+func (nw *ndjsonWriter) Write(values []any) error {
+	return nw.enc.Encode(fieldset.NewObject(nw.columns, values))
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,147 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var (
+	columns = []string{"company_name", "freight", "region"}
+	rows    = [][]any{
+		{"Alfreds Futterkiste", 32.38, nil},
+		{"=HYPERLINK(\"http://evil\",\"x\")", -1.5, "@SUM(A1)"},
+		{"-Minus Corp", 0, "+49"},
+	}
+)
+
+// write encodes rows in f and returns the document.
+func write(t *testing.T, f Format) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(f, &buf, columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range rows {
+		if err := w.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSVWriter(t *testing.T) {
+	got, err := csv.NewReader(bytes.NewReader(write(t, CSV))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		columns,
+		{"Alfreds Futterkiste", "32.38", ""},
+		{`'=HYPERLINK("http://evil","x")`, "-1.5", "'@SUM(A1)"},
+		{"'-Minus Corp", "0", "'+49"},
+	}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("CSV = %q, want %q", got, want)
+	}
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(string(write(t, NDJSON))), "\n")
+	if len(lines) != len(rows) {
+		t.Fatalf("%d lines, want %d", len(lines), len(rows))
+	}
+	if !strings.HasPrefix(lines[0], `{"company_name":"Alfreds Futterkiste","freight":32.38,"region":null}`) {
+		t.Errorf("first line %s keeps neither the column order nor the values", lines[0])
+	}
+	var second map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatal(err)
+	}
+	// NDJSON is read by programs, not spreadsheets, so values stay as is.
+	if second["company_name"] != rows[1][0] {
+		t.Errorf("company_name = %v, want %v", second["company_name"], rows[1][0])
+	}
+}
+
+// cell is a worksheet cell as written by xlsxWriter.
+type cell struct {
+	Ref   string `xml:"r,attr"`
+	Type  string `xml:"t,attr"`
+	Style string `xml:"s,attr"`
+	Value string `xml:"v"`
+	Text  string `xml:"is>t"`
+}
+
+func TestXLSXWriter(t *testing.T) {
+	doc := write(t, XLSX)
+	zr, err := zip.NewReader(bytes.NewReader(doc), int64(len(doc)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[f.Name], err = io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := xml.Unmarshal(parts[f.Name], new(struct{})); err != nil {
+			t.Errorf("%s is not well-formed: %v", f.Name, err)
+		}
+	}
+	if !bytes.Contains(parts["xl/styles.xml"], []byte(`quotePrefix="1"`)) {
+		t.Error("styles.xml has no quote prefix style")
+	}
+
+	var sheet struct {
+		Rows []struct {
+			Cells []cell `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet); err != nil {
+		t.Fatal(err)
+	}
+	if len(sheet.Rows) != len(rows)+1 {
+		t.Fatalf("%d rows, want %d", len(sheet.Rows), len(rows)+1)
+	}
+
+	want := map[string]cell{
+		"A1": {Ref: "A1", Type: "inlineStr", Text: "company_name"},
+		"A2": {Ref: "A2", Type: "inlineStr", Text: "Alfreds Futterkiste"},
+		"B2": {Ref: "B2", Type: "n", Value: "32.38"},
+		"A3": {Ref: "A3", Type: "inlineStr", Style: "1", Text: `=HYPERLINK("http://evil","x")`},
+		"B3": {Ref: "B3", Type: "n", Value: "-1.5"},
+		"C3": {Ref: "C3", Type: "inlineStr", Style: "1", Text: "@SUM(A1)"},
+		"A4": {Ref: "A4", Type: "inlineStr", Style: "1", Text: "-Minus Corp"},
+		"C4": {Ref: "C4", Type: "inlineStr", Style: "1", Text: "+49"},
+	}
+	got := map[string]cell{}
+	for _, r := range sheet.Rows {
+		for _, c := range r.Cells {
+			got[c.Ref] = c
+		}
+	}
+	if _, ok := got["C2"]; ok {
+		t.Error("a nil value was written as a cell")
+	}
+	for ref, w := range want {
+		if got[ref] != w {
+			t.Errorf("cell %s = %+v, want %+v", ref, got[ref], w)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+// Package fieldset parses the fields query parameter and trims response rows
+// down to the requested projection.
+//
+// The parameter is a comma-separated list of JSON field names:
+// fields=order_id,customer_name,freight. Omitting it returns every field.
+package fieldset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Param is the query parameter read by Spec.Parse.
+const Param = "fields"
+
+// Spec lists the fields an endpoint returns, in response order.
+type Spec []string
+
+// Of lists the JSON field names of struct type T in declaration order.
+func Of[T any]() Spec {
+	spec := Spec{}
+	for _, f := range reflect.VisibleFields(reflect.TypeFor[T]()) {
+		if name, ok := jsonName(f); ok {
+			spec = append(spec, name)
+		}
+	}
+	return spec
+}
+
+func jsonName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() || f.Anonymous {
+		return "", false
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return f.Name, true
+	}
+	return name, true
+}
+
+// Without returns s minus the named fields, for models that carry fields an
+// endpoint never fills.
+func (s Spec) Without(names ...string) Spec {
+	out := Spec{}
+	for _, f := range s {
+		if !Spec(names).Has(f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Set is the projection a client asked for, in the order it was given. A nil
+// Set selects every field.
+type Set []string
+
+// Error reports an invalid fields parameter.
+type Error struct {
+	Message string
+}
+
+func (e *Error) Error() string {
+	return Param + " " + e.Message
+}
+
+// Has reports whether field is part of s.
+func (s Spec) Has(field string) bool {
+	for _, f := range s {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse validates the fields parameter in q against s. Duplicates are
+// dropped; an absent or empty parameter yields a nil Set.
+func (s Spec) Parse(q url.Values) (Set, error) {
+	raw := strings.TrimSpace(q.Get(Param))
+	if raw == "" {
+		return nil, nil
+	}
+
+	var set Set
+	seen := map[string]bool{}
+	unknown := []string{}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" || seen[part] {
+			continue
+		}
+		seen[part] = true
+		if !s.Has(part) {
+			unknown = append(unknown, part)
+			continue
+		}
+		set = append(set, part)
+	}
+
+	if len(unknown) > 0 {
+		return nil, &Error{Message: fmt.Sprintf("has unknown %s %s; available fields are %s",
+			plural(len(unknown), "field", "fields"), strings.Join(unknown, ", "), strings.Join(s, ", "))}
+	}
+	return set, nil
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// Object is a JSON object that keeps its members in order.
+type Object []Member
+
+// Member is one name/value pair of an Object.
+type Member struct {
+	Name  string
+	Value any
+}
+
+func (o Object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, err := json.Marshal(m.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// Getter returns a function that reads the named fields of a T, in order.
+// Names T does not have read as nil. T may be Object, whose members are read
+// by name.
+func Getter[T any](names []string) func(row *T) []any {
+	if _, ok := any((*T)(nil)).(*Object); ok {
+		return func(row *T) []any {
+			obj := any(row).(*Object)
+			values := make([]any, len(names))
+			for i, name := range names {
+				for _, m := range *obj {
+					if m.Name == name {
+						values[i] = m.Value
+						break
+					}
+				}
+			}
+			return values
+		}
+	}
+
+	index := map[string][]int{}
+	for _, f := range reflect.VisibleFields(reflect.TypeFor[T]()) {
+		if name, ok := jsonName(f); ok {
+			index[name] = f.Index
+		}
+	}
+
+	return func(row *T) []any {
+		rv := reflect.ValueOf(row).Elem()
+		values := make([]any, len(names))
+		for i, name := range names {
+			if idx, ok := index[name]; ok {
+				values[i] = rv.FieldByIndex(idx).Interface()
+			}
+		}
+		return values
+	}
+}
+
+// NewObject pairs names with values.
+func NewObject(names []string, values []any) Object {
+	obj := make(Object, len(names))
+	for i, name := range names {
+		obj[i] = Member{Name: name, Value: values[i]}
+	}
+	return obj
+}
+
+// Project trims every row to the fields in s. With a nil Set the rows are
+// returned unchanged.
+func Project[T any](rows []T, s Set) any {
+	if s == nil {
+		return rows
+	}
+
+	get := Getter[T](s)
+	out := make([]Object, len(rows))
+	for i := range rows {
+		out[i] = NewObject(s, get(&rows[i]))
+	}
+	return out
+}
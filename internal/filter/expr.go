@@ -0,0 +1,151 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expr is a boolean expression over fields, for query languages richer than
+// the per-field filters of a Spec, such as OData's $filter. Whoever builds
+// an Expr checks its field names and value types; SQL only renders it.
+type Expr interface {
+	// SQL renders the expression as one condition, with cols mapping field
+	// names to columns and bound values appended to args.
+	SQL(cols Columns, args *[]any) string
+}
+
+// Fold selects a case conversion applied to a field before comparing it.
+type Fold int
+
+const (
+	NoFold Fold = iota
+	Lower
+	Upper
+)
+
+// Ref is a field in an Expr.
+type Ref struct {
+	Field string
+	Fold  Fold
+}
+
+func (r Ref) sql(cols Columns) string {
+	col, ok := cols[r.Field]
+	if !ok {
+		// Builders only name known fields; an unmapped one matches nothing.
+		return "NULL"
+	}
+	if strings.ContainsAny(col, " (") {
+		col = "(" + col + ")"
+	}
+	switch r.Fold {
+	case Lower:
+		return "LOWER(" + col + ")"
+	case Upper:
+		return "UPPER(" + col + ")"
+	}
+	return col
+}
+
+// CompareOp is a comparison operator, spelled as in SQL.
+type CompareOp string
+
+const (
+	Eq CompareOp = "="
+	// Ne treats null as a value, so a null field differs from any value.
+	Ne CompareOp = "IS DISTINCT FROM"
+	Gt CompareOp = ">"
+	Ge CompareOp = ">="
+	Lt CompareOp = "<"
+	Le CompareOp = "<="
+)
+
+// Compare compares a field with a value. A nil Value is null, which only Eq
+// and Ne accept.
+type Compare struct {
+	Ref   Ref
+	Op    CompareOp
+	Value any
+}
+
+func (c Compare) SQL(cols Columns, args *[]any) string {
+	col := c.Ref.sql(cols)
+	if c.Value == nil {
+		if c.Op == Ne {
+			return col + " IS NOT NULL"
+		}
+		return col + " IS NULL"
+	}
+	return fmt.Sprintf("%s %s %s", col, c.Op, bind(args, c.Value))
+}
+
+// MatchKind is where a Match looks for its text.
+type MatchKind int
+
+const (
+	StartsWith MatchKind = iota
+	EndsWith
+	ContainsText
+)
+
+// Match finds text within a field, case-sensitively unless the field is
+// folded.
+type Match struct {
+	Ref   Ref
+	Kind  MatchKind
+	Value string
+}
+
+func (m Match) SQL(cols Columns, args *[]any) string {
+	pattern := escapeLike(m.Value)
+	switch m.Kind {
+	case StartsWith:
+		pattern += "%"
+	case EndsWith:
+		pattern = "%" + pattern
+	default:
+		pattern = "%" + pattern + "%"
+	}
+	return fmt.Sprintf("%s LIKE %s", m.Ref.sql(cols), bind(args, pattern))
+}
+
+// OneOf matches a field equal to any of Values.
+type OneOf struct {
+	Ref    Ref
+	Values []any
+}
+
+func (o OneOf) SQL(cols Columns, args *[]any) string {
+	placeholders := make([]string, len(o.Values))
+	for i, v := range o.Values {
+		placeholders[i] = bind(args, v)
+	}
+	return fmt.Sprintf("%s IN (%s)", o.Ref.sql(cols), strings.Join(placeholders, ", "))
+}
+
+// And holds when both sides hold.
+type And struct{ Left, Right Expr }
+
+func (a And) SQL(cols Columns, args *[]any) string {
+	return fmt.Sprintf("(%s AND %s)", a.Left.SQL(cols, args), a.Right.SQL(cols, args))
+}
+
+// Or holds when either side holds.
+type Or struct{ Left, Right Expr }
+
+func (o Or) SQL(cols Columns, args *[]any) string {
+	return fmt.Sprintf("(%s OR %s)", o.Left.SQL(cols, args), o.Right.SQL(cols, args))
+}
+
+// Not negates X. A comparison with a null field counts as false, so its
+// negation holds.
+type Not struct{ X Expr }
+
+func (n Not) SQL(cols Columns, args *[]any) string {
+	return fmt.Sprintf("NOT COALESCE(%s, FALSE)", n.X.SQL(cols, args))
+}
+
+func bind(args *[]any, v any) string {
+	*args = append(*args, v)
+	return fmt.Sprintf("$%d", len(*args))
+}
@@ -0,0 +1,513 @@
+// Package filter turns query-string filters into parameterized SQL.
+//
+// An endpoint declares a Spec listing each filterable field with its value
+// type and match mode. Spec.Parse validates the request's query string into a
+// Set, which can render WHERE conditions against a backend's column mapping
+// and echo the applied filters back to the caller.
+package filter
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type is the value type of a filter.
+type Type int
+
+const (
+	Text Type = iota
+	Int
+	Date
+	Bool
+	Money
+)
+
+// SchemaType returns the JSON Schema type used to describe values of t.
+func (t Type) SchemaType() string {
+	switch t {
+	case Int:
+		return "integer"
+	case Bool:
+		return "boolean"
+	case Money:
+		return "number"
+	}
+	return "string"
+}
+
+// Mode controls how a filter value is matched.
+type Mode int
+
+const (
+	// Exact matches the whole value; text is compared case-insensitively.
+	Exact Mode = iota
+	// Prefix matches text starting with the value.
+	Prefix
+	// Contains matches text containing the value.
+	Contains
+	// Range accepts an exact value plus inclusive <name>_from and <name>_to
+	// bounds.
+	Range
+	// In accepts a comma-separated list of exact values.
+	In
+)
+
+// Range bound suffixes.
+const (
+	FromSuffix = "_from"
+	ToSuffix   = "_to"
+)
+
+// Field declares one filterable query parameter.
+type Field struct {
+	Name        string
+	Type        Type
+	Mode        Mode
+	Description string
+}
+
+// Spec is the set of filters an endpoint accepts.
+type Spec []Field
+
+// Columns maps filter names to the SQL expression they apply to. A Set only
+// renders conditions for names present in the mapping, which lets a query
+// apply some filters inside a CTE and the rest to its outer SELECT.
+type Columns map[string]string
+
+// Param describes a single query parameter accepted by a Spec.
+type Param struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+// Params lists the query parameters accepted by s, including range bounds.
+func (s Spec) Params() []Param {
+	params := []Param{}
+	for _, f := range s {
+		switch f.Mode {
+		case Range:
+			params = append(params,
+				Param{Name: f.Name, Type: f.Type.SchemaType(), Description: f.Description},
+				Param{Name: f.Name + FromSuffix, Type: f.Type.SchemaType(), Description: f.Description + " (lower bound, inclusive)"},
+				Param{Name: f.Name + ToSuffix, Type: f.Type.SchemaType(), Description: f.Description + " (upper bound, inclusive)"},
+			)
+		case In:
+			params = append(params, Param{Name: f.Name, Type: "string", Description: f.Description + " (comma-separated list)"})
+		default:
+			params = append(params, Param{Name: f.Name, Type: f.Type.SchemaType(), Description: f.Description})
+		}
+	}
+	return params
+}
+
+// Lookup returns the field called name.
+func (s Spec) Lookup(name string) (Field, bool) {
+	for _, f := range s {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+type op int
+
+const (
+	opEq op = iota
+	opPrefix
+	opContains
+	opFrom
+	opTo
+	opIn
+)
+
+type condition struct {
+	field Field
+	param string
+	op    op
+	// values holds one element for every op except opIn. Dates are stored as
+	// dateSpan so partial dates can match a whole month or year.
+	values []any
+	echo   any
+}
+
+// Set is a validated collection of filters ready to be rendered as SQL.
+type Set struct {
+	conds []condition
+}
+
+// Parse validates every filter in q declared by s. Parameters s does not
+// declare are ignored; empty values are treated as absent.
+func (s Spec) Parse(q url.Values) (Set, error) {
+	var set Set
+	var errs Errors
+
+	add := func(f Field, param string, o op) {
+		raw := strings.TrimSpace(q.Get(param))
+		if raw == "" {
+			return
+		}
+		c, err := parseCondition(f, param, o, raw)
+		if err != nil {
+			errs = append(errs, FieldError{Field: param, Message: err.Error()})
+			return
+		}
+		set.conds = append(set.conds, c)
+	}
+
+	for _, f := range s {
+		switch f.Mode {
+		case Prefix:
+			add(f, f.Name, opPrefix)
+		case Contains:
+			add(f, f.Name, opContains)
+		case Range:
+			add(f, f.Name, opEq)
+			add(f, f.Name+FromSuffix, opFrom)
+			add(f, f.Name+ToSuffix, opTo)
+		case In:
+			add(f, f.Name, opIn)
+		default:
+			add(f, f.Name, opEq)
+		}
+	}
+
+	if len(errs) > 0 {
+		return Set{}, errs
+	}
+	return set, nil
+}
+
+func parseCondition(f Field, param string, o op, raw string) (condition, error) {
+	c := condition{field: f, param: param, op: o}
+
+	if o == opPrefix || o == opContains {
+		c.values = []any{raw}
+		c.echo = raw
+		return c, nil
+	}
+
+	parts := []string{raw}
+	if o == opIn {
+		parts = strings.Split(raw, ",")
+	}
+
+	echo := []any{}
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		v, e, err := parseValue(f.Type, p)
+		if err != nil {
+			return condition{}, err
+		}
+		c.values = append(c.values, v)
+		echo = append(echo, e)
+	}
+	if len(c.values) == 0 {
+		return condition{}, fmt.Errorf("must not be empty")
+	}
+
+	if o == opIn && len(echo) > 1 {
+		c.echo = echo
+	} else {
+		c.echo = echo[0]
+		if o == opIn {
+			c.op = opEq
+		}
+	}
+	return c, nil
+}
+
+// parseValue converts raw into the SQL argument and the value echoed back in
+// the response.
+func parseValue(t Type, raw string) (any, any, error) {
+	switch t {
+	case Int:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("must be an integer")
+		}
+		return n, n, nil
+	case Money:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("must be a number")
+		}
+		return n, n, nil
+	case Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("must be true or false")
+		}
+		return b, b, nil
+	case Date:
+		span, err := parseDate(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		return span, raw, nil
+	}
+	return raw, raw, nil
+}
+
+// dateSpan is the half-open interval [start, end) covered by a full or
+// partial date such as 1997, 1997-05 or 1997-05-14.
+type dateSpan struct {
+	start, end time.Time
+}
+
+func parseDate(raw string) (dateSpan, error) {
+	layouts := []struct {
+		layout string
+		next   func(time.Time) time.Time
+	}{
+		{"2006-01-02", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+		{"2006-01", func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+		{"2006", func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }},
+	}
+	for _, l := range layouts {
+		if t, err := time.Parse(l.layout, raw); err == nil {
+			return dateSpan{start: t, end: l.next(t)}, nil
+		}
+	}
+	return dateSpan{}, fmt.Errorf("must be a date (YYYY, YYYY-MM or YYYY-MM-DD)")
+}
+
+// echo formats span as the caller would have written it: a year or month
+// when it covers exactly one, otherwise the day the condition uses.
+func (d dateSpan) echo(o op) string {
+	switch {
+	case d.start.YearDay() == 1 && d.end.Equal(d.start.AddDate(1, 0, 0)):
+		return d.start.Format("2006")
+	case d.start.Day() == 1 && d.end.Equal(d.start.AddDate(0, 1, 0)):
+		return d.start.Format("2006-01")
+	case o == opTo:
+		return d.end.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+	return d.start.Format("2006-01-02")
+}
+
+// Where renders a condition for every filter in s whose name appears in
+// cols, appending the bound values to args.
+func (s Set) Where(cols Columns, args *[]any) []string {
+	conds := []string{}
+	for _, c := range s.conds {
+		col, ok := cols[c.field.Name]
+		if !ok {
+			continue
+		}
+		conds = append(conds, c.sql(col, args))
+	}
+	return conds
+}
+
+func (c condition) sql(col string, args *[]any) string {
+	bind := func(v any) string {
+		*args = append(*args, v)
+		return fmt.Sprintf("$%d", len(*args))
+	}
+
+	text := c.field.Type == Text
+	if strings.ContainsAny(col, " (") {
+		col = "(" + col + ")"
+	}
+
+	switch c.op {
+	case opPrefix:
+		return fmt.Sprintf("LOWER(%s) LIKE LOWER(%s)", col, bind(escapeLike(c.values[0].(string))+"%"))
+	case opContains:
+		return fmt.Sprintf("LOWER(%s) LIKE LOWER(%s)", col, bind("%"+escapeLike(c.values[0].(string))+"%"))
+	case opIn:
+		placeholders := make([]string, len(c.values))
+		for i, v := range c.values {
+			if text {
+				placeholders[i] = "LOWER(" + bind(v) + ")"
+			} else {
+				placeholders[i] = bind(v)
+			}
+		}
+		if text {
+			col = "LOWER(" + col + ")"
+		}
+		return fmt.Sprintf("%s IN (%s)", col, strings.Join(placeholders, ", "))
+	}
+
+	if span, ok := c.values[0].(dateSpan); ok {
+		switch c.op {
+		case opFrom:
+			return fmt.Sprintf("%s >= %s", col, bind(span.start))
+		case opTo:
+			return fmt.Sprintf("%s < %s", col, bind(span.end))
+		}
+		return fmt.Sprintf("%s >= %s AND %s < %s", col, bind(span.start), col, bind(span.end))
+	}
+
+	switch c.op {
+	case opFrom:
+		return fmt.Sprintf("%s >= %s", col, bind(c.values[0]))
+	case opTo:
+		return fmt.Sprintf("%s <= %s", col, bind(c.values[0]))
+	}
+	if text {
+		return fmt.Sprintf("LOWER(%s) = LOWER(%s)", col, bind(c.values[0]))
+	}
+	return fmt.Sprintf("%s = %s", col, bind(c.values[0]))
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// Echo returns the applied filters keyed by query parameter, for the
+// response's "filters" object.
+func (s Set) Echo() map[string]any {
+	out := map[string]any{}
+	for _, c := range s.conds {
+		out[c.param] = c.echo
+	}
+	return out
+}
+
+type contextKey struct{}
+
+// NewContext returns ctx carrying s as the filters of the request ctx
+// serves, for traces and logs to report.
+func NewContext(ctx context.Context, s Set) context.Context {
+	return context.WithValue(ctx, contextKey{}, s)
+}
+
+// FromContext returns the filters ctx carries, if any.
+func FromContext(ctx context.Context) (Set, bool) {
+	s, ok := ctx.Value(contextKey{}).(Set)
+	return s, ok
+}
+
+// Has reports whether the filter called name was supplied.
+func (s Set) Has(name string) bool {
+	for _, c := range s.conds {
+		if c.field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns the parsed value of an exact-match filter.
+func (s Set) Value(name string) (any, bool) {
+	for _, c := range s.conds {
+		if c.field.Name == name && c.op == opEq {
+			return c.values[0], true
+		}
+	}
+	return nil, false
+}
+
+// Bounds returns the interval [from, to) that the date filter called name
+// restricts values to, combining its exact value and its range bounds. A nil
+// bound is open.
+func (s Set) Bounds(name string) (from, to *time.Time) {
+	for _, c := range s.conds {
+		span, ok := c.values[0].(dateSpan)
+		if c.field.Name != name || !ok {
+			continue
+		}
+		if c.op == opEq || c.op == opFrom {
+			if from == nil || span.start.After(*from) {
+				from = &span.start
+			}
+		}
+		if c.op == opEq || c.op == opTo {
+			if to == nil || span.end.Before(*to) {
+				to = &span.end
+			}
+		}
+	}
+	return from, to
+}
+
+// Ints returns the values of the integer filter called name.
+func (s Set) Ints(name string) []int64 {
+	var out []int64
+	for _, c := range s.conds {
+		if c.field.Name != name || c.field.Type != Int {
+			continue
+		}
+		for _, v := range c.values {
+			out = append(out, v.(int64))
+		}
+	}
+	return out
+}
+
+// AddInt returns a copy of s with n added to every value of the integer
+// filter called name.
+func (s Set) AddInt(name string, n int64) Set {
+	return s.mapValues(name, Int, func(v any) (any, any) {
+		shifted := v.(int64) + n
+		return shifted, shifted
+	})
+}
+
+// AddDate returns a copy of s with every value of the date filter called
+// name moved by the given years, months and days, as time.Time.AddDate would
+// move it.
+func (s Set) AddDate(name string, years, months, days int) Set {
+	out := Set{conds: make([]condition, len(s.conds))}
+	for i, c := range s.conds {
+		if c.field.Name == name && c.field.Type == Date {
+			span := c.values[0].(dateSpan)
+			span = dateSpan{start: span.start.AddDate(years, months, days), end: span.end.AddDate(years, months, days)}
+			c.values = []any{span}
+			c.echo = span.echo(c.op)
+		}
+		out.conds[i] = c
+	}
+	return out
+}
+
+// mapValues returns a copy of s with fn applied to every value of the filter
+// called name. fn returns the new value and its echo.
+func (s Set) mapValues(name string, t Type, fn func(any) (any, any)) Set {
+	out := Set{conds: make([]condition, len(s.conds))}
+	for i, c := range s.conds {
+		if c.field.Name == name && c.field.Type == t {
+			values := make([]any, len(c.values))
+			echo := make([]any, len(c.values))
+			for j, v := range c.values {
+				values[j], echo[j] = fn(v)
+			}
+			c.values = values
+			if len(echo) == 1 {
+				c.echo = echo[0]
+			} else {
+				c.echo = echo
+			}
+		}
+		out.conds[i] = c
+	}
+	return out
+}
+
+// FieldError describes one invalid filter value.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors collects every invalid filter in a request.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Field + " " + fe.Message
+	}
+	return "invalid filters: " + strings.Join(msgs, "; ")
+}
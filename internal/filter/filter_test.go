@@ -0,0 +1,229 @@
+package filter
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSpec = Spec{
+	{Name: "country", Type: Text},
+	{Name: "company_name", Type: Text, Mode: Prefix},
+	{Name: "contact_name", Type: Text, Mode: Contains},
+	{Name: "order_date", Type: Date, Mode: Range},
+	{Name: "freight", Type: Money, Mode: Range},
+	{Name: "employee_id", Type: Int, Mode: In},
+	{Name: "discontinued", Type: Bool},
+}
+
+var testColumns = Columns{
+	"country":      "c.country",
+	"company_name": "c.company_name",
+	"contact_name": "c.contact_name",
+	"order_date":   "o.order_date",
+	"freight":      "o.freight",
+	"employee_id":  "o.employee_id",
+	"discontinued": "p.discontinued",
+}
+
+func day(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParseWhere(t *testing.T) {
+	tests := []struct {
+		query string
+		where []string
+		args  []any
+		echo  map[string]any
+	}{
+		{"", []string{}, nil, map[string]any{}},
+		{"unknown=1&country=", []string{}, nil, map[string]any{}},
+		{
+			"country=Germany",
+			[]string{"LOWER(c.country) = LOWER($1)"},
+			[]any{"Germany"},
+			map[string]any{"country": "Germany"},
+		},
+		{
+			"company_name=Al_f%25",
+			[]string{`LOWER(c.company_name) LIKE LOWER($1)`},
+			[]any{`Al\_f\%%`},
+			map[string]any{"company_name": "Al_f%"},
+		},
+		{
+			"contact_name=ann",
+			[]string{"LOWER(c.contact_name) LIKE LOWER($1)"},
+			[]any{"%ann%"},
+			map[string]any{"contact_name": "ann"},
+		},
+		{
+			"order_date=1997",
+			[]string{"o.order_date >= $1 AND o.order_date < $2"},
+			[]any{day("1997-01-01"), day("1998-01-01")},
+			map[string]any{"order_date": "1997"},
+		},
+		{
+			"order_date_from=1997-05&order_date_to=1997-05-14",
+			[]string{"o.order_date >= $1", "o.order_date < $2"},
+			[]any{day("1997-05-01"), day("1997-05-15")},
+			map[string]any{"order_date_from": "1997-05", "order_date_to": "1997-05-14"},
+		},
+		{
+			"freight_from=10.5&freight_to=100",
+			[]string{"o.freight >= $1", "o.freight <= $2"},
+			[]any{10.5, 100.0},
+			map[string]any{"freight_from": 10.5, "freight_to": 100.0},
+		},
+		{
+			"employee_id=1,%202,,3",
+			[]string{"o.employee_id IN ($1, $2, $3)"},
+			[]any{int64(1), int64(2), int64(3)},
+			map[string]any{"employee_id": []any{int64(1), int64(2), int64(3)}},
+		},
+		{
+			"employee_id=4",
+			[]string{"o.employee_id = $1"},
+			[]any{int64(4)},
+			map[string]any{"employee_id": int64(4)},
+		},
+		{
+			"discontinued=true",
+			[]string{"p.discontinued = $1"},
+			[]any{true},
+			map[string]any{"discontinued": true},
+		},
+	}
+	for _, tt := range tests {
+		q, err := url.ParseQuery(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		set, err := testSpec.Parse(q)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.query, err)
+			continue
+		}
+		var args []any
+		if where := set.Where(testColumns, &args); !reflect.DeepEqual(where, tt.where) {
+			t.Errorf("Parse(%q).Where = %q, want %q", tt.query, where, tt.where)
+		}
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("Parse(%q) args = %v, want %v", tt.query, args, tt.args)
+		}
+		if echo := set.Echo(); !reflect.DeepEqual(echo, tt.echo) {
+			t.Errorf("Parse(%q).Echo = %v, want %v", tt.query, echo, tt.echo)
+		}
+	}
+}
+
+// TestParseListsEveryError fails when Parse stops at the first bad filter.
+func TestParseListsEveryError(t *testing.T) {
+	q := url.Values{
+		"order_date":   {"May 1997"},
+		"freight_to":   {"lots"},
+		"employee_id":  {"1,two"},
+		"discontinued": {"maybe"},
+		"country":      {"Germany"},
+	}
+	_, err := testSpec.Parse(q)
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Parse: %v, want Errors", err)
+	}
+	got := map[string]string{}
+	for _, fe := range errs {
+		got[fe.Field] = fe.Message
+	}
+	want := map[string]string{
+		"order_date":   "must be a date (YYYY, YYYY-MM or YYYY-MM-DD)",
+		"freight_to":   "must be a number",
+		"employee_id":  "must be an integer",
+		"discontinued": "must be true or false",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("errors = %v, want %v", got, want)
+	}
+}
+
+func TestParseRejectsEmptyList(t *testing.T) {
+	if _, err := testSpec.Parse(url.Values{"employee_id": {" , ,"}}); err == nil || !strings.Contains(err.Error(), "must not be empty") {
+		t.Errorf("Parse(employee_id= , ,) = %v, want must not be empty", err)
+	}
+}
+
+// TestWhereSkipsUnmappedColumns checks that a Set renders only the filters
+// a query maps, so others can apply elsewhere.
+func TestWhereSkipsUnmappedColumns(t *testing.T) {
+	set, err := testSpec.Parse(url.Values{"country": {"USA"}, "freight": {"5"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var args []any
+	where := set.Where(Columns{"freight": "SUM(o.freight)"}, &args)
+	if want := []string{"(SUM(o.freight)) = $1"}; !reflect.DeepEqual(where, want) {
+		t.Errorf("Where = %q, want %q", where, want)
+	}
+}
+
+func TestBoundsAndAddDate(t *testing.T) {
+	set, err := testSpec.Parse(url.Values{"order_date": {"1997"}, "order_date_from": {"1997-03-15"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, to := set.Bounds("order_date")
+	if from == nil || !from.Equal(day("1997-03-15")) || to == nil || !to.Equal(day("1998-01-01")) {
+		t.Errorf("Bounds = %v, %v", from, to)
+	}
+
+	prior := set.AddDate("order_date", -1, 0, 0)
+	want := map[string]any{"order_date": "1996", "order_date_from": "1996-03-15"}
+	if echo := prior.Echo(); !reflect.DeepEqual(echo, want) {
+		t.Errorf("AddDate echo = %v, want %v", echo, want)
+	}
+	if echo := set.Echo(); echo["order_date"] != "1997" {
+		t.Errorf("AddDate changed the original set: %v", echo)
+	}
+}
+
+func TestExprSQL(t *testing.T) {
+	cols := Columns{"name": "c.name", "region": "c.region", "total": "SUM(o.freight)"}
+	tests := []struct {
+		expr Expr
+		sql  string
+		args []any
+	}{
+		{Compare{Ref: Ref{Field: "region"}, Op: Eq}, "c.region IS NULL", nil},
+		{Compare{Ref: Ref{Field: "region"}, Op: Ne}, "c.region IS NOT NULL", nil},
+		{Compare{Ref: Ref{Field: "region"}, Op: Ne, Value: "WA"}, "c.region IS DISTINCT FROM $1", []any{"WA"}},
+		{Compare{Ref: Ref{Field: "total"}, Op: Gt, Value: 5.0}, "(SUM(o.freight)) > $1", []any{5.0}},
+		{Compare{Ref: Ref{Field: "missing"}, Op: Eq, Value: 1}, "NULL = $1", []any{1}},
+		{Match{Ref: Ref{Field: "name", Fold: Lower}, Kind: StartsWith, Value: "a_b"}, `LOWER(c.name) LIKE $1`, []any{`a\_b%`}},
+		{Match{Ref: Ref{Field: "name", Fold: Upper}, Kind: EndsWith, Value: "X"}, `UPPER(c.name) LIKE $1`, []any{`%X`}},
+		{OneOf{Ref: Ref{Field: "region"}, Values: []any{"WA", "OR"}}, "c.region IN ($1, $2)", []any{"WA", "OR"}},
+		{
+			Not{X: Or{
+				Left:  And{Left: Compare{Ref: Ref{Field: "region"}, Op: Eq, Value: "WA"}, Right: Match{Ref: Ref{Field: "name"}, Kind: ContainsText, Value: "co"}},
+				Right: Compare{Ref: Ref{Field: "total"}, Op: Le, Value: 1.0},
+			}},
+			"NOT COALESCE(((c.region = $1 AND c.name LIKE $2) OR (SUM(o.freight)) <= $3), FALSE)",
+			[]any{"WA", "%co%", 1.0},
+		},
+	}
+	for _, tt := range tests {
+		var args []any
+		if sql := tt.expr.SQL(cols, &args); sql != tt.sql {
+			t.Errorf("%#v.SQL = %q, want %q", tt.expr, sql, tt.sql)
+		}
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%#v args = %v, want %v", tt.expr, args, tt.args)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/page"
+	"github.com/nicholasraynes/northwind-api/internal/sorting"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// relation loads the T records that belong to a set of parents through the
+// store's list method for T, using the In filter named key to match parent
+// values. Every parent's records come back in one query.
+type relation[T any] struct {
+	spec  filter.Spec
+	sorts sorting.Spec
+	key   string
+	list  func(ctx context.Context, q store.Query) (store.Rows[T], error)
+	// keyOf reads the value of key from a loaded record.
+	keyOf func(v T) string
+}
+
+// args lists the arguments a nested field over r accepts: its filters,
+// except the one that joins it to the parent, and a sort order.
+func (r relation[T]) args() graphql.FieldConfigArgument {
+	spec := filter.Spec{}
+	for _, f := range r.spec {
+		if f.Name != r.key {
+			spec = append(spec, f)
+		}
+	}
+	args := filterArgs(spec)
+	args[sorting.Param] = sortArg(r.sorts)
+	return args
+}
+
+// batch collects the parent keys one nested field is asked for across a
+// response level. The first thunk that runs loads every queued key at once;
+// keys seen in an earlier level are served from what was loaded then.
+type batch[T any] struct {
+	mu      sync.Mutex
+	load    func(keys []string) ([]T, error)
+	keyOf   func(v T) string
+	pending []string
+	queued  map[string]bool
+	loaded  map[string][]T
+	failed  map[string]error
+}
+
+// thunk queues key and returns a deferred result for it, which the executor
+// calls once every sibling has queued its own key.
+func (b *batch[T]) thunk(key string) func() ([]T, error) {
+	b.mu.Lock()
+	if !b.queued[key] {
+		b.queued[key] = true
+		b.pending = append(b.pending, key)
+	}
+	b.mu.Unlock()
+
+	return func() ([]T, error) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if len(b.pending) > 0 {
+			keys := b.pending
+			b.pending = nil
+			rows, err := b.load(keys)
+			for _, k := range keys {
+				b.failed[k] = err
+			}
+			for _, v := range rows {
+				k := b.keyOf(v)
+				b.loaded[k] = append(b.loaded[k], v)
+			}
+		}
+		if err := b.failed[key]; err != nil {
+			return nil, err
+		}
+		return b.loaded[key], nil
+	}
+}
+
+// batches holds the batches of one request, one per nested field and set of
+// arguments, and the number of nested records they may still load.
+type batches struct {
+	mu   sync.Mutex
+	m    map[string]any
+	left int
+}
+
+type batchesKey struct{}
+
+func withBatches(ctx context.Context) context.Context {
+	return context.WithValue(ctx, batchesKey{}, &batches{m: map[string]any{}, left: maxNestedRows})
+}
+
+// errTooManyRows fails a nested field whose records would exceed what is left
+// of maxNestedRows.
+var errTooManyRows = fmt.Errorf("nested fields would load more than %d records; narrow them with filters", maxNestedRows)
+
+// remaining returns how many more nested records the request may load.
+func (bs *batches) remaining() int {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	return bs.left
+}
+
+// spend counts n loaded records against the request, reporting false when
+// fewer than n were left.
+func (bs *batches) spend(n int) bool {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if n > bs.left {
+		bs.left = 0
+		return false
+	}
+	bs.left -= n
+	return true
+}
+
+// batchFor returns the batch for the field p resolves under its arguments,
+// creating it on first use. The arguments are checked here, so a bad filter
+// fails the field rather than its deferred load.
+func batchFor[T any](p graphql.ResolveParams, r relation[T]) (*batch[T], error) {
+	bs := p.Context.Value(batchesKey{}).(*batches)
+	args, _ := json.Marshal(p.Args)
+	id := p.Info.ParentType.Name() + "." + p.Info.FieldName + string(args)
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if b, ok := bs.m[id]; ok {
+		return b.(*batch[T]), nil
+	}
+
+	values, err := argValues(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.spec.Parse(values); err != nil {
+		return nil, err
+	}
+	o, err := r.sorts.Parse(values)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := p.Context
+	b := &batch[T]{
+		keyOf:  r.keyOf,
+		queued: map[string]bool{},
+		loaded: map[string][]T{},
+		failed: map[string]error{},
+		load: func(keys []string) ([]T, error) {
+			values.Set(r.key, strings.Join(keys, ","))
+			f, err := r.spec.Parse(values)
+			if err != nil {
+				return nil, err
+			}
+			// One row past the budget tells a full load from one cut short.
+			pg := page.Request{Limit: bs.remaining() + 1}
+			rows, err := r.list(ctx, store.Query{Filters: f, Sort: o, Page: pg})
+			if err != nil {
+				return nil, storeError(ctx, err)
+			}
+			res, err := store.Collect(rows)
+			if err != nil {
+				return nil, storeError(ctx, err)
+			}
+			if !bs.spend(len(res.Rows)) {
+				return nil, errTooManyRows
+			}
+			return res.Rows, nil
+		},
+	}
+	bs.m[id] = b
+	return b, nil
+}
+
+// hasMany resolves a list of the T records belonging to the parent whose key
+// parentKey reads.
+func hasMany[P, T any](r relation[T], parentKey func(v P) string) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		b, err := batchFor(p, r)
+		if err != nil {
+			return nil, err
+		}
+		load := b.thunk(parentKey(p.Source.(P)))
+		return func() (any, error) {
+			rows, err := load()
+			if rows == nil {
+				rows = []T{}
+			}
+			return rows, err
+		}, nil
+	}
+}
+
+// belongsTo resolves the single T record the parent refers to, or null when
+// it has been deleted.
+func belongsTo[P, T any](r relation[T], parentKey func(v P) string) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		b, err := batchFor(p, r)
+		if err != nil {
+			return nil, err
+		}
+		load := b.thunk(parentKey(p.Source.(P)))
+		return func() (any, error) {
+			rows, err := load()
+			if err != nil || len(rows) == 0 {
+				return nil, err
+			}
+			return rows[0], nil
+		}, nil
+	}
+}
@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+const (
+	// maxDepth bounds how deeply fields may nest. The schema is cyclic, so
+	// without it one query could walk customers, orders and customers again
+	// indefinitely.
+	maxDepth = 8
+	// maxNestedRows bounds the records nested fields may load across one
+	// request. Root collections are paged and do not count against it.
+	maxNestedRows = 10 * 1000
+)
+
+// checkQuery reports an error when an operation in query nests fields deeper
+// than maxDepth, or when a fragment spreads itself: graphql-go's validator
+// recurses through such a cycle until the stack overflows, taking the process
+// down with it. Introspection fields are exempt, as they never reach the
+// store. A query that does not parse passes, leaving graphql.Do to report
+// the syntax error.
+func checkQuery(query string) error {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return nil
+	}
+
+	d := &depths{fragments: map[string]*ast.FragmentDefinition{}, memo: map[string]int{}}
+	for _, def := range doc.Definitions {
+		if f, ok := def.(*ast.FragmentDefinition); ok {
+			d.fragments[f.Name.Value] = f
+		}
+	}
+	for _, def := range doc.Definitions {
+		var n int
+		switch def := def.(type) {
+		case *ast.OperationDefinition:
+			n = d.of(def.SelectionSet)
+		case *ast.FragmentDefinition:
+			// Unused fragments are checked too, as the validator walks
+			// them all.
+			n = d.fragment(def.Name.Value)
+		}
+		if d.cycle != "" {
+			return fmt.Errorf("fragment %s spreads itself", d.cycle)
+		}
+		if n > maxDepth {
+			return fmt.Errorf("query nests %d levels deep; at most %d are allowed", n, maxDepth)
+		}
+	}
+	return nil
+}
+
+// depths measures selection sets, following fragment spreads.
+type depths struct {
+	fragments map[string]*ast.FragmentDefinition
+	// memo holds the depth of each fragment measured so far, and -1 while
+	// one is being measured.
+	memo map[string]int
+	// cycle names the first fragment found spreading itself.
+	cycle string
+}
+
+// of returns the depth of the deepest field in set, counting set's own
+// fields as one.
+func (d *depths) of(set *ast.SelectionSet) int {
+	if set == nil {
+		return 0
+	}
+	deepest := 0
+	for _, sel := range set.Selections {
+		n := 0
+		switch s := sel.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name.Value, "__") {
+				continue
+			}
+			n = 1 + d.of(s.SelectionSet)
+		case *ast.InlineFragment:
+			n = d.of(s.SelectionSet)
+		case *ast.FragmentSpread:
+			n = d.fragment(s.Name.Value)
+		}
+		deepest = max(deepest, n)
+	}
+	return deepest
+}
+
+func (d *depths) fragment(name string) int {
+	if n, ok := d.memo[name]; ok {
+		if n < 0 && d.cycle == "" {
+			d.cycle = name
+		}
+		return max(n, 0)
+	}
+	f, ok := d.fragments[name]
+	if !ok {
+		return 0
+	}
+	d.memo[name] = -1
+	n := d.of(f.SelectionSet)
+	d.memo[name] = n
+	return n
+}
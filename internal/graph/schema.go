@@ -0,0 +1,208 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/page"
+	"github.com/nicholasraynes/northwind-api/internal/sorting"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// newSchema builds the object types from the models, links them through
+// batched relations and exposes each collection and record at the root.
+func newSchema(st store.Store) (graphql.Schema, error) {
+	ordersOfCustomer := relation[models.Order]{
+		spec: store.OrderFilters, sorts: store.OrderSorts, key: "customer_id", list: st.ListOrders,
+		keyOf: func(o models.Order) string { return o.CustomerID },
+	}
+	orderByID := relation[models.Order]{
+		spec: store.OrderFilters, sorts: store.OrderSorts, key: "order_id", list: st.ListOrders,
+		keyOf: func(o models.Order) string { return strconv.Itoa(o.OrderID) },
+	}
+	detailsOfOrder := relation[models.OrderDetail]{
+		spec: store.OrderDetailFilters, sorts: store.OrderDetailSorts, key: "order_id", list: st.ListOrderDetails,
+		keyOf: func(d models.OrderDetail) string { return strconv.Itoa(d.OrderID) },
+	}
+	customerByID := relation[models.Customer]{
+		spec: store.CustomerFilters, sorts: store.CustomerSorts, key: "customer_id", list: st.ListCustomers,
+		keyOf: func(c models.Customer) string { return c.CustomerID },
+	}
+	productByID := relation[models.Product]{
+		spec: store.ProductFilters, sorts: store.ProductSorts, key: "product_id", list: st.ListProducts,
+		keyOf: func(p models.Product) string { return strconv.Itoa(p.ProductID) },
+	}
+	productsOfSupplier := relation[models.Product]{
+		spec: store.ProductFilters, sorts: store.ProductSorts, key: "supplier_id", list: st.ListProducts,
+		keyOf: func(p models.Product) string { return strconv.Itoa(p.SupplierID) },
+	}
+	supplierByID := relation[models.Supplier]{
+		spec: store.SupplierFilters, sorts: store.SupplierSorts, key: "supplier_id", list: st.ListSuppliers,
+		keyOf: func(s models.Supplier) string { return strconv.Itoa(s.SupplierID) },
+	}
+
+	var customerType, orderType, detailType, productType, supplierType *graphql.Object
+
+	customerType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Customer",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			fields := modelFields[models.Customer]()
+			fields["orders"] = &graphql.Field{
+				Type:        listOf(orderType),
+				Description: "The customer's orders",
+				Args:        ordersOfCustomer.args(),
+				Resolve:     hasMany(ordersOfCustomer, func(c models.Customer) string { return c.CustomerID }),
+			}
+			return fields
+		}),
+	})
+
+	orderType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Order",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			fields := modelFields[models.Order]()
+			fields["customer"] = &graphql.Field{
+				Type:        customerType,
+				Description: "The customer who placed the order; null once deleted",
+				Resolve:     belongsTo(customerByID, func(o models.Order) string { return o.CustomerID }),
+			}
+			fields["details"] = &graphql.Field{
+				Type:        listOf(detailType),
+				Description: "The order's line items",
+				Args:        detailsOfOrder.args(),
+				Resolve:     hasMany(detailsOfOrder, func(o models.Order) string { return strconv.Itoa(o.OrderID) }),
+			}
+			return fields
+		}),
+	})
+
+	detailType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "OrderDetail",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			fields := modelFields[models.OrderDetail]()
+			fields["order"] = &graphql.Field{
+				Type:        orderType,
+				Description: "The order the line item belongs to",
+				Resolve:     belongsTo(orderByID, func(d models.OrderDetail) string { return strconv.Itoa(d.OrderID) }),
+			}
+			fields["product"] = &graphql.Field{
+				Type:        productType,
+				Description: "The product ordered; null once deleted",
+				Resolve:     belongsTo(productByID, func(d models.OrderDetail) string { return strconv.Itoa(d.ProductID) }),
+			}
+			return fields
+		}),
+	})
+
+	productType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Product",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			fields := modelFields[models.Product]()
+			fields["supplier"] = &graphql.Field{
+				Type:        supplierType,
+				Description: "The product's supplier; null once deleted",
+				Resolve:     belongsTo(supplierByID, func(p models.Product) string { return strconv.Itoa(p.SupplierID) }),
+			}
+			return fields
+		}),
+	})
+
+	supplierType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Supplier",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			fields := modelFields[models.Supplier]()
+			fields["products"] = &graphql.Field{
+				Type:        listOf(productType),
+				Description: "The products the supplier provides",
+				Args:        productsOfSupplier.args(),
+				Resolve:     hasMany(productsOfSupplier, func(s models.Supplier) string { return strconv.Itoa(s.SupplierID) }),
+			}
+			return fields
+		}),
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"customers":     collection(customerType, "Customers", store.CustomerFilters, store.CustomerSorts, st.ListCustomers),
+			"customer":      record(customerType, graphql.String, "A customer by ID, e.g. ALFKI", st.GetCustomer),
+			"orders":        collection(orderType, "Orders", store.OrderFilters, store.OrderSorts, st.ListOrders),
+			"order":         record(orderType, graphql.Int, "An order by ID", st.GetOrder),
+			"order_details": collection(detailType, "Order line items", store.OrderDetailFilters, store.OrderDetailSorts, st.ListOrderDetails),
+			"products":      collection(productType, "Products", store.ProductFilters, store.ProductSorts, st.ListProducts),
+			"product":       record(productType, graphql.Int, "A product by ID", st.GetProduct),
+			"suppliers":     collection(supplierType, "Suppliers", store.SupplierFilters, store.SupplierSorts, st.ListSuppliers),
+			"supplier":      record(supplierType, graphql.Int, "A supplier by ID", st.GetSupplier),
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+func listOf(t *graphql.Object) graphql.Output {
+	return graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t)))
+}
+
+// collection is a root field listing one page of T, taking the filters, sort
+// order and paging of the matching REST endpoint.
+func collection[T any](t *graphql.Object, description string, spec filter.Spec, sorts sorting.Spec, list func(ctx context.Context, q store.Query) (store.Rows[T], error)) *graphql.Field {
+	return &graphql.Field{
+		Type:        listOf(t),
+		Description: description,
+		Args:        listArgs(spec, sorts),
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			values, err := argValues(p.Args)
+			if err != nil {
+				return nil, err
+			}
+			f, err := spec.Parse(values)
+			if err != nil {
+				return nil, err
+			}
+			o, err := sorts.Parse(values)
+			if err != nil {
+				return nil, err
+			}
+			pg, err := page.Parse(values)
+			if err != nil {
+				return nil, err
+			}
+
+			rows, err := list(p.Context, store.Query{Filters: f, Sort: o, Page: pg})
+			if err != nil {
+				return nil, storeError(p.Context, err)
+			}
+			res, err := store.Collect(rows)
+			if err != nil {
+				return nil, storeError(p.Context, err)
+			}
+			return res.Rows, nil
+		},
+	}
+}
+
+// record is a root field returning the T with the given id, or null when
+// there is none.
+func record[T any, K comparable](t *graphql.Object, id graphql.Input, description string, get func(ctx context.Context, id K) (T, error)) *graphql.Field {
+	return &graphql.Field{
+		Type:        t,
+		Description: description,
+		Args: graphql.FieldConfigArgument{
+			"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(id)},
+		},
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			v, err := get(p.Context, p.Args["id"].(K))
+			if errors.Is(err, store.ErrNotFound) {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, storeError(p.Context, err)
+			}
+			return v, nil
+		},
+	}
+}
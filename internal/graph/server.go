@@ -0,0 +1,124 @@
+// Package graph serves the Northwind entity graph over GraphQL.
+//
+// The object types are derived from the models: Customer, Order,
+// OrderDetail, Product and Supplier expose every JSON field under its JSON
+// name, and are linked so that a customer's orders, their line items and each
+// product's supplier come back in one request. List fields take the filters
+// and sort order of the matching REST endpoint as arguments.
+//
+// Nested fields are batched. Each resolver queues its parent's key and
+// returns a thunk; the executor runs the thunks of a response level only
+// after every sibling has queued, so the first one loads all of them through
+// a single store call with an In filter.
+//
+// As the types refer to each other, a query is refused when it nests fields
+// more than maxDepth deep, and its nested fields may load at most
+// maxNestedRows records in all.
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/nicholasraynes/northwind-api/internal/logging"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// maxRequestSize caps the body of a POST request.
+const maxRequestSize = 1 << 20
+
+// request is a GraphQL request as sent over HTTP.
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Server answers GraphQL requests against one store.
+type Server struct {
+	schema graphql.Schema
+}
+
+// NewServer builds the schema over st.
+func NewServer(st store.Store) (*Server, error) {
+	schema, err := newSchema(st)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{schema: schema}, nil
+}
+
+// ServeHTTP takes the request from the query string on GET and from a JSON
+// body on POST. Every executed request answers 200, with any errors in the
+// "errors" member alongside the data that did resolve.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if raw := q.Get("variables"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &req.Variables); err != nil {
+				writeError(w, http.StatusBadRequest, "variables must be a JSON object")
+				return
+			}
+		}
+	case http.MethodPost:
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "cannot read request body")
+			return
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if req.Query == "" {
+		writeError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	if err := checkQuery(req.Query); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res := graphql.Do(graphql.Params{
+		Schema:         s.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        withBatches(r.Context()),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(res)
+}
+
+// writeError answers a request that could not be executed, in the shape of a
+// GraphQL response.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"errors": []map[string]string{{"message": message}},
+	})
+}
+
+// storeError describes a failed store call to the client by its
+// store.Classify message, logging the driver's with the request.
+func storeError(ctx context.Context, err error) error {
+	f := store.Classify(ctx, err)
+	logging.Fault(ctx, f, err)
+	return f
+}
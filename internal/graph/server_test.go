@@ -0,0 +1,271 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// fakeStore serves the entity graph from memory and counts the calls made to
+// each list method. List methods honor the In filters the relations join on.
+type fakeStore struct {
+	store.Store
+	customers []models.Customer
+	orders    []models.Order
+	details   []models.OrderDetail
+	products  []models.Product
+	suppliers []models.Supplier
+	calls     map[string]int
+}
+
+// matching returns the rows whose keys match every filter in q that keys
+// knows, counting the call under name.
+func matching[T any](s *fakeStore, name string, rows []T, q store.Query, keys map[string]func(T) string) store.Rows[T] {
+	s.calls[name]++
+	var out []T
+	for _, r := range rows {
+		ok := true
+		for param, want := range q.Filters.Echo() {
+			key, known := keys[param]
+			if !known {
+				continue
+			}
+			values, isList := want.([]any)
+			if !isList {
+				values = []any{want}
+			}
+			ok = ok && slices.ContainsFunc(values, func(v any) bool { return fmt.Sprint(v) == key(r) })
+		}
+		if ok {
+			out = append(out, r)
+		}
+	}
+	total := len(out)
+	out = out[min(q.Page.Offset, len(out)):]
+	out = out[:min(q.Page.Limit, len(out))]
+	return &sliceRows[T]{rows: out, total: total}
+}
+
+func (s *fakeStore) ListCustomers(ctx context.Context, q store.Query) (store.Rows[models.Customer], error) {
+	return matching(s, "customers", s.customers, q, map[string]func(models.Customer) string{
+		"customer_id": func(c models.Customer) string { return c.CustomerID },
+	}), nil
+}
+
+func (s *fakeStore) ListOrders(ctx context.Context, q store.Query) (store.Rows[models.Order], error) {
+	return matching(s, "orders", s.orders, q, map[string]func(models.Order) string{
+		"order_id":    func(o models.Order) string { return strconv.Itoa(o.OrderID) },
+		"customer_id": func(o models.Order) string { return o.CustomerID },
+	}), nil
+}
+
+func (s *fakeStore) ListOrderDetails(ctx context.Context, q store.Query) (store.Rows[models.OrderDetail], error) {
+	return matching(s, "details", s.details, q, map[string]func(models.OrderDetail) string{
+		"order_id": func(d models.OrderDetail) string { return strconv.Itoa(d.OrderID) },
+	}), nil
+}
+
+func (s *fakeStore) ListProducts(ctx context.Context, q store.Query) (store.Rows[models.Product], error) {
+	return matching(s, "products", s.products, q, map[string]func(models.Product) string{
+		"product_id":  func(p models.Product) string { return strconv.Itoa(p.ProductID) },
+		"supplier_id": func(p models.Product) string { return strconv.Itoa(p.SupplierID) },
+	}), nil
+}
+
+func (s *fakeStore) ListSuppliers(ctx context.Context, q store.Query) (store.Rows[models.Supplier], error) {
+	return matching(s, "suppliers", s.suppliers, q, map[string]func(models.Supplier) string{
+		"supplier_id": func(v models.Supplier) string { return strconv.Itoa(v.SupplierID) },
+	}), nil
+}
+
+func (s *fakeStore) GetCustomer(ctx context.Context, id string) (models.Customer, error) {
+	return get(s, "customer", s.customers, func(c models.Customer) bool { return c.CustomerID == id })
+}
+
+func (s *fakeStore) GetOrder(ctx context.Context, id int) (models.Order, error) {
+	return get(s, "order", s.orders, func(o models.Order) bool { return o.OrderID == id })
+}
+
+func (s *fakeStore) GetProduct(ctx context.Context, id int) (models.Product, error) {
+	return get(s, "product", s.products, func(p models.Product) bool { return p.ProductID == id })
+}
+
+func (s *fakeStore) GetSupplier(ctx context.Context, id int) (models.Supplier, error) {
+	return get(s, "supplier", s.suppliers, func(v models.Supplier) bool { return v.SupplierID == id })
+}
+
+func get[T any](s *fakeStore, name string, rows []T, match func(T) bool) (T, error) {
+	s.calls[name]++
+	if i := slices.IndexFunc(rows, match); i >= 0 {
+		return rows[i], nil
+	}
+	var zero T
+	return zero, store.ErrNotFound
+}
+
+type sliceRows[T any] struct {
+	rows  []T
+	total int
+	i     int
+}
+
+func (r *sliceRows[T]) Next() bool {
+	r.i++
+	return r.i <= len(r.rows)
+}
+func (r *sliceRows[T]) Row() T       { return r.rows[r.i-1] }
+func (r *sliceRows[T]) Total() int   { return r.total }
+func (r *sliceRows[T]) Err() error   { return nil }
+func (r *sliceRows[T]) Close() error { return nil }
+
+// northwind is a small graph: three customers with two orders each, every
+// order holding two of three products from two suppliers.
+func northwind() *fakeStore {
+	s := &fakeStore{calls: map[string]int{}}
+	for _, id := range []string{"ALFKI", "ANATR", "AROUT"} {
+		s.customers = append(s.customers, models.Customer{CustomerID: id, CompanyName: "Company " + id})
+	}
+	for i := range 6 {
+		id := 10248 + i
+		s.orders = append(s.orders, models.Order{OrderID: id, CustomerID: s.customers[i/2].CustomerID})
+		s.details = append(s.details,
+			models.OrderDetail{OrderID: id, ProductID: 1 + i%3},
+			models.OrderDetail{OrderID: id, ProductID: 1 + (i+1)%3})
+	}
+	for id := 1; id <= 3; id++ {
+		s.products = append(s.products, models.Product{ProductID: id, ProductName: "Product " + strconv.Itoa(id), SupplierID: 1 + id%2})
+	}
+	s.suppliers = []models.Supplier{{SupplierID: 1, CompanyName: "Exotic Liquids"}, {SupplierID: 2, CompanyName: "New Orleans Cajun Delights"}}
+	return s
+}
+
+// do runs query against a server over st and returns the status and the
+// decoded response.
+func do(t *testing.T, st store.Store, query string) (int, map[string]any) {
+	t.Helper()
+	s, err := NewServer(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(query), nil))
+	var res map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("%s: %v", w.Body, err)
+	}
+	return w.Code, res
+}
+
+// TestNestedFieldsBatch fails when a nested field calls the store once per
+// parent instead of once per level.
+func TestNestedFieldsBatch(t *testing.T) {
+	st := northwind()
+	code, res := do(t, st, `{
+		customers {
+			customer_id
+			orders { order_id details { product_id product { product_name supplier { company_name } } } }
+		}
+	}`)
+	if code != http.StatusOK || res["errors"] != nil {
+		t.Fatalf("status %d: %v", code, res["errors"])
+	}
+
+	want := map[string]int{"customers": 1, "orders": 1, "details": 1, "products": 1, "suppliers": 1}
+	for name, n := range want {
+		if st.calls[name] != n {
+			t.Errorf("%d calls to list %s, want %d", st.calls[name], name, n)
+		}
+	}
+
+	customers := res["data"].(map[string]any)["customers"].([]any)
+	if len(customers) != 3 {
+		t.Fatalf("%d customers, want 3", len(customers))
+	}
+	for _, c := range customers {
+		c := c.(map[string]any)
+		orders := c["orders"].([]any)
+		if len(orders) != 2 {
+			t.Errorf("customer %v has %d orders, want 2", c["customer_id"], len(orders))
+		}
+		for _, o := range orders {
+			for _, d := range o.(map[string]any)["details"].([]any) {
+				d := d.(map[string]any)
+				p := d["product"].(map[string]any)
+				if p["product_name"] != fmt.Sprintf("Product %v", d["product_id"]) || p["supplier"] == nil {
+					t.Errorf("line item %v resolved to %v", d, p)
+				}
+			}
+		}
+	}
+}
+
+func TestDepthLimit(t *testing.T) {
+	deep := "{ customers { orders { customer { orders { customer { orders { customer { orders { order_id } } } } } } } } }"
+	viaFragments := `
+		query { customers { ...C } }
+		fragment C on Customer { orders { ...O } }
+		fragment O on Order { customer { orders { customer { orders { customer { orders { order_id } } } } } } }`
+	for _, q := range []string{deep, viaFragments} {
+		st := northwind()
+		code, res := do(t, st, q)
+		if code != http.StatusBadRequest || !strings.Contains(fmt.Sprint(res["errors"]), "at most 8") {
+			t.Errorf("status %d, errors %v, want 400 naming the limit", code, res["errors"])
+		}
+		if len(st.calls) > 0 {
+			t.Errorf("store called %v for a refused query", st.calls)
+		}
+	}
+
+	// Cyclic fragments would overflow the validator's stack.
+	for _, q := range []string{
+		"query { customers { ...A } } fragment A on Customer { ...B } fragment B on Customer { ...A }",
+		"query { customers { customer_id } } fragment A on Customer { orders { customer { ...A } } }",
+	} {
+		if code, res := do(t, northwind(), q); code != http.StatusBadRequest || !strings.Contains(fmt.Sprint(res["errors"]), "spreads itself") {
+			t.Errorf("status %d, errors %v, want 400 for a fragment cycle", code, res["errors"])
+		}
+	}
+
+	// Eight levels and introspection are left alone.
+	ok := []string{
+		"{ customers { orders { customer { orders { customer { orders { customer { customer_id } } } } } } } }",
+		"query { customers { ...A } } fragment A on Customer { ...B } fragment B on Customer { customer_id }",
+		"{ __schema { types { fields { type { ofType { ofType { ofType { ofType { ofType { name } } } } } } } } } }",
+	}
+	for _, q := range ok {
+		if code, res := do(t, northwind(), q); code != http.StatusOK {
+			t.Errorf("status %d for %s: %v", code, q, res["errors"])
+		}
+	}
+}
+
+func TestNestedRowLimit(t *testing.T) {
+	st := northwind()
+	for i := range maxNestedRows {
+		st.details = append(st.details, models.OrderDetail{OrderID: 10248, ProductID: 100 + i})
+	}
+
+	code, res := do(t, st, `{ orders { order_id details { product_id } } }`)
+	if code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if !strings.Contains(fmt.Sprint(res["errors"]), "narrow them with filters") {
+		t.Errorf("errors = %v, want the nested row limit", res["errors"])
+	}
+
+	// Filters bring the same field back under the limit.
+	code, res = do(t, st, `{ orders(order_id: "10249") { details { product_id } } }`)
+	if code != http.StatusOK || res["errors"] != nil {
+		t.Errorf("status %d: %v", code, res["errors"])
+	}
+}
@@ -0,0 +1,129 @@
+package graph
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/nicholasraynes/northwind-api/internal/fieldset"
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/page"
+	"github.com/nicholasraynes/northwind-api/internal/sorting"
+)
+
+// modelFields lists a field for every JSON field of model T, under its JSON
+// name. Pointer fields are nullable; the rest are not.
+func modelFields[T any]() graphql.Fields {
+	types := map[string]reflect.Type{}
+	for _, f := range reflect.VisibleFields(reflect.TypeFor[T]()) {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		types[name] = f.Type
+	}
+
+	fields := graphql.Fields{}
+	for _, name := range fieldset.Of[T]() {
+		get := fieldset.Getter[T]([]string{name})
+		fields[name] = &graphql.Field{
+			Type: outputType(types[name]),
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				row := p.Source.(T)
+				return get(&row)[0], nil
+			},
+		}
+	}
+	return fields
+}
+
+// outputType maps a model field type to its GraphQL type. Models only use
+// the kinds below, so anything else is a programming error.
+func outputType(t reflect.Type) graphql.Output {
+	nullable := t.Kind() == reflect.Pointer
+	if nullable {
+		t = t.Elem()
+	}
+
+	var out graphql.Output
+	switch t.Kind() {
+	case reflect.String:
+		out = graphql.String
+	case reflect.Bool:
+		out = graphql.Boolean
+	case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64:
+		out = graphql.Int
+	case reflect.Float32, reflect.Float64:
+		out = graphql.Float
+	default:
+		if t != reflect.TypeFor[time.Time]() {
+			panic(fmt.Sprintf("graph: no GraphQL type for %s", t))
+		}
+		out = graphql.DateTime
+	}
+
+	if nullable {
+		return out
+	}
+	return graphql.NewNonNull(out)
+}
+
+// argTypes maps filter parameter types to GraphQL input types.
+var argTypes = map[string]graphql.Input{
+	"string":  graphql.String,
+	"integer": graphql.Int,
+	"number":  graphql.Float,
+	"boolean": graphql.Boolean,
+}
+
+// filterArgs lists an argument for every query parameter of spec, so a field
+// takes the filters of the matching REST endpoint under the same names.
+func filterArgs(spec filter.Spec) graphql.FieldConfigArgument {
+	args := graphql.FieldConfigArgument{}
+	for _, p := range spec.Params() {
+		args[p.Name] = &graphql.ArgumentConfig{Type: argTypes[p.Type], Description: p.Description}
+	}
+	return args
+}
+
+func sortArg(sorts sorting.Spec) *graphql.ArgumentConfig {
+	return &graphql.ArgumentConfig{
+		Type:        graphql.String,
+		Description: "Comma-separated fields to sort by; prefix a field with - for descending order. One of: " + strings.Join(sorts, ", "),
+	}
+}
+
+// listArgs lists the arguments of a top-level collection: its filters, a sort
+// order and a page.
+func listArgs(spec filter.Spec, sorts sorting.Spec) graphql.FieldConfigArgument {
+	args := filterArgs(spec)
+	args[sorting.Param] = sortArg(sorts)
+	args[page.LimitParam] = &graphql.ArgumentConfig{
+		Type:        graphql.Int,
+		Description: fmt.Sprintf("Maximum number of rows to return (default %d, max %d)", page.DefaultLimit, page.MaxLimit),
+	}
+	args[page.OffsetParam] = &graphql.ArgumentConfig{Type: graphql.Int, Description: "Number of rows to skip"}
+	return args
+}
+
+// argValues renders resolved arguments as the query parameters the REST
+// endpoints would take, so they go through the same parsing.
+func argValues(args map[string]any) (url.Values, error) {
+	values := url.Values{}
+	for name, v := range args {
+		switch v := v.(type) {
+		case string:
+			values.Set(name, v)
+		case int:
+			values.Set(name, strconv.Itoa(v))
+		case float64:
+			values.Set(name, strconv.FormatFloat(v, 'f', -1, 64))
+		case bool:
+			values.Set(name, strconv.FormatBool(v))
+		default:
+			return nil, fmt.Errorf("argument %s has unsupported value %v", name, v)
+		}
+	}
+	return values, nil
+}
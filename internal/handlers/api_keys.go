@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nicholasraynes/northwind-api/internal/auth"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// GET /admin/keys
+// Optional parameters: key_id, name, prefix
+func (h *Handler) GetAPIKeys(c *gin.Context) {
+	q, ok := parseQuery(c, store.APIKeyFilters, store.APIKeySorts, store.APIKeyFields)
+	if !ok {
+		return
+	}
+
+	keys, err := h.store.ListAPIKeys(c.Request.Context(), q.Query)
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	respond(c, q, keys)
+}
+
+// POST /admin/keys
+// Body: models.APIKeyInput. The key is in the response and nowhere else.
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	var in models.APIKeyInput
+	if !decodeBody(c, &in) {
+		return
+	}
+	if err := validateKey(in); err != nil {
+		writeError(c, "API key", err)
+		return
+	}
+
+	key, prefix, hash, err := auth.NewKey()
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+	k, err := h.store.CreateAPIKey(c.Request.Context(), models.APIKey{Name: in.Name, Prefix: prefix, Scopes: in.Scopes, RowScope: in.RowScope}, hash)
+	if err != nil {
+		writeError(c, "API key", err)
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("/admin/keys/%d", k.KeyID))
+	c.JSON(http.StatusCreated, gin.H{"data": k, "key": key})
+}
+
+// POST /admin/keys/:id/rotate
+// Replaces the key, which stops working at once, keeping its name and scopes.
+func (h *Handler) RotateAPIKey(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	key, prefix, hash, err := auth.NewKey()
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+	k, err := h.store.RotateAPIKey(c.Request.Context(), id, prefix, hash)
+	if err != nil {
+		writeError(c, "API key", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": k, "key": key})
+}
+
+// DELETE /admin/keys/:id
+// The key stops working but stays listed, with its revoked_at.
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.store.RevokeAPIKey(c.Request.Context(), id); err != nil {
+		writeError(c, "API key", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// validateKey checks the binding rules of in and that it names only known
+// scopes and row scope dimensions, reporting every failure at once.
+func validateKey(in models.APIKeyInput) error {
+	scopes := invalidFields{}
+	for _, s := range in.Scopes {
+		if !slices.Contains(auth.Scopes, auth.Scope(s)) {
+			scopes.add("scopes", "has unknown scope %q; known scopes are %s", s, auth.Scopes)
+		}
+	}
+	return joinFields(validateBody(in), store.CheckRowScope(in.RowScope), scopes.err())
+}
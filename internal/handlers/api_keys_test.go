@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/nicholasraynes/northwind-api/internal/models"
+)
+
+// TestValidateKeyListsEveryField fails when key validation reports its
+// binding, row scope and scope failures one at a time.
+func TestValidateKeyListsEveryField(t *testing.T) {
+	in := models.APIKeyInput{
+		Scopes:   []string{"read:core", "write:everything"},
+		RowScope: models.RowScope{"region": "WA"},
+	}
+	got, ok := fieldErrors(validateKey(in))
+	if !ok {
+		t.Fatalf("got %v, want invalid fields", validateKey(in))
+	}
+	var fields []string
+	for _, fe := range got {
+		fields = append(fields, fe.Field)
+	}
+	if want := []string{"name", "row_scope", "scopes"}; !slices.Equal(fields, want) {
+		t.Errorf("invalid fields = %v, want %v", fields, want)
+	}
+}
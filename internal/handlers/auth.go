@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nicholasraynes/northwind-api/internal/auth"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// PrincipalKey is the gin.Context key holding the caller's *auth.Principal.
+const PrincipalKey = "principal"
+
+// Authenticate identifies the caller with the first of auths to recognise a
+// credential on the request, such as an API key or a bearer token, and
+// records the principal on both the gin and the request context. A
+// principal's row scope goes on the request context too, where the store
+// applies it to every query. A request without credentials passes through
+// anonymous, for Require to turn away; one with bad credentials is answered
+// 401. A request made in process that already carries a principal, such as an
+// MCP tool call, keeps it.
+func Authenticate(auths ...auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, _ := auth.FromContext(c.Request.Context())
+		for _, a := range auths {
+			if p != nil {
+				break
+			}
+			var err error
+			if p, err = a.Authenticate(c.Request); err != nil {
+				if errors.Is(err, auth.ErrInvalidCredentials) {
+					fail(c, http.StatusUnauthorized, codeUnauthorized, err.Error())
+				} else {
+					storeError(c, err)
+				}
+				c.Abort()
+				return
+			}
+			if p != nil {
+				ctx := auth.WithPrincipal(c.Request.Context(), p)
+				if p.Confined() {
+					ctx = store.WithRowScope(ctx, p.Rows)
+				}
+				c.Request = c.Request.WithContext(ctx)
+			}
+		}
+		if p != nil {
+			c.Set(PrincipalKey, p)
+		}
+		c.Next()
+	}
+}
+
+// Require answers 401 to anonymous callers and 403 to callers without every
+// one of scopes. With no scopes, any authenticated caller passes.
+func Require(scopes ...auth.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := Principal(c)
+		if !ok {
+			fail(c, http.StatusUnauthorized, codeUnauthorized, "authentication required: send an API key in the "+auth.KeyHeader+" header or an OAuth bearer token")
+			c.Abort()
+			return
+		}
+		for _, s := range scopes {
+			if !p.Allows(s) {
+				message := "this operation requires the " + string(s) + " scope"
+				if p.Confined() && !s.Read() {
+					message = "callers confined to one employee or customer can only read"
+				}
+				fail(c, http.StatusForbidden, codeForbidden, message)
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// Principal returns the caller Authenticate identified, if any.
+func Principal(c *gin.Context) (*auth.Principal, bool) {
+	p, ok := c.Get(PrincipalKey)
+	if !ok {
+		return nil, false
+	}
+	principal, ok := p.(*auth.Principal)
+	return principal, ok
+}
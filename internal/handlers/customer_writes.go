@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+)
+
+func (h *Handler) customerRecord() record[models.Customer, string] {
+	return record[models.Customer, string]{
+		what:     "customer",
+		path:     "/customers",
+		key:      "customer_id",
+		assigned: false,
+		id:       func(v *models.Customer) *string { return &v.CustomerID },
+		parse:    customerKey,
+		get:      h.store.GetCustomer,
+		create:   h.store.CreateCustomer,
+		update:   h.store.UpdateCustomer,
+		remove:   h.store.DeleteCustomer,
+	}
+}
+
+// GET /customers/:id
+func (h *Handler) GetCustomer(c *gin.Context) {
+	getRecord(c, h.customerRecord())
+}
+
+// POST /customers
+// Body: models.Customer; customer_id is chosen by the client.
+func (h *Handler) CreateCustomer(c *gin.Context) {
+	createRecord(c, h.customerRecord())
+}
+
+// PUT /customers/:id
+// Body: models.Customer; every field is overwritten. Requires If-Match.
+func (h *Handler) ReplaceCustomer(c *gin.Context) {
+	replaceRecord(c, h.customerRecord())
+}
+
+// PATCH /customers/:id
+// Body: the fields of models.Customer to change. Requires If-Match.
+func (h *Handler) PatchCustomer(c *gin.Context) {
+	patchRecord(c, h.customerRecord())
+}
+
+// DELETE /customers/:id
+// Requires If-Match. The customer is hidden from every endpoint but kept in the
+// database.
+func (h *Handler) DeleteCustomer(c *gin.Context) {
+	deleteRecord(c, h.customerRecord())
+}
+
+// customerKey reads the customer id from the path. Customer ids are short
+// codes such as ALFKI rather than numbers.
+func customerKey(c *gin.Context) (string, bool) {
+	return c.Param("id"), true
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// GET /analytics/delivery-times?group_by=employee&year=1997
+// Optional parameters: group_by (shipper, employee or both), year, order_date, shipper_id, shipper_name, employee_id, employee_name, ship_country
+func (h *Handler) GetDeliveryTimes(c *gin.Context) {
+	by, ok := choice(c, "group_by", store.DeliveryGroupings)
+	if !ok {
+		return
+	}
+	q, ok := parseQuery(c, store.DeliveryTimesFilters, store.DeliveryTimesSorts, store.DeliveryTimesFields)
+	if !ok {
+		return
+	}
+
+	results, err := h.store.DeliveryTimes(c.Request.Context(), q.Query, store.DeliveryGrouping(by))
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	filters := echo(q.Query)
+	filters["group_by"] = by
+	respondWithFilters(c, q, results, filters)
+}
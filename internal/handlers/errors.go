@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nicholasraynes/northwind-api/internal/export"
+	"github.com/nicholasraynes/northwind-api/internal/fieldset"
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/logging"
+	"github.com/nicholasraynes/northwind-api/internal/page"
+	"github.com/nicholasraynes/northwind-api/internal/sorting"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// Codes of the errors the handlers raise themselves. Failures the store
+// cannot place add the store.Code* codes.
+const (
+	codeInvalidRequest       = "invalid_request"
+	codeMalformedBody        = "malformed_body"
+	codeNotFound             = "not_found"
+	codeAlreadyExists        = "already_exists"
+	codeVersionMismatch      = "version_mismatch"
+	codePreconditionRequired = "precondition_required"
+	codeInsufficientStock    = "insufficient_stock"
+	codeUnauthorized         = "unauthorized"
+	codeForbidden            = "forbidden"
+)
+
+// ErrorBody is the body of every error response. Code is stable, so
+// clients can act on it; Error is meant for people and may change.
+type ErrorBody struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+	// Fields lists every invalid query parameter or body field of an
+	// invalid_request.
+	Fields []filter.FieldError `json:"fields,omitempty"`
+	// StockError names the product an insufficient_stock is short of.
+	*store.StockError
+}
+
+// invalidFields reports several invalid fields at once.
+type invalidFields []filter.FieldError
+
+func (e invalidFields) Error() string {
+	return describeFields(e)
+}
+
+// add records field as invalid.
+func (e *invalidFields) add(field, format string, args ...any) {
+	*e = append(*e, filter.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// err returns e, or nil when it lists no field.
+func (e invalidFields) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// joinFields merges the fields that errs report invalid into one
+// invalidFields, so a client hears of every bad field at once. An error that
+// is not a validation error is returned as it is.
+func joinFields(errs ...error) error {
+	all := invalidFields{}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		fields, ok := fieldErrors(err)
+		if !ok {
+			return err
+		}
+		all = append(all, fields...)
+	}
+	return all.err()
+}
+
+func describeFields(fields []filter.FieldError) string {
+	msgs := make([]string, len(fields))
+	for i, fe := range fields {
+		msgs[i] = fe.Field + " " + fe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// fieldErrors lists the fields err reports invalid, if it is a validation
+// error from a parameter parser or from body validation.
+func fieldErrors(err error) ([]filter.FieldError, bool) {
+	var (
+		filters   filter.Errors
+		fields    invalidFields
+		invalid   *store.InvalidError
+		compare   *store.CompareError
+		sortErr   *sorting.Error
+		fieldsErr *fieldset.Error
+		pageErr   *page.Error
+		formatErr *export.Error
+	)
+	switch {
+	case errors.As(err, &filters):
+		return filters, true
+	case errors.As(err, &fields):
+		return fields, true
+	case errors.As(err, &invalid):
+		return []filter.FieldError{{Field: invalid.Field, Message: invalid.Message}}, true
+	case errors.As(err, &compare):
+		return []filter.FieldError{{Field: store.CompareParam, Message: compare.Message}}, true
+	case errors.As(err, &sortErr):
+		return []filter.FieldError{{Field: sorting.Param, Message: sortErr.Message}}, true
+	case errors.As(err, &fieldsErr):
+		return []filter.FieldError{{Field: fieldset.Param, Message: fieldsErr.Message}}, true
+	case errors.As(err, &pageErr):
+		return []filter.FieldError{{Field: pageErr.Param, Message: pageErr.Message}}, true
+	case errors.As(err, &formatErr):
+		return []filter.FieldError{{Field: export.Param, Message: formatErr.Message()}}, true
+	}
+	return nil, false
+}
+
+// badRequest answers 400 invalid_request, listing the invalid fields.
+func badRequest(c *gin.Context, fields ...filter.FieldError) {
+	c.JSON(http.StatusBadRequest, ErrorBody{
+		Error:  describeFields(fields),
+		Code:   codeInvalidRequest,
+		Fields: fields,
+	})
+}
+
+// fail answers with status and an error body without fields.
+func fail(c *gin.Context, status int, code, message string) {
+	c.JSON(status, ErrorBody{Error: message, Code: code})
+}
+
+// storeError answers a failed store call. Validation errors become a 400;
+// anything else is classified by store.Classify and logged with the request,
+// so the driver's message never reaches the client.
+func storeError(c *gin.Context, err error) {
+	if fields, ok := fieldErrors(err); ok {
+		badRequest(c, fields...)
+		return
+	}
+	f := store.Classify(c.Request.Context(), err)
+	if d := c.GetDuration(timeoutKey); f.Code == store.CodeTimeout && d > 0 {
+		f.Message = fmt.Sprintf("the query did not finish within %s and was canceled; narrow the filters or try again later", d)
+	}
+	logging.Fault(c.Request.Context(), f, err)
+	fail(c, f.Status, f.Code, f.Message)
+}
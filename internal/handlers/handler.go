@@ -0,0 +1,12 @@
+package handlers
+
+import "github.com/nicholasraynes/northwind-api/internal/store"
+
+// Handler serves the REST endpoints from whichever store it is given.
+type Handler struct {
+	store store.Store
+}
+
+func New(s store.Store) *Handler {
+	return &Handler{store: s}
+}
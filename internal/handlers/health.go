@@ -1,15 +1,177 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/nicholasraynes/northwind-api/internal/logging"
+	"github.com/nicholasraynes/northwind-api/internal/store"
 )
 
-func Health(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"filters": gin.H{},
-		"status":  "ok",
-		"message": "Northwind MCP server is running!",
-	})
+// Version names the build in readiness responses. Release builds set it
+// with -ldflags "-X github.com/nicholasraynes/northwind-api/internal/handlers.Version=v1.2.0".
+var Version = "dev"
+
+// readyTimeout bounds the database checks of one readiness probe, so a
+// database that hangs fails the probe instead of stalling it.
+const readyTimeout = 2 * time.Second
+
+// Statuses of a readiness check.
+const (
+	checkOK        = "ok"
+	checkFailed    = "failed"
+	checkSkipped   = "skipped"
+	checkSaturated = "saturated"
+)
+
+// check is the outcome of one readiness check.
+type check struct {
+	Status    string  `json:"status"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+// tablesCheck adds the tables found missing.
+type tablesCheck struct {
+	check
+	Missing []string `json:"missing,omitempty"`
+}
+
+// poolCheck describes the connection pool. It is saturated when every
+// connection it may open is in use, so new queries wait for one; that is
+// reported but does not fail readiness, as a busy instance can still serve.
+type poolCheck struct {
+	Status string `json:"status"`
+	Open   int    `json:"open"`
+	InUse  int    `json:"in_use"`
+	Idle   int    `json:"idle"`
+	// MaxOpen is zero when the pool is unbounded.
+	MaxOpen int `json:"max_open"`
+	// Saturation is InUse over MaxOpen, or zero when the pool is unbounded.
+	Saturation float64 `json:"saturation"`
+	WaitCount  int64   `json:"wait_count"`
+	WaitMS     float64 `json:"wait_ms"`
+}
+
+// readyChecks are the checks behind a readiness response.
+type readyChecks struct {
+	Database check       `json:"database"`
+	Tables   tablesCheck `json:"tables"`
+	Pool     poolCheck   `json:"pool"`
+}
+
+// buildInfo identifies the running build.
+type buildInfo struct {
+	Version  string `json:"version"`
+	Revision string `json:"revision,omitempty"`
+	Time     string `json:"time,omitempty"`
+	Modified bool   `json:"modified,omitempty"`
+	Go       string `json:"go"`
+}
+
+// build reads the VCS details the Go toolchain stamped into the binary.
+var build = sync.OnceValue(func() buildInfo {
+	b := buildInfo{Version: Version}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+	b.Go = info.GoVersion
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			b.Revision = s.Value
+		case "vcs.time":
+			b.Time = s.Value
+		case "vcs.modified":
+			b.Modified = s.Value == "true"
+		}
+	}
+	return b
+})
+
+// Live answers 200 while the process can serve HTTP at all. It touches
+// nothing else, so an orchestrator restarts the process only when the
+// process itself is stuck.
+func (h *Handler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, liveBody{Status: checkOK})
+}
+
+// Ready answers whether the instance should receive traffic: whether the
+// database answers within readyTimeout and holds the tables the API reads.
+// It answers 503 with the outcome of each check when either fails.
+func (h *Handler) Ready(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyTimeout)
+	defer cancel()
+
+	var checks readyChecks
+	checks.Database = h.checkDatabase(ctx)
+	if checks.Database.Status == checkOK {
+		checks.Tables = h.checkTables(ctx)
+	} else {
+		checks.Tables.Status = checkSkipped
+	}
+	checks.Pool = h.checkPool()
+
+	status, body := http.StatusOK, readyBody{Status: checkOK, Checks: checks, Build: build()}
+	if checks.Database.Status != checkOK || checks.Tables.Status != checkOK {
+		status, body.Status = http.StatusServiceUnavailable, "unavailable"
+	}
+	c.JSON(status, body)
+}
+
+func (h *Handler) checkDatabase(ctx context.Context) check {
+	start := time.Now()
+	return outcome(ctx, start, h.store.Ping(ctx), "the database cannot be reached")
+}
+
+func (h *Handler) checkTables(ctx context.Context) tablesCheck {
+	start := time.Now()
+	missing, err := h.store.MissingTables(ctx)
+	res := tablesCheck{check: outcome(ctx, start, err, "the tables could not be listed"), Missing: missing}
+	if err == nil && len(missing) > 0 {
+		res.Status, res.Error = checkFailed, "the database lacks tables the API reads"
+	}
+	return res
+}
+
+func (h *Handler) checkPool() poolCheck {
+	s := h.store.PoolStats()
+	p := poolCheck{
+		Status:    checkOK,
+		Open:      s.OpenConnections,
+		InUse:     s.InUse,
+		Idle:      s.Idle,
+		MaxOpen:   s.MaxOpenConnections,
+		WaitCount: s.WaitCount,
+		WaitMS:    float64(s.WaitDuration.Microseconds()) / 1000,
+	}
+	if p.MaxOpen > 0 {
+		p.Saturation = float64(p.InUse) / float64(p.MaxOpen)
+		if p.InUse >= p.MaxOpen {
+			p.Status = checkSaturated
+		}
+	}
+	return p
+}
+
+// outcome reports a database check that started at start and returned err,
+// describing err as store.Classify would to a client, or as unplaced when
+// Classify cannot place it, and logging it.
+func outcome(ctx context.Context, start time.Time, err error, unplaced string) check {
+	res := check{Status: checkOK, LatencyMS: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		f := store.Classify(ctx, err)
+		logging.Fault(ctx, f, err)
+		res.Status, res.Error = checkFailed, f.Message
+		if f.Code == store.CodeInternal {
+			res.Error = unplaced
+		}
+	}
+	return res
 }
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+)
+
+// POST /orders/:id/details
+// Body: array of models.OrderDetailInput for products not yet on the order.
+func (h *Handler) AddOrderDetails(c *gin.Context) {
+	h.writeOrderDetails(c, http.StatusCreated, true, h.store.AddOrderDetails)
+}
+
+// PUT /orders/:id/details
+// Body: array of models.OrderDetailInput; the order's complete line items.
+func (h *Handler) ReplaceOrderDetails(c *gin.Context) {
+	h.writeOrderDetails(c, http.StatusOK, true, h.store.ReplaceOrderDetails)
+}
+
+// PATCH /orders/:id/details
+// Body: array of models.OrderDetailInput for products already on the order;
+// omitted fields are unchanged.
+func (h *Handler) PatchOrderDetails(c *gin.Context) {
+	h.writeOrderDetails(c, http.StatusOK, false, h.store.PatchOrderDetails)
+}
+
+// writeLines is the shape shared by the store's line item writes.
+type writeLines func(ctx context.Context, id int, lines []models.OrderDetailInput) ([]models.OrderDetail, error)
+
+// writeOrderDetails decodes and validates a list of line items and hands it to
+// write. With full set, every line must carry a quantity.
+func (h *Handler) writeOrderDetails(c *gin.Context, status int, full bool, write writeLines) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var lines []models.OrderDetailInput
+	if !decodeBody(c, &lines) {
+		return
+	}
+	if len(lines) == 0 {
+		writeError(c, "order", invalid("details", "must list at least one line item"))
+		return
+	}
+	if err := validateLines(lines, full); err != nil {
+		writeError(c, "order", err)
+		return
+	}
+
+	details, err := write(c.Request.Context(), id, lines)
+	if err != nil {
+		writeError(c, "order", err)
+		return
+	}
+
+	c.JSON(status, gin.H{"count": len(details), "data": details})
+}
+
+// DELETE /orders/:id/details?product_id=11,42
+// Optional parameters: product_id (omit to remove every line item)
+func (h *Handler) DeleteOrderDetails(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var productIDs []int
+	if raw := strings.TrimSpace(c.Query("product_id")); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || n < 1 {
+				writeError(c, "order", invalid("product_id", "must be a comma-separated list of product IDs"))
+				return
+			}
+			productIDs = append(productIDs, n)
+		}
+	}
+
+	details, err := h.store.DeleteOrderDetails(c.Request.Context(), id, productIDs)
+	if err != nil {
+		writeError(c, "order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"count": len(details), "data": details})
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+)
+
+// GET /orders/:id
+func (h *Handler) GetOrder(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	order, err := h.store.GetOrder(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, "order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": order})
+}
+
+// POST /orders
+// Body: models.OrderInput; customer_id, employee_id and ship_via are required
+// and every line item needs a product_id and quantity.
+func (h *Handler) CreateOrder(c *gin.Context) {
+	var in models.OrderInput
+	if !decodeBody(c, &in) {
+		return
+	}
+	if err := joinFields(validateOrder(in, true), validateLines(in.Details, true)); err != nil {
+		writeError(c, "order", err)
+		return
+	}
+
+	order, details, err := h.store.CreateOrder(c.Request.Context(), in)
+	if err != nil {
+		writeError(c, "order", err)
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("/orders/%d", order.OrderID))
+	c.JSON(http.StatusCreated, gin.H{"data": order, "details": details})
+}
+
+// PUT /orders/:id
+// Body: models.OrderInput without details; omitted optional fields are cleared.
+func (h *Handler) ReplaceOrder(c *gin.Context) {
+	h.updateOrder(c, false)
+}
+
+// PATCH /orders/:id
+// Body: models.OrderInput without details; omitted fields are unchanged.
+func (h *Handler) PatchOrder(c *gin.Context) {
+	h.updateOrder(c, true)
+}
+
+func (h *Handler) updateOrder(c *gin.Context, partial bool) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var in models.OrderInput
+	if !decodeBody(c, &in) {
+		return
+	}
+	if in.Details != nil {
+		writeError(c, "order", invalid("details", "cannot be changed here; use /orders/%d/details", id))
+		return
+	}
+	if err := validateOrder(in, !partial); err != nil {
+		writeError(c, "order", err)
+		return
+	}
+
+	update := h.store.ReplaceOrder
+	if partial {
+		update = h.store.PatchOrder
+	}
+	order, err := update(c.Request.Context(), id, in)
+	if err != nil {
+		writeError(c, "order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": order})
+}
+
+// DELETE /orders/:id
+func (h *Handler) DeleteOrder(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.store.DeleteOrder(c.Request.Context(), id); err != nil {
+		writeError(c, "order", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// validateOrder checks the fields of in that need no database lookup. With
+// full set, the references an order cannot exist without are required.
+func validateOrder(in models.OrderInput, full bool) error {
+	invalid := invalidFields{}
+	if full {
+		if in.CustomerID == nil {
+			invalid.add("customer_id", "is required")
+		}
+		if in.EmployeeID == nil {
+			invalid.add("employee_id", "is required")
+		}
+		if in.ShipVia == nil {
+			invalid.add("ship_via", "is required")
+		}
+	}
+
+	dates := []struct {
+		field string
+		value *string
+	}{
+		{"order_date", in.OrderDate},
+		{"required_date", in.RequiredDate},
+		{"shipped_date", in.ShippedDate},
+	}
+	for _, d := range dates {
+		if d.value == nil {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, *d.value); err != nil {
+			invalid.add(d.field, "must be a date (YYYY-MM-DD)")
+		}
+	}
+
+	if in.Freight != nil && *in.Freight < 0 {
+		invalid.add("freight", "must not be negative")
+	}
+	return invalid.err()
+}
+
+// validateLines checks line items before they reach the store. With full
+// set, every line must carry a quantity.
+func validateLines(lines []models.OrderDetailInput, full bool) error {
+	invalid := invalidFields{}
+	seen := map[int]bool{}
+	for i, l := range lines {
+		field := func(name string) string { return fmt.Sprintf("details[%d].%s", i, name) }
+
+		switch {
+		case l.ProductID < 1:
+			invalid.add(field("product_id"), "is required")
+		case seen[l.ProductID]:
+			invalid.add(field("product_id"), "appears more than once")
+		}
+		seen[l.ProductID] = true
+
+		switch {
+		case full && l.Quantity == nil:
+			invalid.add(field("quantity"), "is required")
+		case l.Quantity != nil && *l.Quantity < 1:
+			invalid.add(field("quantity"), "must be at least 1")
+		}
+		if l.UnitPrice != nil && *l.UnitPrice < 0 {
+			invalid.add(field("unit_price"), "must not be negative")
+		}
+		if l.Discount != nil && (*l.Discount < 0 || *l.Discount > 1) {
+			invalid.add(field("discount"), "must be between 0 and 1")
+		}
+	}
+	return invalid.err()
+}
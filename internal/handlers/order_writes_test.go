@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/nicholasraynes/northwind-api/internal/models"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+// TestValidateOrderListsEveryField fails when order validation stops at the
+// first bad field instead of reporting them all.
+func TestValidateOrderListsEveryField(t *testing.T) {
+	in := models.OrderInput{
+		OrderDate: ptr("1997-13-01"),
+		Freight:   ptr(-1.0),
+		Details: []models.OrderDetailInput{
+			{ProductID: 11, Quantity: ptr(0)},
+			{ProductID: 11, Quantity: ptr(2), Discount: ptr(1.5)},
+			{Quantity: ptr(1), UnitPrice: ptr(-3.0)},
+		},
+	}
+	err := joinFields(validateOrder(in, true), validateLines(in.Details, true))
+	got, ok := fieldErrors(err)
+	if !ok {
+		t.Fatalf("got %v, want invalid fields", err)
+	}
+	want := []string{
+		"customer_id", "employee_id", "ship_via", "order_date", "freight",
+		"details[0].quantity",
+		"details[1].product_id", "details[1].discount",
+		"details[2].product_id", "details[2].unit_price",
+	}
+	var fields []string
+	for _, fe := range got {
+		fields = append(fields, fe.Field)
+	}
+	if !slices.Equal(fields, want) {
+		t.Errorf("invalid fields = %v, want %v", fields, want)
+	}
+}
+
+func TestValidateOrderAcceptsValid(t *testing.T) {
+	in := models.OrderInput{
+		CustomerID: ptr("ALFKI"),
+		EmployeeID: ptr(5),
+		ShipVia:    ptr(1),
+		OrderDate:  ptr("1997-01-01"),
+		Details:    []models.OrderDetailInput{{ProductID: 11, Quantity: ptr(2)}},
+	}
+	if err := joinFields(validateOrder(in, true), validateLines(in.Details, true)); err != nil {
+		t.Errorf("valid order: %v", err)
+	}
+}
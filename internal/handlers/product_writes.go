@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+)
+
+func (h *Handler) productRecord() record[models.Product, int] {
+	return record[models.Product, int]{
+		what:     "product",
+		path:     "/products",
+		key:      "product_id",
+		assigned: true,
+		id:       func(v *models.Product) *int { return &v.ProductID },
+		parse:    idKey,
+		get:      h.store.GetProduct,
+		create:   h.store.CreateProduct,
+		update:   h.store.UpdateProduct,
+		remove:   h.store.DeleteProduct,
+	}
+}
+
+// GET /products/:id
+func (h *Handler) GetProduct(c *gin.Context) {
+	getRecord(c, h.productRecord())
+}
+
+// POST /products
+// Body: models.Product; product_id is assigned by the server.
+func (h *Handler) CreateProduct(c *gin.Context) {
+	createRecord(c, h.productRecord())
+}
+
+// PUT /products/:id
+// Body: models.Product; every field is overwritten. Requires If-Match.
+func (h *Handler) ReplaceProduct(c *gin.Context) {
+	replaceRecord(c, h.productRecord())
+}
+
+// PATCH /products/:id
+// Body: the fields of models.Product to change. Requires If-Match.
+func (h *Handler) PatchProduct(c *gin.Context) {
+	patchRecord(c, h.productRecord())
+}
+
+// DELETE /products/:id
+// Requires If-Match. The product is hidden from every endpoint but kept in the
+// database.
+func (h *Handler) DeleteProduct(c *gin.Context) {
+	deleteRecord(c, h.productRecord())
+}
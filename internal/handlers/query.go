@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nicholasraynes/northwind-api/internal/export"
+	"github.com/nicholasraynes/northwind-api/internal/fieldset"
+	"github.com/nicholasraynes/northwind-api/internal/page"
+	"github.com/nicholasraynes/northwind-api/internal/sorting"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// metricsQuery is the body of POST /query. Filters, sort, fields and the
+// pagination settings take the same values as the query parameters of the
+// GET endpoints; a filter may also be given as a list.
+type metricsQuery struct {
+	Measures   []string       `json:"measures"`
+	Dimensions []string       `json:"dimensions"`
+	Filters    map[string]any `json:"filters"`
+	Sort       string         `json:"sort"`
+	Fields     string         `json:"fields"`
+	Limit      *int           `json:"limit"`
+	Offset     *int           `json:"offset"`
+	Cursor     string         `json:"cursor"`
+	Format     string         `json:"format"`
+}
+
+// POST /query
+// Aggregates the requested measures by the requested dimensions.
+func (h *Handler) QueryMetrics(c *gin.Context) {
+	var body metricsQuery
+	if !decodeBody(c, &body) {
+		return
+	}
+
+	sel, err := body.selection()
+	if err != nil {
+		writeError(c, "query", err)
+		return
+	}
+	values, err := body.values()
+	if err != nil {
+		writeError(c, "query", err)
+		return
+	}
+
+	outputs := sel.Outputs()
+	q, ok := parseValues(c, values, store.QueryFilters, sorting.Spec(outputs), fieldset.Spec(outputs))
+	if !ok {
+		return
+	}
+
+	results, err := h.store.Aggregate(c.Request.Context(), sel, q.Query)
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	filters := echo(q.Query)
+	filters["measures"] = body.Measures
+	filters["dimensions"] = sel.Outputs()[:len(sel.Dimensions)]
+	respondWithFilters(c, q, results, filters)
+}
+
+// selection checks the measures and dimensions of b against the semantic
+// layer.
+func (b metricsQuery) selection() (store.Selection, error) {
+	if len(b.Measures) == 0 {
+		return store.Selection{}, invalid("measures", "must name at least one measure")
+	}
+	var sel store.Selection
+	for _, name := range b.Measures {
+		if _, ok := store.FindTerm(store.Measures, name); !ok {
+			return sel, invalid("measures", "cannot use %q; measures are %s", name, termNames(store.Measures))
+		}
+		if slices.Contains(sel.Outputs(), name) {
+			return sel, invalid("measures", "lists %q more than once", name)
+		}
+		sel.Measures = append(sel.Measures, store.Column{Name: name})
+	}
+	for _, name := range b.Dimensions {
+		if _, ok := store.FindTerm(store.Dimensions, name); !ok {
+			return sel, invalid("dimensions", "cannot use %q; dimensions are %s", name, termNames(store.Dimensions))
+		}
+		if slices.Contains(sel.Outputs(), name) {
+			return sel, invalid("dimensions", "lists %q more than once", name)
+		}
+		sel.Dimensions = append(sel.Dimensions, store.Column{Name: name})
+	}
+	return sel, nil
+}
+
+// values renders b as the query parameters the GET endpoints would take, so
+// that filters, sorting and paging share their parsing, and the cursor
+// fingerprint covers the measures and dimensions too.
+func (b metricsQuery) values() (url.Values, error) {
+	known := map[string]bool{}
+	for _, p := range store.QueryFilters.Params() {
+		known[p.Name] = true
+	}
+
+	values := url.Values{}
+	for name, v := range b.Filters {
+		if !known[name] {
+			return nil, invalid("filters."+name, "is not a filter; filters are the dimensions, order_date, order_date_from and order_date_to")
+		}
+		raw, err := filterValue(v)
+		if err != nil {
+			return nil, invalid("filters."+name, "%s", err)
+		}
+		values.Set(name, raw)
+	}
+
+	values.Set("measures", strings.Join(b.Measures, ","))
+	values.Set("dimensions", strings.Join(b.Dimensions, ","))
+	set := func(name, v string) {
+		if v != "" {
+			values.Set(name, v)
+		}
+	}
+	set(sorting.Param, b.Sort)
+	set(fieldset.Param, b.Fields)
+	set(page.CursorParam, b.Cursor)
+	set(export.Param, b.Format)
+	if b.Limit != nil {
+		values.Set(page.LimitParam, strconv.Itoa(*b.Limit))
+	}
+	if b.Offset != nil {
+		values.Set(page.OffsetParam, strconv.Itoa(*b.Offset))
+	}
+	return values, nil
+}
+
+// filterValue renders a JSON filter value as its query parameter. Lists are
+// joined with commas.
+func filterValue(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case []any:
+		parts := []string{}
+		for _, e := range v {
+			if _, ok := e.([]any); ok {
+				return "", fmt.Errorf("must not nest lists")
+			}
+			s, err := filterValue(e)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, s)
+		}
+		return strings.Join(parts, ","), nil
+	}
+	return "", fmt.Errorf("must be a string, number, boolean or list of those")
+}
+
+func termNames(terms []store.Term) string {
+	names := []string{}
+	for _, t := range terms {
+		names = append(names, t.Name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nicholasraynes/northwind-api/internal/logging"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// record binds the single-record endpoints of a collection whose body is its
+// model, such as /customers/:id, to the store. K is the type of the key.
+type record[T any, K comparable] struct {
+	// what names one record in error messages.
+	what string
+	// path is the collection path; a record lives at path/<key>.
+	path string
+	// key is the JSON name of the key field.
+	key string
+	// assigned is set when the store picks the key of a new record.
+	assigned bool
+
+	id     func(v *T) *K
+	parse  func(c *gin.Context) (K, bool)
+	get    func(ctx context.Context, id K) (T, error)
+	create func(ctx context.Context, v T) (T, error)
+	update func(ctx context.Context, v T, ifMatch string) (T, error)
+	remove func(ctx context.Context, id K, ifMatch string) error
+}
+
+// getRecord answers GET path/:id with the record and its ETag, or 304 when
+// If-None-Match names the current version.
+func getRecord[T any, K comparable](c *gin.Context, r record[T, K]) {
+	id, ok := r.parse(c)
+	if !ok {
+		return
+	}
+
+	v, err := r.get(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, r.what, err)
+		return
+	}
+
+	c.Header("ETag", store.ETag(v))
+	if inm := c.GetHeader("If-None-Match"); inm != "" && store.Matches(inm, v) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Set(logging.RowsKey, 1)
+	c.JSON(http.StatusOK, gin.H{"data": v})
+}
+
+// createRecord answers POST path with 201, the stored record, its Location
+// and its ETag.
+func createRecord[T any, K comparable](c *gin.Context, r record[T, K]) {
+	var v T
+	if !decodeBody(c, &v) {
+		return
+	}
+	var zero K
+	if r.assigned && *r.id(&v) != zero {
+		writeError(c, r.what, invalid(r.key, "is assigned by the server"))
+		return
+	}
+	if err := validateBody(&v); err != nil {
+		writeError(c, r.what, err)
+		return
+	}
+
+	v, err := r.create(c.Request.Context(), v)
+	if err != nil {
+		writeError(c, r.what, err)
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("%s/%v", r.path, *r.id(&v)))
+	c.Header("ETag", store.ETag(v))
+	c.JSON(http.StatusCreated, gin.H{"data": v})
+}
+
+// replaceRecord answers PUT path/:id, which overwrites every field.
+func replaceRecord[T any, K comparable](c *gin.Context, r record[T, K]) {
+	id, ifMatch, ok := preconditions(c, r)
+	if !ok {
+		return
+	}
+
+	var v T
+	if !decodeBody(c, &v) {
+		return
+	}
+	r.save(c, id, ifMatch, v)
+}
+
+// patchRecord answers PATCH path/:id. The body is merged over the current
+// record, so omitted fields keep their values. null clears a nullable field,
+// such as a customer's region, and leaves the others unchanged.
+func patchRecord[T any, K comparable](c *gin.Context, r record[T, K]) {
+	id, ifMatch, ok := preconditions(c, r)
+	if !ok {
+		return
+	}
+
+	v, err := r.get(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, r.what, err)
+		return
+	}
+	if !decodeBody(c, &v) {
+		return
+	}
+	r.save(c, id, ifMatch, v)
+}
+
+// save validates v as the new state of the record with key id and stores it.
+func (r record[T, K]) save(c *gin.Context, id K, ifMatch string, v T) {
+	var zero K
+	key := r.id(&v)
+	if *key != zero && *key != id {
+		writeError(c, r.what, invalid(r.key, "does not match the path"))
+		return
+	}
+	*key = id
+	if err := validateBody(&v); err != nil {
+		writeError(c, r.what, err)
+		return
+	}
+
+	v, err := r.update(c.Request.Context(), v, ifMatch)
+	if err != nil {
+		writeError(c, r.what, err)
+		return
+	}
+
+	c.Header("ETag", store.ETag(v))
+	c.JSON(http.StatusOK, gin.H{"data": v})
+}
+
+// deleteRecord answers DELETE path/:id with 204.
+func deleteRecord[T any, K comparable](c *gin.Context, r record[T, K]) {
+	id, ifMatch, ok := preconditions(c, r)
+	if !ok {
+		return
+	}
+
+	if err := r.remove(c.Request.Context(), id, ifMatch); err != nil {
+		writeError(c, r.what, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// preconditions reads the key and the If-Match header every change to a
+// record must carry. Without the header it writes a 428 and returns false.
+func preconditions[T any, K comparable](c *gin.Context, r record[T, K]) (K, string, bool) {
+	id, ok := r.parse(c)
+	if !ok {
+		return id, "", false
+	}
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		fail(c, http.StatusPreconditionRequired, codePreconditionRequired,
+			fmt.Sprintf("If-Match is required; send the ETag from GET %s/%v, or * to overwrite any version", r.path, id))
+		return id, "", false
+	}
+	return id, ifMatch, true
+}
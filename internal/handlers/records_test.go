@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nicholasraynes/northwind-api/internal/models"
+)
+
+// TestPatchNull fails when null in a PATCH body stops clearing a nullable
+// field, or starts blanking a field that cannot be null.
+func TestPatchNull(t *testing.T) {
+	stored := models.Customer{
+		CustomerID:  "ALFKI",
+		CompanyName: "Alfreds Futterkiste",
+		ContactName: "Maria Anders",
+		City:        "Berlin",
+		Region:      ptr("Brandenburg"),
+		Fax:         ptr("030-0076545"),
+	}
+	r := record[models.Customer, string]{
+		what:  "customer",
+		path:  "/customers",
+		key:   "customer_id",
+		id:    func(v *models.Customer) *string { return &v.CustomerID },
+		parse: customerKey,
+		get:   func(ctx context.Context, id string) (models.Customer, error) { return stored, nil },
+		update: func(ctx context.Context, v models.Customer, ifMatch string) (models.Customer, error) {
+			stored = v
+			return v, nil
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "ALFKI"}}
+	c.Request = httptest.NewRequest(http.MethodPatch, "/customers/ALFKI",
+		strings.NewReader(`{"region": null, "contact_name": null, "city": "Potsdam"}`))
+	c.Request.Header.Set("If-Match", "*")
+	patchRecord(c, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var body recordBody[models.Customer]
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	got := body.Data
+	if got.Region != nil {
+		t.Errorf("region = %q, want null", *got.Region)
+	}
+	if got.ContactName != "Maria Anders" {
+		t.Errorf("contact_name = %q, want it unchanged", got.ContactName)
+	}
+	if got.City != "Potsdam" {
+		t.Errorf("city = %q, want Potsdam", got.City)
+	}
+	if got.Fax == nil || *got.Fax != "030-0076545" {
+		t.Errorf("fax = %v, want it unchanged", got.Fax)
+	}
+}
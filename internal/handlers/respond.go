@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nicholasraynes/northwind-api/internal/export"
+	"github.com/nicholasraynes/northwind-api/internal/fieldset"
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/logging"
+	"github.com/nicholasraynes/northwind-api/internal/page"
+	"github.com/nicholasraynes/northwind-api/internal/sorting"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// listRequest is a parsed request for one page of a collection.
+type listRequest struct {
+	store.Query
+	// fields is every field the endpoint returns. Exports use it for their
+	// columns when no projection was requested.
+	fields fieldset.Spec
+	format export.Format
+}
+
+// parseQuery validates the response format, the filters in spec, the sort
+// order against sorts, the projection against fields and the pagination
+// parameters of the request, and records the filters on the request context
+// for traces and logs. On failure it writes a 400 listing every invalid
+// parameter and returns false.
+func parseQuery(c *gin.Context, spec filter.Spec, sorts sorting.Spec, fields fieldset.Spec) (listRequest, bool) {
+	return parseValues(c, c.Request.URL.Query(), spec, sorts, fields)
+}
+
+// parseValues is parseQuery for parameters that do not come from the URL,
+// such as those of a request body.
+func parseValues(c *gin.Context, values url.Values, spec filter.Spec, sorts sorting.Spec, fields fieldset.Spec) (listRequest, bool) {
+	var invalid []filter.FieldError
+	check := func(err error) {
+		if fe, ok := fieldErrors(err); ok {
+			invalid = append(invalid, fe...)
+		}
+	}
+
+	format, err := export.Negotiate(values.Get(export.Param), c.GetHeader("Accept"))
+	check(err)
+	// A cursor stays valid when only the format changes.
+	values.Del(export.Param)
+
+	f, err := spec.Parse(values)
+	check(err)
+	o, err := sorts.Parse(values)
+	check(err)
+	proj, err := fields.Parse(values)
+	check(err)
+	p, err := page.Parse(values)
+	check(err)
+	// An export is the whole collection unless a page was asked for.
+	if format != export.JSON && !page.Explicit(values) {
+		p = page.All
+	}
+
+	if len(invalid) > 0 {
+		badRequest(c, invalid...)
+		return listRequest{}, false
+	}
+	c.Request = c.Request.WithContext(filter.NewContext(c.Request.Context(), f))
+	return listRequest{
+		Query:  store.Query{Filters: f, Sort: o, Fields: proj, Page: p},
+		fields: fields,
+		format: format,
+	}, true
+}
+
+// choice reads the query parameter name, which must be one of allowed. An
+// absent parameter selects allowed[0]. On an unknown value it writes a 400
+// and returns false.
+func choice(c *gin.Context, name string, allowed []string) (string, bool) {
+	v := strings.TrimSpace(c.Query(name))
+	if v == "" {
+		return allowed[0], true
+	}
+	if !slices.Contains(allowed, v) {
+		badRequest(c, filter.FieldError{Field: name, Message: "must be one of " + strings.Join(allowed, ", ")})
+		return "", false
+	}
+	return v, true
+}
+
+// respond writes one page of a collection in the negotiated format, together
+// with the filters that produced it. It closes rows.
+func respond[T any](c *gin.Context, q listRequest, rows store.Rows[T]) {
+	respondWithFilters(c, q, rows, echo(q.Query))
+}
+
+// echo describes the applied filters, sort order and projection for the
+// response's "filters" object.
+func echo(q store.Query) map[string]any {
+	filters := q.Filters.Echo()
+	if len(q.Sort) > 0 {
+		filters[sorting.Param] = q.Sort.String()
+	}
+	if q.Fields != nil {
+		filters[fieldset.Param] = q.Fields
+	}
+	return filters
+}
+
+// respondWithFilters is respond for handlers that add derived values to the
+// echoed filters. Exports carry no filters block, so they ignore them.
+func respondWithFilters[T any](c *gin.Context, q listRequest, rows store.Rows[T], filters map[string]any) {
+	if q.format != export.JSON {
+		stream(c, q, rows)
+		return
+	}
+
+	res, err := store.Collect(rows)
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	c.Set(logging.RowsKey, len(res.Rows))
+
+	var next any
+	if cursor := q.Page.Next(res.Total); cursor != "" {
+		next = cursor
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"filters":     filters,
+		"count":       len(res.Rows),
+		"total_count": res.Total,
+		"limit":       q.Page.Limit,
+		"offset":      q.Page.Offset,
+		"next_cursor": next,
+		"has_more":    next != nil,
+		"data":        fieldset.Project(res.Rows, q.Fields),
+	})
+}
+
+// stream writes rows in an export format as they are read. The total travels
+// in X-Total-Count and, when a page was asked for, the next cursor in
+// X-Next-Cursor. Once the body has started an error can only be logged,
+// leaving a truncated document.
+func stream[T any](c *gin.Context, q listRequest, rows store.Rows[T]) {
+	defer rows.Close()
+
+	columns := []string(q.fields)
+	if q.Fields != nil {
+		columns = q.Fields
+	}
+	get := fieldset.Getter[T](columns)
+
+	// The first row carries the total, so read it before sending headers.
+	more := rows.Next()
+	if err := rows.Err(); err != nil {
+		storeError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", q.format.MediaType())
+	if q.format != export.NDJSON {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, path.Base(c.FullPath()), q.format))
+	}
+	c.Header("X-Total-Count", strconv.Itoa(rows.Total()))
+	if cursor := q.Page.Next(rows.Total()); cursor != "" {
+		c.Header("X-Next-Cursor", cursor)
+	}
+	c.Status(http.StatusOK)
+
+	w, err := export.NewWriter(q.format, c.Writer, columns)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	n := 0
+	defer func() { c.Set(logging.RowsKey, n) }()
+	for ; more; more = rows.Next() {
+		row := rows.Row()
+		if err := w.Write(get(&row)); err != nil {
+			c.Error(err)
+			return
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		c.Error(err)
+		return
+	}
+	if err := w.Close(); err != nil {
+		c.Error(err)
+	}
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nicholasraynes/northwind-api/internal/fieldset"
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/sorting"
+)
+
+// sliceRows serves one page of rows from memory, as a store would after
+// applying the query's limit and offset to a collection of total rows.
+type sliceRows[T any] struct {
+	rows  []T
+	total int
+	i     int
+}
+
+func (r *sliceRows[T]) Next() bool {
+	r.i++
+	return r.i <= len(r.rows)
+}
+func (r *sliceRows[T]) Row() T       { return r.rows[r.i-1] }
+func (r *sliceRows[T]) Total() int   { return r.total }
+func (r *sliceRows[T]) Err() error   { return nil }
+func (r *sliceRows[T]) Close() error { return nil }
+
+type item struct {
+	ID int `json:"id"`
+}
+
+// window returns the rows at [offset, offset+limit) of a collection of total
+// items numbered from 0.
+func window(offset, limit, total int) *sliceRows[item] {
+	rs := &sliceRows[item]{total: total}
+	for i := offset; i < offset+limit && i < total; i++ {
+		rs.rows = append(rs.rows, item{ID: i})
+	}
+	return rs
+}
+
+// get runs h on a request for target and returns the recorded response.
+func get(t *testing.T, target string, h func(c *gin.Context)) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	h(c)
+	return w
+}
+
+var itemFields = fieldset.Spec{"id"}
+
+// list answers target with the page of a 25 item collection it asks for.
+func list(c *gin.Context) {
+	q, ok := parseQuery(c, filter.Spec{}, sorting.Spec{}, itemFields)
+	if !ok {
+		return
+	}
+	respond(c, q, window(q.Page.Offset, q.Page.Limit, 25))
+}
+
+func TestRespondHasMore(t *testing.T) {
+	tests := []struct {
+		target  string
+		count   int
+		hasMore bool
+	}{
+		{"/items?limit=10", 10, true},
+		{"/items?limit=10&offset=10", 10, true},
+		{"/items?limit=10&offset=20", 5, false},
+		{"/items?limit=5&offset=20", 5, false},
+		{"/items", 25, false},
+	}
+	for _, tt := range tests {
+		w := get(t, tt.target, list)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d: %s", tt.target, w.Code, w.Body)
+		}
+		var body listBody[item]
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("GET %s: %v", tt.target, err)
+		}
+		if body.Count != tt.count || body.TotalCount != 25 {
+			t.Errorf("GET %s: count %d of %d, want %d of 25", tt.target, body.Count, body.TotalCount, tt.count)
+		}
+		if body.HasMore != tt.hasMore {
+			t.Errorf("GET %s: has_more %v, want %v", tt.target, body.HasMore, tt.hasMore)
+		}
+		if (body.NextCursor != nil) != tt.hasMore {
+			t.Errorf("GET %s: next_cursor %v with has_more %v", tt.target, body.NextCursor, body.HasMore)
+		}
+	}
+}
+
+// TestRespondPagesThrough follows next_cursor from the first page until
+// has_more is false and expects to see every row exactly once.
+func TestRespondPagesThrough(t *testing.T) {
+	target := "/items?limit=10"
+	seen := 0
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("has_more never became false")
+		}
+		var body listBody[item]
+		if err := json.Unmarshal(get(t, target, list).Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		for _, it := range body.Data {
+			if it.ID != seen {
+				t.Fatalf("row %d on page %d, want %d", it.ID, pages, seen)
+			}
+			seen++
+		}
+		if !body.HasMore {
+			break
+		}
+		target = "/items?limit=10&cursor=" + *body.NextCursor
+	}
+	if seen != 25 {
+		t.Errorf("paged through %d rows, want 25", seen)
+	}
+}
+
+// TestExportWholeCollection fails when an export without limit, offset or
+// cursor is cut to the default page.
+func TestExportWholeCollection(t *testing.T) {
+	tests := []struct {
+		target string
+		rows   int
+		cursor bool
+	}{
+		{"/items?format=ndjson", 25, false},
+		{"/items?format=csv", 25, false},
+		{"/items?format=ndjson&limit=10", 10, true},
+		{"/items?format=ndjson&offset=20", 5, false},
+	}
+	for _, tt := range tests {
+		w := get(t, tt.target, list)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d: %s", tt.target, w.Code, w.Body)
+		}
+		lines := strings.Count(w.Body.String(), "\n")
+		if strings.Contains(tt.target, "csv") {
+			lines-- // the header
+		}
+		if lines != tt.rows {
+			t.Errorf("GET %s: %d rows, want %d", tt.target, lines, tt.rows)
+		}
+		if got := w.Header().Get("X-Total-Count"); got != "25" {
+			t.Errorf("GET %s: X-Total-Count %q, want 25", tt.target, got)
+		}
+		if cursor := w.Header().Get("X-Next-Cursor"); (cursor != "") != tt.cursor {
+			t.Errorf("GET %s: X-Next-Cursor %q", tt.target, cursor)
+		}
+	}
+}
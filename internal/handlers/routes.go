@@ -0,0 +1,796 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nicholasraynes/northwind-api/internal/auth"
+	"github.com/nicholasraynes/northwind-api/internal/export"
+	"github.com/nicholasraynes/northwind-api/internal/fieldset"
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/page"
+	"github.com/nicholasraynes/northwind-api/internal/sorting"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// Parameter types, as named by JSON Schema in the MCP tool schemas and the
+// OpenAPI document.
+const (
+	ParamString  = "string"
+	ParamInteger = "integer"
+	ParamNumber  = "number"
+	ParamBoolean = "boolean"
+)
+
+// Parameter locations. Path parameters are always required.
+const (
+	InQuery = "query"
+	InPath  = "path"
+)
+
+// Param describes a query or path parameter read by a handler. An empty In
+// means InQuery.
+type Param struct {
+	Name        string
+	In          string
+	Type        string
+	Description string
+}
+
+// Route ties a handler to its path and the metadata needed to expose it as
+// an MCP tool and describe it in the OpenAPI document. cmd/api registers
+// every entry on the Gin router.
+type Route struct {
+	Method      string
+	Path        string
+	Name        string
+	Summary     string
+	Description string
+	// Scope is the scope a caller must hold; empty means the route is
+	// public.
+	Scope  auth.Scope
+	Params []Param
+	// Body is a value of the type the handler decodes its request body
+	// into, or nil when it reads none.
+	Body any
+	// Status is the status of a successful response; zero means 200.
+	Status int
+	// Response is a value of the type of a successful response's body, or
+	// nil when it has none.
+	Response any
+	// Timeout bounds the time the route's queries may take; zero means the
+	// router's default.
+	Timeout time.Duration
+	Handler gin.HandlerFunc
+}
+
+// The bodies written by the handlers, for the OpenAPI document.
+type (
+	// listBody is a page of a collection, as written by respond.
+	listBody[T any] struct {
+		Filters    map[string]any `json:"filters"`
+		Count      int            `json:"count"`
+		TotalCount int            `json:"total_count"`
+		Limit      int            `json:"limit"`
+		Offset     int            `json:"offset"`
+		NextCursor *string        `json:"next_cursor"`
+		HasMore    bool           `json:"has_more"`
+		Data       []T            `json:"data"`
+	}
+	// recordBody is a single record.
+	recordBody[T any] struct {
+		Data T `json:"data"`
+	}
+	// linesBody is an order's line items after a write.
+	linesBody struct {
+		Count int                  `json:"count"`
+		Data  []models.OrderDetail `json:"data"`
+	}
+	// createdOrderBody is a new order with its line items.
+	createdOrderBody struct {
+		Data    models.Order         `json:"data"`
+		Details []models.OrderDetail `json:"details"`
+	}
+	// keyBody is an API key along with the key itself, after it is created
+	// or rotated.
+	keyBody struct {
+		Data models.APIKey `json:"data"`
+		Key  string        `json:"key"`
+	}
+	// liveBody answers a liveness probe.
+	liveBody struct {
+		Status string `json:"status"`
+	}
+	// readyBody answers a readiness probe: ok, or unavailable along with the
+	// check that failed.
+	readyBody struct {
+		Status string      `json:"status"`
+		Checks readyChecks `json:"checks"`
+		Build  buildInfo   `json:"build"`
+	}
+)
+
+// orderIDParam addresses a single order.
+var orderIDParam = Param{Name: "id", In: InPath, Type: ParamInteger, Description: "Order ID"}
+
+// Path parameters addressing a single customer, product or supplier.
+var (
+	customerIDParam = Param{Name: "id", In: InPath, Type: ParamString, Description: "Customer ID, e.g. ALFKI"}
+	productIDParam  = Param{Name: "id", In: InPath, Type: ParamInteger, Description: "Product ID"}
+	supplierIDParam = Param{Name: "id", In: InPath, Type: ParamInteger, Description: "Supplier ID"}
+)
+
+// keyIDParam addresses a single API key.
+var keyIDParam = Param{Name: "id", In: InPath, Type: ParamInteger, Description: "API key ID"}
+
+// pageParams are accepted by every collection endpoint.
+var pageParams = []Param{
+	{Name: page.LimitParam, Type: ParamInteger, Description: fmt.Sprintf("Maximum number of rows to return (default %d, max %d; exports default to every row)", page.DefaultLimit, page.MaxLimit)},
+	{Name: page.OffsetParam, Type: ParamInteger, Description: "Number of rows to skip"},
+	{Name: page.CursorParam, Type: ParamString, Description: "Opaque cursor from a previous response's next_cursor; replaces offset"},
+}
+
+// formatParam selects an export format for any collection endpoint.
+var formatParam = Param{
+	Name:        export.Param,
+	Type:        ParamString,
+	Description: "Response format: json (default), csv, ndjson or xlsx; overrides the Accept header. Exports hold every row unless limit, offset or cursor is given",
+}
+
+// choiceParam describes a parameter read by choice.
+func choiceParam(name, description string, allowed []string) Param {
+	return Param{
+		Name:        name,
+		Type:        ParamString,
+		Description: fmt.Sprintf("%s: %s (default %s)", description, strings.Join(allowed, ", "), allowed[0]),
+	}
+}
+
+// compareParam is accepted by the sales-by-* summaries.
+var compareParam = Param{
+	Name:        store.CompareParam,
+	Type:        ParamString,
+	Description: "Compare each group with previous_year, previous_period or a year such as 1996, adding prior_total_sales, change and pct_growth (which can then be sorted and selected). The current period comes from year or order_date",
+}
+
+// listParams describes the query parameters accepted by a collection
+// endpoint: the filters in spec, the sort and fields parameters and the
+// pagination and format parameters.
+func listParams(spec filter.Spec, sorts sorting.Spec, fields fieldset.Spec) []Param {
+	params := []Param{}
+	for _, p := range spec.Params() {
+		params = append(params, Param{Name: p.Name, Type: p.Type, Description: p.Description})
+	}
+	params = append(params, Param{
+		Name:        sorting.Param,
+		Type:        ParamString,
+		Description: "Comma-separated fields to sort by; prefix a field with - for descending order. One of: " + strings.Join(sorts, ", "),
+	}, Param{
+		Name:        fieldset.Param,
+		Type:        ParamString,
+		Description: "Comma-separated fields to return; omit for all. One of: " + strings.Join(fields, ", "),
+	})
+	params = append(params, pageParams...)
+	return append(params, formatParam)
+}
+
+// Routes lists every endpoint served by the API, bound to h.
+func (h *Handler) Routes() []Route {
+	return []Route{
+		{
+			Method:      http.MethodGet,
+			Path:        "/livez",
+			Name:        "checkLiveness",
+			Summary:     "Liveness Check",
+			Description: "Report that the server process is up, without touching the database.",
+			Response:    liveBody{},
+			Handler:     h.Live,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/readyz",
+			Name:        "checkReadiness",
+			Summary:     "Readiness Check",
+			Description: "Check that the database answers and holds the Northwind tables, and report connection pool saturation and the build. Answers 503 with each check's outcome when the server cannot serve.",
+			Response:    readyBody{},
+			Handler:     h.Ready,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/health",
+			Name:        "checkHealth",
+			Summary:     "Health Check",
+			Description: "Same as /readyz, kept for existing monitors.",
+			Response:    readyBody{},
+			Handler:     h.Ready,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/customers",
+			Name:        "getCustomers",
+			Scope:       auth.ReadCore,
+			Summary:     "Get Customers",
+			Description: "Retrieve all customers, including company names, cities, and contact details.",
+			Params:      listParams(store.CustomerFilters, store.CustomerSorts, store.CustomerFields),
+			Response:    listBody[models.Customer]{},
+			Handler:     h.GetCustomers,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/customers/:id",
+			Name:        "getCustomer",
+			Scope:       auth.ReadCore,
+			Summary:     "Get Customer",
+			Description: "Retrieve a single customer by ID. The response carries an ETag for conditional updates.",
+			Params:      []Param{customerIDParam},
+			Response:    recordBody[models.Customer]{},
+			Handler:     h.GetCustomer,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/customers",
+			Name:        "createCustomer",
+			Scope:       auth.WriteCore,
+			Summary:     "Create Customer",
+			Description: "Create a customer.",
+			Body:        models.Customer{},
+			Status:      http.StatusCreated,
+			Response:    recordBody[models.Customer]{},
+			Handler:     h.CreateCustomer,
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/customers/:id",
+			Name:        "replaceCustomer",
+			Scope:       auth.WriteCore,
+			Summary:     "Replace Customer",
+			Description: "Replace every field of a customer. Requires an If-Match header with the current ETag.",
+			Params:      []Param{customerIDParam},
+			Body:        models.Customer{},
+			Response:    recordBody[models.Customer]{},
+			Handler:     h.ReplaceCustomer,
+		},
+		{
+			Method:      http.MethodPatch,
+			Path:        "/customers/:id",
+			Name:        "patchCustomer",
+			Scope:       auth.WriteCore,
+			Summary:     "Update Customer",
+			Description: "Update the supplied fields of a customer. Requires an If-Match header with the current ETag.",
+			Params:      []Param{customerIDParam},
+			Body:        models.Customer{},
+			Response:    recordBody[models.Customer]{},
+			Handler:     h.PatchCustomer,
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/customers/:id",
+			Name:        "deleteCustomer",
+			Scope:       auth.WriteCore,
+			Summary:     "Delete Customer",
+			Description: "Soft-delete a customer: it disappears from the API but their orders are kept. Requires an If-Match header with the current ETag.",
+			Params:      []Param{customerIDParam},
+			Status:      http.StatusNoContent,
+			Handler:     h.DeleteCustomer,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/orders",
+			Name:        "getOrders",
+			Scope:       auth.ReadCore,
+			Summary:     "Get Orders",
+			Description: "Retrieve all orders with customer, employee, shipper, and date information.",
+			Params:      listParams(store.OrderFilters, store.OrderSorts, store.OrderFields),
+			Response:    listBody[models.Order]{},
+			Handler:     h.GetOrders,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/orders/details",
+			Name:        "getOrderDetails",
+			Scope:       auth.ReadCore,
+			Summary:     "Get Order Details",
+			Description: "Retrieve product-level order details including quantity, price, and discounts.",
+			Params:      listParams(store.OrderDetailFilters, store.OrderDetailSorts, store.OrderDetailFields),
+			Response:    listBody[models.OrderDetail]{},
+			Handler:     h.GetOrderDetails,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/orders",
+			Name:        "createOrder",
+			Scope:       auth.WriteOrders,
+			Summary:     "Create Order",
+			Description: "Create an order with its line items. Customer, employee, shipper and product references are validated and ordered quantities are taken out of stock.",
+			Body:        models.OrderInput{},
+			Status:      http.StatusCreated,
+			Response:    createdOrderBody{},
+			Handler:     h.CreateOrder,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/orders/:id",
+			Name:        "getOrder",
+			Scope:       auth.ReadCore,
+			Summary:     "Get Order",
+			Description: "Retrieve a single order by ID.",
+			Params:      []Param{orderIDParam},
+			Response:    recordBody[models.Order]{},
+			Handler:     h.GetOrder,
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/orders/:id",
+			Name:        "replaceOrder",
+			Scope:       auth.WriteOrders,
+			Summary:     "Replace Order",
+			Description: "Replace every field of an order; omitted optional fields are cleared. Line items are unchanged.",
+			Params:      []Param{orderIDParam},
+			Body:        models.OrderInput{},
+			Response:    recordBody[models.Order]{},
+			Handler:     h.ReplaceOrder,
+		},
+		{
+			Method:      http.MethodPatch,
+			Path:        "/orders/:id",
+			Name:        "patchOrder",
+			Scope:       auth.WriteOrders,
+			Summary:     "Update Order",
+			Description: "Update the supplied fields of an order. Line items are unchanged.",
+			Params:      []Param{orderIDParam},
+			Body:        models.OrderInput{},
+			Response:    recordBody[models.Order]{},
+			Handler:     h.PatchOrder,
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/orders/:id",
+			Name:        "deleteOrder",
+			Scope:       auth.WriteOrders,
+			Summary:     "Delete Order",
+			Description: "Delete an order and its line items, returning their units to stock.",
+			Params:      []Param{orderIDParam},
+			Status:      http.StatusNoContent,
+			Handler:     h.DeleteOrder,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/orders/:id/details",
+			Name:        "addOrderDetails",
+			Scope:       auth.WriteOrders,
+			Summary:     "Add Order Line Items",
+			Description: "Add products that are not yet on the order, taking their quantities out of stock.",
+			Params:      []Param{orderIDParam},
+			Body:        []models.OrderDetailInput{},
+			Status:      http.StatusCreated,
+			Response:    linesBody{},
+			Handler:     h.AddOrderDetails,
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/orders/:id/details",
+			Name:        "replaceOrderDetails",
+			Scope:       auth.WriteOrders,
+			Summary:     "Replace Order Line Items",
+			Description: "Replace the order's line items; stock moves by the net change for each product.",
+			Params:      []Param{orderIDParam},
+			Body:        []models.OrderDetailInput{},
+			Response:    linesBody{},
+			Handler:     h.ReplaceOrderDetails,
+		},
+		{
+			Method:      http.MethodPatch,
+			Path:        "/orders/:id/details",
+			Name:        "patchOrderDetails",
+			Scope:       auth.WriteOrders,
+			Summary:     "Update Order Line Items",
+			Description: "Change the quantity, price or discount of line items already on the order.",
+			Params:      []Param{orderIDParam},
+			Body:        []models.OrderDetailInput{},
+			Response:    linesBody{},
+			Handler:     h.PatchOrderDetails,
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/orders/:id/details",
+			Name:        "deleteOrderDetails",
+			Scope:       auth.WriteOrders,
+			Summary:     "Remove Order Line Items",
+			Description: "Remove the listed products from the order, or every line item when none are listed, returning their units to stock.",
+			Params: []Param{
+				orderIDParam,
+				{Name: "product_id", Type: ParamString, Description: "Products to remove (comma-separated list); omit to remove every line item"},
+			},
+			Response: linesBody{},
+			Handler:  h.DeleteOrderDetails,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/products",
+			Name:        "getProducts",
+			Scope:       auth.ReadCore,
+			Summary:     "Get Products",
+			Description: "Retrieve products with supplier, category, price, and stock information.",
+			Params:      listParams(store.ProductFilters, store.ProductSorts, store.ProductFields),
+			Response:    listBody[models.Product]{},
+			Handler:     h.GetProducts,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/products/:id",
+			Name:        "getProduct",
+			Scope:       auth.ReadCore,
+			Summary:     "Get Product",
+			Description: "Retrieve a single product by ID. The response carries an ETag for conditional updates.",
+			Params:      []Param{productIDParam},
+			Response:    recordBody[models.Product]{},
+			Handler:     h.GetProduct,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/products",
+			Name:        "createProduct",
+			Scope:       auth.WriteCore,
+			Summary:     "Create Product",
+			Description: "Create a product.",
+			Body:        models.Product{},
+			Status:      http.StatusCreated,
+			Response:    recordBody[models.Product]{},
+			Handler:     h.CreateProduct,
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/products/:id",
+			Name:        "replaceProduct",
+			Scope:       auth.WriteCore,
+			Summary:     "Replace Product",
+			Description: "Replace every field of a product. Requires an If-Match header with the current ETag.",
+			Params:      []Param{productIDParam},
+			Body:        models.Product{},
+			Response:    recordBody[models.Product]{},
+			Handler:     h.ReplaceProduct,
+		},
+		{
+			Method:      http.MethodPatch,
+			Path:        "/products/:id",
+			Name:        "patchProduct",
+			Scope:       auth.WriteCore,
+			Summary:     "Update Product",
+			Description: "Update the supplied fields of a product. Requires an If-Match header with the current ETag.",
+			Params:      []Param{productIDParam},
+			Body:        models.Product{},
+			Response:    recordBody[models.Product]{},
+			Handler:     h.PatchProduct,
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/products/:id",
+			Name:        "deleteProduct",
+			Scope:       auth.WriteCore,
+			Summary:     "Delete Product",
+			Description: "Soft-delete a product: it disappears from the API but order lines referring to it are kept. Requires an If-Match header with the current ETag.",
+			Params:      []Param{productIDParam},
+			Status:      http.StatusNoContent,
+			Handler:     h.DeleteProduct,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/suppliers",
+			Name:        "getSuppliers",
+			Scope:       auth.ReadCore,
+			Summary:     "Get Suppliers",
+			Description: "Retrieve all supplier information, including contact details and country.",
+			Params:      listParams(store.SupplierFilters, store.SupplierSorts, store.SupplierFields),
+			Response:    listBody[models.Supplier]{},
+			Handler:     h.GetSuppliers,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/suppliers/:id",
+			Name:        "getSupplier",
+			Scope:       auth.ReadCore,
+			Summary:     "Get Supplier",
+			Description: "Retrieve a single supplier by ID. The response carries an ETag for conditional updates.",
+			Params:      []Param{supplierIDParam},
+			Response:    recordBody[models.Supplier]{},
+			Handler:     h.GetSupplier,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/suppliers",
+			Name:        "createSupplier",
+			Scope:       auth.WriteCore,
+			Summary:     "Create Supplier",
+			Description: "Create a supplier.",
+			Body:        models.Supplier{},
+			Status:      http.StatusCreated,
+			Response:    recordBody[models.Supplier]{},
+			Handler:     h.CreateSupplier,
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/suppliers/:id",
+			Name:        "replaceSupplier",
+			Scope:       auth.WriteCore,
+			Summary:     "Replace Supplier",
+			Description: "Replace every field of a supplier. Requires an If-Match header with the current ETag.",
+			Params:      []Param{supplierIDParam},
+			Body:        models.Supplier{},
+			Response:    recordBody[models.Supplier]{},
+			Handler:     h.ReplaceSupplier,
+		},
+		{
+			Method:      http.MethodPatch,
+			Path:        "/suppliers/:id",
+			Name:        "patchSupplier",
+			Scope:       auth.WriteCore,
+			Summary:     "Update Supplier",
+			Description: "Update the supplied fields of a supplier. Requires an If-Match header with the current ETag.",
+			Params:      []Param{supplierIDParam},
+			Body:        models.Supplier{},
+			Response:    recordBody[models.Supplier]{},
+			Handler:     h.PatchSupplier,
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/suppliers/:id",
+			Name:        "deleteSupplier",
+			Scope:       auth.WriteCore,
+			Summary:     "Delete Supplier",
+			Description: "Soft-delete a supplier: it disappears from the API but their products keep referring to it. Requires an If-Match header with the current ETag.",
+			Params:      []Param{supplierIDParam},
+			Status:      http.StatusNoContent,
+			Handler:     h.DeleteSupplier,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/summary/sales-by-country",
+			Name:        "getSalesByCountry",
+			Scope:       auth.ReadAnalytics,
+			Summary:     "Sales by Country",
+			Description: "Aggregate total sales grouped by customer country.",
+			Params:      append(listParams(store.SalesByCountryFilters, store.SalesByCountrySorts, store.SalesByCountryFields), compareParam),
+			Response:    listBody[models.SalesSummary]{},
+			Handler:     h.GetSalesByCountry,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/summary/sales-by-category",
+			Name:        "getSalesByCategory",
+			Scope:       auth.ReadAnalytics,
+			Summary:     "Sales by Category",
+			Description: "Total revenue aggregated by product category.",
+			Params:      append(listParams(store.SalesByCategoryFilters, store.SalesByCategorySorts, store.SalesByCategoryFields), compareParam),
+			Response:    listBody[models.SalesSummary]{},
+			Handler:     h.GetSalesByCategory,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/summary/sales-by-employee",
+			Name:        "getSalesByEmployee",
+			Scope:       auth.ReadAnalytics,
+			Summary:     "Sales by Employee",
+			Description: "Total sales and order counts grouped by employee.",
+			Params:      append(listParams(store.SalesByEmployeeFilters, store.SalesByEmployeeSorts, store.SalesByEmployeeFields), compareParam),
+			Response:    listBody[models.SalesSummary]{},
+			Handler:     h.GetSalesByEmployee,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/summary/sales-by-year",
+			Name:        "getSalesByYear",
+			Scope:       auth.ReadAnalytics,
+			Summary:     "Sales by Year",
+			Description: "Annual sales totals across all countries and categories.",
+			Params:      listParams(store.SalesByYearFilters, store.SalesByYearSorts, store.SalesByYearFields),
+			Response:    listBody[models.SalesSummary]{},
+			Handler:     h.GetSalesByYear,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/summary/sales-over-time",
+			Name:        "getSalesOverTime",
+			Scope:       auth.ReadAnalytics,
+			Summary:     "Sales Over Time",
+			Description: "Sales totals per day, week, month, quarter or year, optionally split by country, category, employee or shipper. Periods without sales are included with zero totals.",
+			Params: append(
+				listParams(store.SalesOverTimeFilters, store.SalesOverTimeSorts, store.SalesOverTimeFields),
+				choiceParam("granularity", "Period length", store.Granularities),
+				choiceParam("split_by", "Split each period by", store.SalesSplits),
+			),
+			Response: listBody[models.SalesPeriod]{},
+			Timeout:  time.Minute,
+			Handler:  h.GetSalesOverTime,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/query",
+			Name:    "queryMetrics",
+			Scope:   auth.ReadAnalytics,
+			Summary: "Query Metrics",
+			Description: fmt.Sprintf("Aggregate sales measures (%s) by any dimensions (%s). "+
+				"The JSON body names the measures and dimensions and may carry filters on any dimension or order_date, "+
+				"plus sort, fields, limit, offset, cursor and format as for the list endpoints. The /summary endpoints are presets of this query.",
+				termNames(store.Measures), termNames(store.Dimensions)),
+			Body:     metricsQuery{},
+			Response: listBody[map[string]any]{},
+			Timeout:  time.Minute,
+			Handler:  h.QueryMetrics,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/summary/sales-by-shipper",
+			Name:        "getSalesByShipper",
+			Scope:       auth.ReadAnalytics,
+			Summary:     "Sales by Shipper",
+			Description: "Total sales grouped by freight company.",
+			Params:      append(listParams(store.SalesByShipperFilters, store.SalesByShipperSorts, store.SalesByShipperFields), compareParam),
+			Response:    listBody[models.SalesSummary]{},
+			Handler:     h.GetSalesByShipper,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/analytics/top-customers",
+			Name:        "getTopCustomers",
+			Scope:       auth.ReadAnalytics,
+			Summary:     "Top Customers",
+			Description: "Retrieve top customers ranked by total revenue.",
+			Params:      listParams(store.TopCustomersFilters, store.TopCustomersSorts, store.TopCustomersFields),
+			Response:    listBody[models.TopCustomer]{},
+			Handler:     h.GetTopCustomers,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/analytics/customer-orders",
+			Name:        "getCustomerOrders",
+			Scope:       auth.ReadAnalytics,
+			Summary:     "Customer Orders",
+			Description: "Detailed order history per customer.",
+			Params:      listParams(store.CustomerOrdersFilters, store.CustomerOrdersSorts, store.CustomerOrdersFields),
+			Response:    listBody[models.CustomerOrderSummary]{},
+			Handler:     h.GetCustomerOrders,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/analytics/customer-ltv",
+			Name:        "getCustomerLTV",
+			Scope:       auth.ReadAnalytics,
+			Summary:     "Customer Lifetime Value",
+			Description: "Total lifetime revenue per customer.",
+			Params:      listParams(store.CustomerLTVFilters, store.CustomerLTVSorts, store.CustomerLTVFields),
+			Response:    listBody[models.CustomerLTV]{},
+			Handler:     h.GetCustomerLTV,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/analytics/customer-retention",
+			Name:        "getCustomerRetention",
+			Scope:       auth.ReadAnalytics,
+			Summary:     "Customer Retention",
+			Description: "Measures repeat customers and retention rates.",
+			Params:      listParams(store.CustomerRetentionFilters, store.CustomerRetentionSorts, store.CustomerRetentionFields),
+			Response:    listBody[models.CustomerRetention]{},
+			Timeout:     time.Minute,
+			Handler:     h.GetCustomerRetention,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/analytics/top-products",
+			Name:        "getTopProducts",
+			Scope:       auth.ReadAnalytics,
+			Summary:     "Top Products",
+			Description: "Top-selling products by total revenue and units sold.",
+			Params:      listParams(store.TopProductsFilters, store.TopProductsSorts, store.TopProductsFields),
+			Response:    listBody[models.TopProduct]{},
+			Handler:     h.GetTopProducts,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/analytics/supplier-performance",
+			Name:        "getSupplierPerformance",
+			Scope:       auth.ReadAnalytics,
+			Summary:     "Supplier Performance",
+			Description: "Supplier contribution by revenue and efficiency.",
+			Params:      listParams(store.SupplierPerformanceFilters, store.SupplierPerformanceSorts, store.SupplierPerformanceFields),
+			Response:    listBody[models.SupplierPerformance]{},
+			Handler:     h.GetSupplierPerformance,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/analytics/inventory-status",
+			Name:        "getInventoryStatus",
+			Scope:       auth.ReadAnalytics,
+			Summary:     "Inventory Status",
+			Description: "Product stock levels, reorder needs, and discontinued items.",
+			Params:      listParams(store.InventoryStatusFilters, store.InventoryStatusSorts, store.InventoryStatusFields),
+			Response:    listBody[models.InventoryStatus]{},
+			Handler:     h.GetInventoryStatus,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/analytics/employee-performance",
+			Name:        "getEmployeePerformance",
+			Scope:       auth.ReadAnalytics,
+			Summary:     "Employee Performance",
+			Description: "Rank employees by sales totals and order count.",
+			Params:      listParams(store.EmployeePerformanceFilters, store.EmployeePerformanceSorts, store.EmployeePerformanceFields),
+			Response:    listBody[models.EmployeePerformance]{},
+			Handler:     h.GetEmployeePerformance,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/analytics/shipping-costs",
+			Name:        "getShippingCosts",
+			Scope:       auth.ReadAnalytics,
+			Summary:     "Shipping Costs",
+			Description: "Freight cost and order volume per shipping company.",
+			Params:      listParams(store.ShippingCostsFilters, store.ShippingCostsSorts, store.ShippingCostsFields),
+			Response:    listBody[models.ShippingCosts]{},
+			Handler:     h.GetShippingCosts,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/analytics/delivery-times",
+			Name:        "getDeliveryTimes",
+			Scope:       auth.ReadAnalytics,
+			Summary:     "Delivery Times",
+			Description: "Days from order to shipment, late shipments, on-time rate and open or overdue unshipped orders per shipper, employee or both.",
+			Params: append(
+				listParams(store.DeliveryTimesFilters, store.DeliveryTimesSorts, store.DeliveryTimesFields),
+				choiceParam("group_by", "Group orders by", store.DeliveryGroupings),
+			),
+			Response: listBody[models.DeliveryTimes]{},
+			Timeout:  time.Minute,
+			Handler:  h.GetDeliveryTimes,
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/admin/keys",
+			Name:        "getAPIKeys",
+			Scope:       auth.Admin,
+			Summary:     "Get API Keys",
+			Description: "List API keys, including revoked ones, with their scopes and when each was last used. Keys themselves are never returned.",
+			Params:      listParams(store.APIKeyFilters, store.APIKeySorts, store.APIKeyFields),
+			Response:    listBody[models.APIKey]{},
+			Handler:     h.GetAPIKeys,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/admin/keys",
+			Name:        "createAPIKey",
+			Scope:       auth.Admin,
+			Summary:     "Create API Key",
+			Description: "Create an API key with the given scopes. The key is returned once, in this response; only its hash is stored.",
+			Body:        models.APIKeyInput{},
+			Status:      http.StatusCreated,
+			Response:    keyBody{},
+			Handler:     h.CreateAPIKey,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/admin/keys/:id/rotate",
+			Name:        "rotateAPIKey",
+			Scope:       auth.Admin,
+			Summary:     "Rotate API Key",
+			Description: "Replace an API key with a new one, keeping its name and scopes. The old key stops working at once; the new one is returned once, in this response.",
+			Params:      []Param{keyIDParam},
+			Response:    keyBody{},
+			Handler:     h.RotateAPIKey,
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/admin/keys/:id",
+			Name:        "revokeAPIKey",
+			Scope:       auth.Admin,
+			Summary:     "Revoke API Key",
+			Description: "Revoke an API key. It stops working at once but stays listed.",
+			Params:      []Param{keyIDParam},
+			Status:      http.StatusNoContent,
+			Handler:     h.RevokeAPIKey,
+		},
+	}
+}
@@ -1,79 +1,27 @@
 package handlers
 
 import (
-	"fmt"
-	"net/http"
-	"strings"
-
 	"github.com/gin-gonic/gin"
-	"github.com/nicholasraynes/northwind-api/internal/db"
-	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
 )
 
 // GET /summary/sales-by-country
-// Optional parameters: year, country
-func GetSalesByCountry(c *gin.Context) {
-	year := c.Query("year")
-	country := c.Query("country")
-
-	query := `
-		SELECT
-			COALESCE(o.ship_country, 'Unknown') AS country,
-			SUM(od.unit_price * od.quantity * (1 - od.discount)) AS total_sales,
-			COUNT(DISTINCT o.order_id) AS order_count
-		FROM order_details od
-		JOIN orders o ON od.order_id = o.order_id
-	`
-
-	args := []any{}
-	conditions := []string{}
-
-	if year != "" {
-		conditions = append(conditions, fmt.Sprintf("EXTRACT(YEAR FROM o.order_date)::TEXT = $%d", len(args)+1))
-		args = append(args, year)
-	}
-	if country != "" {
-		conditions = append(conditions, fmt.Sprintf("LOWER(COALESCE(o.ship_country, 'Unknown')) LIKE LOWER($%d)", len(args)+1))
-		args = append(args, "%"+country+"%")
+// Optional parameters: year, order_date, country, compare_to (previous_year, previous_period or a year)
+func (h *Handler) GetSalesByCountry(c *gin.Context) {
+	if h.compareSales(c, store.SplitByCountry, store.SalesByCountryFilters) {
+		return
 	}
 
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
+	q, ok := parseQuery(c, store.SalesByCountryFilters, store.SalesByCountrySorts, store.SalesByCountryFields)
+	if !ok {
+		return
 	}
 
-	query += `
-		GROUP BY o.ship_country
-		ORDER BY total_sales DESC
-	`
-
-	rows, err := db.DB.Query(query, args...)
+	results, err := h.store.SalesByCountry(c.Request.Context(), q.Query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		storeError(c, err)
 		return
 	}
-	defer rows.Close()
-
-	results := []models.SalesSummary{}
-	for rows.Next() {
-		var s models.SalesSummary
-		if err := rows.Scan(&s.GroupKey, &s.TotalSales, &s.OrderCount); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		results = append(results, s)
-	}
-
-	filters := gin.H{}
-	if year != "" {
-		filters["year"] = year
-	}
-	if country != "" {
-		filters["country"] = country
-	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"filters": filters,
-		"count":   len(results),
-		"data":    results,
-	})
+	respond(c, q, results)
 }
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// compareSales answers a sales-by-* request that carries compare_to, with
+// the groups of the summary by the given dimension set against the prior
+// period. It reports false, having written nothing, when the request has no
+// compare_to.
+func (h *Handler) compareSales(c *gin.Context, by store.SalesSplit, spec filter.Spec) bool {
+	compareTo := c.Query(store.CompareParam)
+	if compareTo == "" {
+		return false
+	}
+
+	q, ok := parseQuery(c, spec, store.SalesComparisonSorts, store.SalesComparisonFields)
+	if !ok {
+		return true
+	}
+	prior, err := store.PriorPeriod(q.Filters, compareTo)
+	if err != nil {
+		storeError(c, err)
+		return true
+	}
+
+	results, err := h.store.CompareSales(c.Request.Context(), by, q.Query, prior)
+	if err != nil {
+		storeError(c, err)
+		return true
+	}
+
+	filters := echo(q.Query)
+	filters[store.CompareParam] = compareTo
+	filters["prior_period"] = prior.Echo()
+	respondWithFilters(c, q, results, filters)
+	return true
+}
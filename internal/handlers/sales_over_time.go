@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// GET /summary/sales-over-time?granularity=month&split_by=category&order_date_from=1997-01
+// Optional parameters: granularity (day, week, month, quarter or year), split_by (none, country, category, employee or shipper), year, order_date, country, category_name, employee_name, shipper_name
+func (h *Handler) GetSalesOverTime(c *gin.Context) {
+	granularity, ok := choice(c, "granularity", store.Granularities)
+	if !ok {
+		return
+	}
+	split, ok := choice(c, "split_by", store.SalesSplits)
+	if !ok {
+		return
+	}
+	q, ok := parseQuery(c, store.SalesOverTimeFilters, store.SalesOverTimeSorts, store.SalesOverTimeFields)
+	if !ok {
+		return
+	}
+
+	results, err := h.store.SalesOverTime(c.Request.Context(), q.Query, store.Granularity(granularity), store.SalesSplit(split))
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	filters := echo(q.Query)
+	filters["granularity"] = granularity
+	filters["split_by"] = split
+	respondWithFilters(c, q, results, filters)
+}
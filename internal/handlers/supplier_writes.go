@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+)
+
+func (h *Handler) supplierRecord() record[models.Supplier, int] {
+	return record[models.Supplier, int]{
+		what:     "supplier",
+		path:     "/suppliers",
+		key:      "supplier_id",
+		assigned: true,
+		id:       func(v *models.Supplier) *int { return &v.SupplierID },
+		parse:    idKey,
+		get:      h.store.GetSupplier,
+		create:   h.store.CreateSupplier,
+		update:   h.store.UpdateSupplier,
+		remove:   h.store.DeleteSupplier,
+	}
+}
+
+// GET /suppliers/:id
+func (h *Handler) GetSupplier(c *gin.Context) {
+	getRecord(c, h.supplierRecord())
+}
+
+// POST /suppliers
+// Body: models.Supplier; supplier_id is assigned by the server.
+func (h *Handler) CreateSupplier(c *gin.Context) {
+	createRecord(c, h.supplierRecord())
+}
+
+// PUT /suppliers/:id
+// Body: models.Supplier; every field is overwritten. Requires If-Match.
+func (h *Handler) ReplaceSupplier(c *gin.Context) {
+	replaceRecord(c, h.supplierRecord())
+}
+
+// PATCH /suppliers/:id
+// Body: the fields of models.Supplier to change. Requires If-Match.
+func (h *Handler) PatchSupplier(c *gin.Context) {
+	patchRecord(c, h.supplierRecord())
+}
+
+// DELETE /suppliers/:id
+// Requires If-Match. The supplier is hidden from every endpoint but kept in the
+// database.
+func (h *Handler) DeleteSupplier(c *gin.Context) {
+	deleteRecord(c, h.supplierRecord())
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultTimeout bounds the routes that declare no Timeout of their own,
+// unless the router is given another default.
+const DefaultTimeout = 30 * time.Second
+
+// timeoutKey is the gin.Context key holding the route's timeout, for the
+// error that reports it.
+const timeoutKey = "timeout"
+
+// Timeout bounds the request context by d. Once d passes, the queries the
+// route is running are canceled, and Postgres is asked to stop them; a store
+// call cut short is answered 504 by storeError.
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Set(timeoutKey, d)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
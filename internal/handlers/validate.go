@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+)
+
+func init() {
+	// Report fields by the names clients send rather than Go field names.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// validateBody checks v against the binding tags on its model and lists
+// every failure as invalidFields.
+func validateBody(v any) error {
+	err := binding.Validator.ValidateStruct(v)
+	var fields validator.ValidationErrors
+	if !errors.As(err, &fields) || len(fields) == 0 {
+		return err
+	}
+
+	invalid := invalidFields{}
+	for _, fe := range fields {
+		invalid = append(invalid, filter.FieldError{Field: fe.Field(), Message: ruleMessage(fe)})
+	}
+	return invalid
+}
+
+// ruleMessage phrases a failed validation rule for a client.
+func ruleMessage(fe validator.FieldError) string {
+	text := fe.Kind() == reflect.String
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "max":
+		if text {
+			return fmt.Sprintf("must be at most %s characters", fe.Param())
+		}
+		return "must be at most " + fe.Param()
+	case "min":
+		if fe.Kind() == reflect.Slice {
+			return "must have at least " + fe.Param() + " item(s)"
+		}
+		return "must be at least " + fe.Param()
+	case "gte":
+		if fe.Param() == "0" {
+			return "must not be negative"
+		}
+		return "must be at least " + fe.Param()
+	default:
+		return fmt.Sprintf("fails the %s rule", fe.Tag())
+	}
+}
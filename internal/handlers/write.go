@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// pathID reads a positive integer path parameter. On failure it writes a 400
+// and returns false.
+func pathID(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id < 1 {
+		badRequest(c, filter.FieldError{Field: name, Message: "must be a positive integer"})
+		return 0, false
+	}
+	return id, true
+}
+
+// idKey reads the integer id path parameter of a record endpoint.
+func idKey(c *gin.Context) (int, bool) {
+	return pathID(c, "id")
+}
+
+// decodeBody reads the JSON request body into v, rejecting unknown fields
+// and trailing data. On failure it writes a 400 and returns false.
+func decodeBody(c *gin.Context, v any) bool {
+	dec := json.NewDecoder(c.Request.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil && dec.More() {
+		err = errors.New("unexpected data after the JSON value")
+	}
+	if err != nil {
+		fail(c, http.StatusBadRequest, codeMalformedBody, "invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// writeError maps a store error from a write to its HTTP status.
+func writeError(c *gin.Context, what string, err error) {
+	var stock *store.StockError
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		fail(c, http.StatusNotFound, codeNotFound, what+" not found")
+	case errors.Is(err, store.ErrExists):
+		fail(c, http.StatusConflict, codeAlreadyExists, what+" already exists")
+	case errors.Is(err, store.ErrVersionMismatch):
+		fail(c, http.StatusPreconditionFailed, codeVersionMismatch, what+" has changed; fetch it again for its current ETag")
+	case errors.As(err, &stock):
+		c.JSON(http.StatusConflict, ErrorBody{Error: stock.Error(), Code: codeInsufficientStock, StockError: stock})
+	default:
+		storeError(c, err)
+	}
+}
+
+// invalid builds the error for a field that failed validation in a handler.
+func invalid(field, format string, args ...any) error {
+	return &store.InvalidError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/nicholasraynes/northwind-api/internal/db"
+)
+
+// DB is a db.Observer logging statements that fail, tied to the request
+// that ran them, and at debug level every statement with its time and rows.
+var DB dbObserver
+
+type dbObserver struct{}
+
+func (dbObserver) Statement(ctx context.Context, query string) func(int64, error) {
+	start := time.Now()
+	return func(rows int64, err error) {
+		level, msg := slog.LevelDebug, "statement"
+		switch {
+		case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+			level, msg = slog.LevelWarn, "statement canceled"
+		case err != nil:
+			level, msg = slog.LevelError, "statement failed"
+		}
+		if !slog.Default().Enabled(ctx, level) {
+			return
+		}
+		attrs := []slog.Attr{
+			slog.String("query", db.Sanitize(query)),
+			slog.Int64("rows", rows),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		slog.LogAttrs(ctx, level, msg, attrs...)
+	}
+}
@@ -0,0 +1,86 @@
+// Package logging writes the service's logs as JSON lines with log/slog.
+//
+// Setup makes a JSON handler the default logger. Records logged with a
+// request's context, through slog's *Context functions, carry the request's
+// ID and trace, so every line about one request can be found from any of
+// them.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// Setup sends the default logger, and with it the log package and Gin's
+// debug output, to w as JSON, dropping records below level: debug, info (the
+// default when level is empty), warn or error.
+func Setup(w io.Writer, level string) error {
+	var l slog.Level
+	if level != "" {
+		if err := l.UnmarshalText([]byte(level)); err != nil {
+			return fmt.Errorf("LOG_LEVEL: %w", err)
+		}
+	}
+	slog.SetDefault(slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: l})}))
+
+	gin.DebugPrintFunc = func(format string, values ...any) {
+		slog.Debug(strings.TrimSpace(fmt.Sprintf(format, values...)))
+	}
+	return nil
+}
+
+// contextHandler adds the request ID and trace of a record's context to the
+// record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns ctx carrying id as the ID of the request it serves.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID ctx carries, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Fault logs err, a failed store call a client was told about as f: at
+// error level when the fault is the server's, at info level otherwise.
+func Fault(ctx context.Context, f *store.Fault, err error) {
+	level := slog.LevelInfo
+	if f.Status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	slog.Log(ctx, level, "store call failed", "code", f.Code, "error", err)
+}
@@ -0,0 +1,114 @@
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nicholasraynes/northwind-api/internal/auth"
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// RequestIDHeader carries a request's ID in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+// RowsKey is the gin.Context key under which handlers record how many rows
+// they returned, for the request log.
+const RowsKey = "rows"
+
+// maxRequestID bounds the request IDs accepted from callers.
+const maxRequestID = 128
+
+// Middleware gives each request an ID, taken from its X-Request-ID header
+// when that is a sensible one and generated otherwise, and echoes it in the
+// response. Once the request is answered it logs one line with its route,
+// status, latency, caller, filters and rows returned. A request made in
+// process, such as an MCP tool call, keeps the ID on its context.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		id := RequestID(ctx)
+		if id == "" {
+			if id = c.GetHeader(RequestIDHeader); !validRequestID(id) {
+				id = newRequestID()
+			}
+			ctx = WithRequestID(ctx, id)
+			c.Request = c.Request.WithContext(ctx)
+		}
+		c.Header(RequestIDHeader, id)
+
+		start := time.Now()
+		c.Next()
+
+		// Handlers replace the request context as they learn more about it.
+		ctx = c.Request.Context()
+		status := c.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if p, ok := auth.FromContext(ctx); ok {
+			attrs = append(attrs, slog.String("principal", p.Subject))
+		}
+		if f, ok := filter.FromContext(ctx); ok {
+			attrs = append(attrs, slog.Any("filters", f.Echo()))
+		}
+		if rows, ok := c.Get(RowsKey); ok {
+			attrs = append(attrs, slog.Any("rows", rows))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.LogAttrs(ctx, level, "request", attrs...)
+	}
+}
+
+// validRequestID reports whether id is short and made of characters that
+// are safe to echo in a header and a log.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestID {
+		return false
+	}
+	for _, ch := range id {
+		switch {
+		case 'a' <= ch && ch <= 'z', 'A' <= ch && ch <= 'Z', '0' <= ch && ch <= '9':
+		case ch == '-' || ch == '_' || ch == '.' || ch == ':' || ch == '/' || ch == '+' || ch == '=':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Recovery answers a request whose handler panicked with a 500 and logs the
+// panic with its stack, tied to the request.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, err any) {
+		slog.ErrorContext(c.Request.Context(), "handler panicked", "panic", err, "stack", string(debug.Stack()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "internal server error",
+			"code":  store.CodeInternal,
+		})
+	})
+}
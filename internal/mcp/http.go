@@ -0,0 +1,229 @@
+package mcp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sessionHeader     = "Mcp-Session-Id"
+	keepAliveInterval = 15 * time.Second
+	// sessionTTL is how long a session may sit idle before it is forgotten
+	// and its client has to initialize again.
+	sessionTTL = 30 * time.Minute
+	// maxSessions bounds the session table. Past it, the session idle the
+	// longest is dropped to make room.
+	maxSessions = 10000
+)
+
+// sessions tracks the live session IDs and when each was last used.
+type sessions struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+	ttl  time.Duration
+	max  int
+	now  func() time.Time
+}
+
+func newSessions() *sessions {
+	return &sessions{seen: map[string]time.Time{}, ttl: sessionTTL, max: maxSessions, now: time.Now}
+}
+
+func (s *sessions) create() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	id := hex.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for old, t := range s.seen {
+		if now.Sub(t) > s.ttl {
+			delete(s.seen, old)
+		}
+	}
+	if len(s.seen) >= s.max {
+		idlest, since := "", now
+		for old, t := range s.seen {
+			if !t.After(since) {
+				idlest, since = old, t
+			}
+		}
+		delete(s.seen, idlest)
+	}
+	s.seen[id] = now
+	return id
+}
+
+// touch marks the session id as used and reports whether it is live. An
+// expired session is forgotten.
+func (s *sessions) touch(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.seen[id]
+	if !ok {
+		return false
+	}
+	now := s.now()
+	if now.Sub(t) > s.ttl {
+		delete(s.seen, id)
+		return false
+	}
+	s.seen[id] = now
+	return true
+}
+
+func (s *sessions) remove(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.seen[id]
+	delete(s.seen, id)
+	return ok
+}
+
+// ServeHTTP implements the MCP streamable HTTP transport: POST carries
+// JSON-RPC messages, GET opens a server-sent event stream and DELETE ends a
+// session. Only an initialize request may come without a session; an
+// unknown or expired one answers 404, telling the client to initialize
+// again.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	id := r.Header.Get(sessionHeader)
+	if id != "" && !s.sessions.touch(id) {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		s.servePost(w, r, id)
+	case http.MethodGet:
+		if id == "" {
+			http.Error(w, sessionHeader+" required", http.StatusBadRequest)
+			return
+		}
+		s.serveStream(w, r, id)
+	case http.MethodDelete:
+		if id == "" {
+			http.Error(w, sessionHeader+" required", http.StatusBadRequest)
+			return
+		}
+		s.sessions.remove(id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) servePost(w http.ResponseWriter, r *http.Request, id string) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		http.Error(w, "cannot read request body", http.StatusBadRequest)
+		return
+	}
+
+	if id == "" {
+		if !isInitialize(body) {
+			http.Error(w, sessionHeader+" required", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set(sessionHeader, s.sessions.create())
+	}
+
+	reply := s.handle(r.Context(), body)
+	if reply == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if wantsEventStream(r) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "event: message\ndata: %s\n\n", reply)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(reply)
+}
+
+// serveStream holds an SSE stream open for server-initiated messages. The
+// server currently has none to send, so it only emits keep-alive comments
+// until the client goes away, keeping session id alive meanwhile.
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request, id string) {
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		http.Error(w, "Accept must include text/event-stream", http.StatusNotAcceptable)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if !s.sessions.touch(id) {
+				return
+			}
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// wantsEventStream reports whether the client only accepts SSE replies.
+// Clients that accept both get plain JSON.
+func wantsEventStream(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "text/event-stream") && !strings.Contains(accept, "application/json")
+}
+
+func isInitialize(body []byte) bool {
+	var req request
+	if json.Unmarshal(body, &req) != nil {
+		return false
+	}
+	return req.Method == "initialize"
+}
+
+// sameOrigin guards against DNS rebinding: browser requests must come from the
+// host they are addressed to. Non-browser clients send no Origin header.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return u.Host == r.Host
+}
@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	initialize = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`
+	ping       = `{"jsonrpc":"2.0","id":2,"method":"ping"}`
+)
+
+// post sends body to s, in session id when it is not empty.
+func post(s *Server, id, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	r.Header.Set("Accept", "application/json, text/event-stream")
+	if id != "" {
+		r.Header.Set(sessionHeader, id)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+func TestSessionRequired(t *testing.T) {
+	s := newTestServer(&fakeStore{})
+
+	if w := post(s, "", ping); w.Code != http.StatusBadRequest {
+		t.Errorf("ping without a session: status %d, want 400", w.Code)
+	}
+	if w := post(s, "feed", ping); w.Code != http.StatusNotFound {
+		t.Errorf("ping in an unknown session: status %d, want 404", w.Code)
+	}
+
+	w := post(s, "", initialize)
+	id := w.Header().Get(sessionHeader)
+	if w.Code != http.StatusOK || id == "" {
+		t.Fatalf("initialize: status %d, session %q", w.Code, id)
+	}
+	if w := post(s, id, ping); w.Code != http.StatusOK {
+		t.Errorf("ping in session: status %d, want 200", w.Code)
+	}
+
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(method, "/mcp", nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s without a session: status %d, want 400", method, w.Code)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodDelete, "/mcp", nil)
+	r.Header.Set(sessionHeader, id)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("DELETE: status %d, want 204", w.Code)
+	}
+	if w := post(s, id, ping); w.Code != http.StatusNotFound {
+		t.Errorf("ping after DELETE: status %d, want 404", w.Code)
+	}
+}
+
+func TestSessionsExpire(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &sessions{seen: map[string]time.Time{}, ttl: time.Hour, max: 3, now: func() time.Time { return now }}
+
+	idle := s.create()
+	now = now.Add(40 * time.Minute)
+	busy := s.create()
+	now = now.Add(40 * time.Minute)
+	if s.touch(idle) {
+		t.Error("session idle past the TTL is still live")
+	}
+	if !s.touch(busy) {
+		t.Error("session used within the TTL expired")
+	}
+	if len(s.seen) != 1 {
+		t.Errorf("%d sessions kept, want 1", len(s.seen))
+	}
+
+	// Expired sessions are swept when a new one is created, even if never
+	// touched again.
+	stale := s.create()
+	now = now.Add(2 * time.Hour)
+	s.create()
+	if _, ok := s.seen[stale]; ok {
+		t.Error("expired session kept after create")
+	}
+}
+
+func TestSessionsBounded(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &sessions{seen: map[string]time.Time{}, ttl: time.Hour, max: 3, now: func() time.Time { return now }}
+
+	var ids []string
+	for range 3 {
+		ids = append(ids, s.create())
+		now = now.Add(time.Minute)
+	}
+	s.touch(ids[0])
+	now = now.Add(time.Minute)
+
+	for range 10 {
+		s.create()
+		now = now.Add(time.Second)
+	}
+	if len(s.seen) != 3 {
+		t.Errorf("%d sessions kept, want 3", len(s.seen))
+	}
+	if _, ok := s.seen[ids[1]]; ok {
+		t.Error("the idlest session survived")
+	}
+}
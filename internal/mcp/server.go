@@ -0,0 +1,390 @@
+// Package mcp exposes the REST routes as Model Context Protocol tools.
+//
+// Every handlers.Route becomes one tool whose input schema is built from the
+// query parameters the handler reads. A tool call is dispatched through the
+// same http.Handler that serves the REST API, so MCP clients get exactly the
+// response the HTTP endpoint would return.
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/nicholasraynes/northwind-api/internal/auth"
+	"github.com/nicholasraynes/northwind-api/internal/handlers"
+)
+
+// ProtocolVersion is the newest MCP revision this server implements.
+const ProtocolVersion = "2025-06-18"
+
+var supportedVersions = []string{ProtocolVersion, "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Tool is the MCP description of a single route.
+type Tool struct {
+	Name        string         `json:"name"`
+	Title       string         `json:"title,omitempty"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+type tool struct {
+	Tool
+	route handlers.Route
+}
+
+// Server answers MCP requests by invoking the REST handler for each tool.
+type Server struct {
+	handler  http.Handler
+	tools    []tool
+	byName   map[string]tool
+	sessions *sessions
+}
+
+// NewServer builds one tool per GET route and dispatches calls to h.
+func NewServer(routes []handlers.Route, h http.Handler) *Server {
+	s := &Server{
+		handler:  h,
+		byName:   map[string]tool{},
+		sessions: newSessions(),
+	}
+	for _, rt := range routes {
+		// Key management stays off MCP, so a leaked agent session cannot
+		// mint keys.
+		if rt.Method != http.MethodGet || rt.Scope == auth.Admin {
+			continue
+		}
+		t := tool{
+			Tool: Tool{
+				Name:        toolName(rt.Name),
+				Title:       rt.Summary,
+				Description: rt.Description,
+				InputSchema: inputSchema(rt.Params),
+			},
+			route: rt,
+		}
+		s.tools = append(s.tools, t)
+		s.byName[t.Name] = t
+	}
+	return s
+}
+
+// Tools returns the tool descriptions in registration order.
+func (s *Server) Tools() []Tool {
+	out := make([]Tool, 0, len(s.tools))
+	for _, t := range s.tools {
+		out = append(out, t.Tool)
+	}
+	return out
+}
+
+// toolName turns an operation id such as "getTopCustomers" into
+// "get_top_customers". An acronym stays one word, so "getCustomerLTV" becomes
+// "get_customer_ltv" and "createAPIKey" "create_api_key".
+func toolName(op string) string {
+	rs := []rune(op)
+	var b strings.Builder
+	for i, r := range rs {
+		if unicode.IsUpper(r) {
+			// A word starts after a lower case letter, or at the last
+			// capital of an acronym followed by a lower case word.
+			if i > 0 && (!unicode.IsUpper(rs[i-1]) || i+1 < len(rs) && unicode.IsLower(rs[i+1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func inputSchema(params []handlers.Param) map[string]any {
+	props := map[string]any{}
+	required := []string{}
+	for _, p := range params {
+		props[p.Name] = map[string]any{
+			"type":        p.Type,
+			"description": p.Description,
+		}
+		if p.In == handlers.InPath {
+			required = append(required, p.Name)
+		}
+	}
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// handle processes a single JSON-RPC message or batch and returns the encoded
+// reply, or nil when the input contained only notifications.
+func (s *Server) handle(ctx context.Context, raw []byte) []byte {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil
+	}
+
+	if raw[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(raw, &batch); err != nil {
+			return encode(errorResponse(nil, codeParseError, "invalid JSON"))
+		}
+		replies := []response{}
+		for _, msg := range batch {
+			if resp := s.dispatch(ctx, msg); resp != nil {
+				replies = append(replies, *resp)
+			}
+		}
+		if len(replies) == 0 {
+			return nil
+		}
+		return encode(replies)
+	}
+
+	if resp := s.dispatch(ctx, raw); resp != nil {
+		return encode(resp)
+	}
+	return nil
+}
+
+func (s *Server) dispatch(ctx context.Context, raw json.RawMessage) *response {
+	var req request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return errorResponse(nil, codeParseError, "invalid JSON")
+	}
+
+	// Responses from the client carry no method; we never send requests, so
+	// there is nothing to match them against.
+	if req.Method == "" {
+		if len(req.ID) == 0 {
+			return errorResponse(nil, codeInvalidRequest, "missing method")
+		}
+		return nil
+	}
+	if req.JSONRPC != "2.0" {
+		return errorResponse(req.ID, codeInvalidRequest, "jsonrpc must be \"2.0\"")
+	}
+
+	result, rerr := s.call(ctx, req.Method, req.Params)
+
+	// Notifications never get a reply, even on error.
+	if len(req.ID) == 0 {
+		return nil
+	}
+	if rerr != nil {
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: rerr}
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func (s *Server) call(ctx context.Context, method string, params json.RawMessage) (any, *rpcError) {
+	switch method {
+	case "initialize":
+		return s.initialize(params)
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": s.Tools()}, nil
+	case "tools/call":
+		return s.callTool(ctx, params)
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + method}
+}
+
+func (s *Server) initialize(params json.RawMessage) (any, *rpcError) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "invalid initialize params"}
+		}
+	}
+
+	version := ProtocolVersion
+	for _, v := range supportedVersions {
+		if v == p.ProtocolVersion {
+			version = v
+			break
+		}
+	}
+
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities": map[string]any{
+			"tools": map[string]any{"listChanged": false},
+		},
+		"serverInfo": map[string]any{
+			"name":    "northwind-api",
+			"version": "1.0.0",
+		},
+		"instructions": "Read-only access to the Northwind dataset. Every tool accepts optional filters and returns {filters, count, total_count, limit, offset, next_cursor, has_more, data}; while has_more is true, pass next_cursor back as cursor to fetch the next page.",
+	}, nil
+}
+
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) (any, *rpcError) {
+	var p struct {
+		Name      string         `json:"name"`
+		Arguments map[string]any `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "invalid tools/call params"}
+	}
+
+	t, ok := s.byName[p.Name]
+	if !ok {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + p.Name}
+	}
+
+	query, err := queryValues(t.route.Params, p.Arguments)
+	if err != nil {
+		return toolError(err.Error()), nil
+	}
+
+	target, err := expandPath(t.route, query)
+	if err != nil {
+		return toolError(err.Error()), nil
+	}
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return toolError(err.Error()), nil
+	}
+	req.Header.Set("Accept", "application/json")
+
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+
+	body := rec.Body.Bytes()
+	result := map[string]any{
+		"content": []map[string]any{{"type": "text", "text": string(body)}},
+		"isError": rec.Code >= http.StatusBadRequest,
+	}
+	var structured map[string]any
+	if json.Unmarshal(body, &structured) == nil {
+		result["structuredContent"] = structured
+	}
+	return result, nil
+}
+
+// queryValues converts typed tool arguments back into the query string the
+// handler expects, rejecting arguments the route does not declare.
+func queryValues(params []handlers.Param, args map[string]any) (url.Values, error) {
+	declared := map[string]handlers.Param{}
+	for _, p := range params {
+		declared[p.Name] = p
+	}
+
+	q := url.Values{}
+	for name, v := range args {
+		p, ok := declared[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown argument %q", name)
+		}
+		if v == nil {
+			continue
+		}
+		switch val := v.(type) {
+		case string:
+			if p.Type == handlers.ParamInteger {
+				if _, err := strconv.Atoi(val); err != nil {
+					return nil, fmt.Errorf("argument %q must be an integer", name)
+				}
+			}
+			q.Set(name, val)
+		case float64:
+			if p.Type == handlers.ParamInteger && val != float64(int64(val)) {
+				return nil, fmt.Errorf("argument %q must be an integer", name)
+			}
+			q.Set(name, strconv.FormatFloat(val, 'f', -1, 64))
+		case bool:
+			q.Set(name, strconv.FormatBool(val))
+		default:
+			return nil, fmt.Errorf("argument %q must be a %s", name, p.Type)
+		}
+	}
+	return q, nil
+}
+
+// expandPath substitutes the route's path parameters, moving them out of
+// query.
+func expandPath(rt handlers.Route, query url.Values) (string, error) {
+	path := rt.Path
+	for _, p := range rt.Params {
+		if p.In != handlers.InPath {
+			continue
+		}
+		v := query.Get(p.Name)
+		if v == "" {
+			return "", fmt.Errorf("argument %q is required", p.Name)
+		}
+		path = strings.Replace(path, ":"+p.Name, url.PathEscape(v), 1)
+		query.Del(p.Name)
+	}
+	return path, nil
+}
+
+func toolError(msg string) map[string]any {
+	return map[string]any{
+		"content": []map[string]any{{"type": "text", "text": msg}},
+		"isError": true,
+	}
+}
+
+func errorResponse(id json.RawMessage, code int, msg string) *response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: msg}}
+}
+
+func encode(v any) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return []byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32603,"message":"internal error"}}`)
+	}
+	return b
+}
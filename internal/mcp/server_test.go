@@ -0,0 +1,248 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nicholasraynes/northwind-api/internal/handlers"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+func TestToolName(t *testing.T) {
+	for op, want := range map[string]string{
+		"getCustomers":         "get_customers",
+		"getTopCustomers":      "get_top_customers",
+		"getSalesByYear":       "get_sales_by_year",
+		"health":               "health",
+		"GetCustomer":          "get_customer",
+		"getCustomerLTV":       "get_customer_ltv",
+		"createAPIKey":         "create_api_key",
+		"listOrderDetailsByID": "list_order_details_by_id",
+	} {
+		if got := toolName(op); got != want {
+			t.Errorf("toolName(%q) = %q, want %q", op, got, want)
+		}
+	}
+}
+
+var params = []handlers.Param{
+	{Name: "id", In: handlers.InPath, Type: handlers.ParamInteger},
+	{Name: "limit", Type: handlers.ParamInteger},
+	{Name: "country", Type: handlers.ParamString},
+	{Name: "discontinued", Type: handlers.ParamBoolean},
+	{Name: "min_price", Type: handlers.ParamNumber},
+}
+
+func TestQueryValues(t *testing.T) {
+	if _, err := queryValues(params, map[string]any{"region": nil}); err == nil {
+		t.Error("undeclared argument region accepted")
+	}
+
+	q, err := queryValues(params, map[string]any{
+		"id":           float64(7),
+		"limit":        "25",
+		"country":      "Germany",
+		"discontinued": false,
+		"min_price":    12.5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := url.Values{
+		"id":           {"7"},
+		"limit":        {"25"},
+		"country":      {"Germany"},
+		"discontinued": {"false"},
+		"min_price":    {"12.5"},
+	}
+	if q.Encode() != want.Encode() {
+		t.Errorf("queryValues = %s, want %s", q.Encode(), want.Encode())
+	}
+
+	for _, args := range []map[string]any{
+		{"limit": 2.5},
+		{"limit": "ten"},
+		{"limit": "2.5"},
+		{"country": []any{"Germany"}},
+		{"country": map[string]any{}},
+		{"unknown": "x"},
+	} {
+		if _, err := queryValues(params, args); err == nil {
+			t.Errorf("queryValues(%v) accepted", args)
+		}
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	rt := handlers.Route{Path: "/orders/:id/details", Params: params}
+
+	q := url.Values{"id": {"10248"}, "limit": {"5"}}
+	path, err := expandPath(rt, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/orders/10248/details" {
+		t.Errorf("path = %q", path)
+	}
+	if q.Has("id") || q.Get("limit") != "5" {
+		t.Errorf("query after expanding = %v, want only limit", q)
+	}
+
+	// Path values are escaped, so an argument cannot reach another route.
+	if path, _ := expandPath(rt, url.Values{"id": {"../admin"}}); path != "/orders/..%2Fadmin/details" {
+		t.Errorf("path = %q", path)
+	}
+
+	if _, err := expandPath(rt, url.Values{}); err == nil {
+		t.Error("missing path argument accepted")
+	}
+}
+
+// fakeStore answers customer reads from memory and records the queries it
+// was asked. Every other method panics.
+type fakeStore struct {
+	store.Store
+	customers []models.Customer
+	queries   []store.Query
+}
+
+func (s *fakeStore) ListCustomers(ctx context.Context, q store.Query) (store.Rows[models.Customer], error) {
+	s.queries = append(s.queries, q)
+	return &sliceRows[models.Customer]{rows: s.customers, total: len(s.customers)}, nil
+}
+
+func (s *fakeStore) GetCustomer(ctx context.Context, id string) (models.Customer, error) {
+	for _, c := range s.customers {
+		if c.CustomerID == id {
+			return c, nil
+		}
+	}
+	return models.Customer{}, store.ErrNotFound
+}
+
+type sliceRows[T any] struct {
+	rows  []T
+	total int
+	i     int
+}
+
+func (r *sliceRows[T]) Next() bool {
+	r.i++
+	return r.i <= len(r.rows)
+}
+func (r *sliceRows[T]) Row() T       { return r.rows[r.i-1] }
+func (r *sliceRows[T]) Total() int   { return r.total }
+func (r *sliceRows[T]) Err() error   { return nil }
+func (r *sliceRows[T]) Close() error { return nil }
+
+// newTestServer serves the REST routes over st without authentication and
+// returns an MCP server dispatching to them.
+func newTestServer(st store.Store) *Server {
+	gin.SetMode(gin.TestMode)
+	routes := handlers.New(st).Routes()
+	r := gin.New()
+	for _, rt := range routes {
+		r.Handle(rt.Method, rt.Path, rt.Handler)
+	}
+	return NewServer(routes, r)
+}
+
+// rpc sends one JSON-RPC request to s and decodes the reply into a response
+// whose result is left raw.
+func rpc(t *testing.T, s *Server, method string, params any) (json.RawMessage, *rpcError) {
+	t.Helper()
+	msg, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	var reply struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if err := json.Unmarshal(s.handle(context.Background(), msg), &reply); err != nil {
+		t.Fatal(err)
+	}
+	return reply.Result, reply.Error
+}
+
+type callResult struct {
+	Content []struct {
+		Text string `json:"text"`
+	} `json:"content"`
+	IsError           bool           `json:"isError"`
+	StructuredContent map[string]any `json:"structuredContent"`
+}
+
+func TestToolsCall(t *testing.T) {
+	st := &fakeStore{customers: []models.Customer{
+		{CustomerID: "ALFKI", CompanyName: "Alfreds Futterkiste", Country: "Germany"},
+	}}
+	s := newTestServer(st)
+
+	raw, rerr := rpc(t, s, "tools/call", map[string]any{
+		"name":      "get_customers",
+		"arguments": map[string]any{"country": "Germany", "limit": 10},
+	})
+	if rerr != nil {
+		t.Fatal(rerr.Message)
+	}
+	var res callResult
+	if err := json.Unmarshal(raw, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError {
+		t.Fatalf("tool error: %s", res.Content[0].Text)
+	}
+	if len(st.queries) != 1 {
+		t.Fatalf("%d store calls, want 1", len(st.queries))
+	}
+	if q := st.queries[0]; q.Page.Limit != 10 || q.Filters.Echo()["country"] != "Germany" {
+		t.Errorf("store got limit %d and filters %v", q.Page.Limit, q.Filters.Echo())
+	}
+	body := res.StructuredContent
+	if body["total_count"] != float64(1) || body["has_more"] != false {
+		t.Errorf("structured content = %v", body)
+	}
+	if data, _ := body["data"].([]any); len(data) != 1 || !strings.Contains(res.Content[0].Text, "Alfreds Futterkiste") {
+		t.Errorf("content = %s", res.Content[0].Text)
+	}
+
+	// A path argument addresses one record, and a miss is a tool error,
+	// not a protocol error.
+	raw, rerr = rpc(t, s, "tools/call", map[string]any{"name": "get_customer", "arguments": map[string]any{"id": "ALFKI"}})
+	if rerr != nil || json.Unmarshal(raw, &res) != nil || res.IsError || !strings.Contains(res.Content[0].Text, `"ALFKI"`) {
+		t.Errorf("get_customer ALFKI = %s, %v", raw, rerr)
+	}
+	res = callResult{}
+	raw, rerr = rpc(t, s, "tools/call", map[string]any{"name": "get_customer", "arguments": map[string]any{"id": "NOONE"}})
+	if rerr != nil || json.Unmarshal(raw, &res) != nil || !res.IsError {
+		t.Errorf("get_customer NOONE = %s, %v", raw, rerr)
+	}
+
+	// Bad arguments never reach the store.
+	raw, _ = rpc(t, s, "tools/call", map[string]any{"name": "get_customers", "arguments": map[string]any{"limit": 1.5}})
+	if json.Unmarshal(raw, &res) != nil || !res.IsError || len(st.queries) != 1 {
+		t.Errorf("limit 1.5 = %s after %d store calls", raw, len(st.queries))
+	}
+
+	if _, rerr := rpc(t, s, "tools/call", map[string]any{"name": "drop_tables"}); rerr == nil || rerr.Code != codeInvalidParams {
+		t.Errorf("unknown tool = %v, want invalid params", rerr)
+	}
+}
+
+func TestToolsExcludeWritesAndAdmin(t *testing.T) {
+	s := newTestServer(&fakeStore{})
+	for _, tl := range s.Tools() {
+		rt := s.byName[tl.Name].route
+		if rt.Method != http.MethodGet || strings.HasPrefix(rt.Path, "/admin") {
+			t.Errorf("tool %s exposes %s %s", tl.Name, rt.Method, rt.Path)
+		}
+	}
+	if _, ok := s.byName["get_customers"]; !ok {
+		t.Error("no get_customers tool")
+	}
+}
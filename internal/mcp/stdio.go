@@ -0,0 +1,36 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"io"
+)
+
+// maxMessageSize bounds a single newline-delimited JSON-RPC message on stdio.
+const maxMessageSize = 4 << 20
+
+// ServeStdio reads newline-delimited JSON-RPC messages from in and writes the
+// replies to out until in is exhausted or ctx is cancelled. Nothing else may
+// write to out while this runs.
+func (s *Server) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+
+	w := bufio.NewWriter(out)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		reply := s.handle(ctx, scanner.Bytes())
+		if reply == nil {
+			continue
+		}
+		if _, err := w.Write(append(reply, '\n')); err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,121 @@
+// Package metrics exposes Prometheus metrics for the API: requests and
+// their latency per route, the time and rows of the SQL statements each
+// route runs, and the state of the connection pool.
+//
+// Metrics are registered with the default Prometheus registry, which
+// Handler serves along with the Go runtime and process metrics.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unmatched labels requests no route matched, so stray paths cannot grow
+// the number of series.
+const unmatched = "unmatched"
+
+var (
+	requests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by route template, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time to answer HTTP requests, by route template and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Time SQL statements take, including reading their rows, by the route that ran them.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route"})
+
+	queryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_query_errors_total",
+		Help: "SQL statements that failed, by the route that ran them.",
+	}, []string{"route"})
+
+	rowsScanned = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_rows_scanned_total",
+		Help: "Rows read by queries, or affected by other statements, by the route that ran them.",
+	}, []string{"route"})
+)
+
+type routeKey struct{}
+
+// WithRoute returns ctx naming route, a template such as
+// /analytics/customer-retention, as the one statements run under ctx are
+// for.
+func WithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// RouteFrom returns the route ctx names, or "none" for work done outside a
+// request, such as migrations.
+func RouteFrom(ctx context.Context) string {
+	if route, ok := ctx.Value(routeKey{}).(string); ok {
+		return route
+	}
+	return "none"
+}
+
+// Middleware counts and times each request by its route template, and
+// names the route on the request context for the statements it runs.
+// Requests made in process, such as MCP tool calls, are counted under the
+// route they reach.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = unmatched
+		}
+		c.Request = c.Request.WithContext(WithRoute(c.Request.Context(), route))
+
+		start := time.Now()
+		c.Next()
+
+		requestDuration.WithLabelValues(route, c.Request.Method).Observe(time.Since(start).Seconds())
+		requests.WithLabelValues(route, c.Request.Method, strconv.Itoa(c.Writer.Status())).Inc()
+	}
+}
+
+// DB is a db.Observer recording each statement's time, rows and failure
+// against the route on its context.
+var DB dbObserver
+
+type dbObserver struct{}
+
+func (dbObserver) Statement(ctx context.Context, query string) func(int64, error) {
+	route := RouteFrom(ctx)
+	start := time.Now()
+	return func(rows int64, err error) {
+		queryDuration.WithLabelValues(route).Observe(time.Since(start).Seconds())
+		rowsScanned.WithLabelValues(route).Add(float64(rows))
+		if err != nil {
+			queryErrors.WithLabelValues(route).Inc()
+		}
+	}
+}
+
+// Pool exports the statistics of db's connection pool: open, in-use and
+// idle connections, and how often and how long callers waited for one.
+func Pool(db *sql.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, "northwind"))
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
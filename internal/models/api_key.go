@@ -0,0 +1,51 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// APIKey describes a key callers authenticate with. The key itself is only
+// returned when it is created or rotated; Prefix tells keys apart.
+type APIKey struct {
+	KeyID      int        `json:"key_id" db:"key_id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"prefix"`
+	Scopes     Scopes     `json:"scopes" db:"scopes"`
+	RowScope   RowScope   `json:"row_scope" db:"row_scope"` // Confines the key to one employee's or customer's rows
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	RotatedAt  *time.Time `json:"rotated_at" db:"rotated_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"` // Updated at most once a minute
+	RevokedAt  *time.Time `json:"revoked_at" db:"revoked_at"`
+}
+
+// APIKeyInput is the body of POST /admin/keys.
+type APIKeyInput struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1"`
+	// RowScope, when set, limits the key to reading one employee's or
+	// customer's rows, e.g. {"employee_id": 5}.
+	RowScope RowScope `json:"row_scope"`
+}
+
+// Scopes are the permissions held by a caller. The database keeps them as
+// one space-separated string, as OAuth writes them.
+type Scopes []string
+
+func (s *Scopes) Scan(v any) error {
+	switch v := v.(type) {
+	case string:
+		*s = strings.Fields(v)
+	case []byte:
+		*s = strings.Fields(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into Scopes", v)
+	}
+	return nil
+}
+
+func (s Scopes) Value() (driver.Value, error) {
+	return strings.Join(s, " "), nil
+}
@@ -1,15 +1,17 @@
 package models
 
+// Customer is both the row returned by the customer endpoints and the body
+// accepted when writing one; the binding tags hold the column limits.
 type Customer struct {
-	CustomerID   string  `json:"customer_id" db:"customer_id"`
-	CompanyName  string  `json:"company_name" db:"company_name"`
-	ContactName  string  `json:"contact_name" db:"contact_name"`
-	ContactTitle string  `json:"contact_title" db:"contact_title"`
-	Address      string  `json:"address" db:"address"`
-	City         string  `json:"city" db:"city"`
-	Region       *string `json:"region" db:"region"`
-	PostalCode   *string `json:"postal_code" db:"postal_code"`
-	Country      string  `json:"country" db:"country"`
-	Phone        string  `json:"phone" db:"phone"`
-	Fax          *string `json:"fax" db:"fax"`
+	CustomerID   string  `json:"customer_id" db:"customer_id" binding:"required,max=5"`
+	CompanyName  string  `json:"company_name" db:"company_name" binding:"required,max=40"`
+	ContactName  string  `json:"contact_name" db:"contact_name" binding:"max=30"`
+	ContactTitle string  `json:"contact_title" db:"contact_title" binding:"max=30"`
+	Address      string  `json:"address" db:"address" binding:"max=60"`
+	City         string  `json:"city" db:"city" binding:"max=15"`
+	Region       *string `json:"region" db:"region" binding:"omitempty,max=15"`
+	PostalCode   *string `json:"postal_code" db:"postal_code" binding:"omitempty,max=10"`
+	Country      string  `json:"country" db:"country" binding:"max=15"`
+	Phone        string  `json:"phone" db:"phone" binding:"max=24"`
+	Fax          *string `json:"fax" db:"fax" binding:"omitempty,max=24"`
 }
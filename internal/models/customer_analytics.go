@@ -40,3 +40,9 @@ type CustomerRetention struct {
 	ActiveYears    int    `json:"active_years" db:"active_years"`
 	RepeatCustomer bool   `json:"repeat_customer" db:"repeat_customer"`
 }
+
+type RetentionSummary struct {
+	TotalCustomers  int     `json:"total_customers" db:"total_customers"`
+	RepeatCustomers int     `json:"repeat_customers" db:"repeat_customers"`
+	RetentionRate   float64 `json:"retention_rate" db:"retention_rate"`
+}
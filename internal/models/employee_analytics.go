@@ -20,14 +20,24 @@ type ShippingCosts struct {
 	Year           *int    `json:"year,omitempty" db:"year"`
 }
 
+// DeliveryTimes describes how quickly one group of orders shipped. Delivery
+// days run from order_date to shipped_date and only count shipped orders, so
+// they and OnTimeRate are null for a group with none. An unshipped order is
+// open until its required_date passes and overdue after that. The shipper and
+// employee columns not used for grouping are null.
 type DeliveryTimes struct {
-	ShipperName     string  `json:"shipper_name" db:"shipper_name"`
-	EmployeeName    string  `json:"employee_name" db:"employee_name"`
-	TotalOrders     int     `json:"total_orders" db:"total_orders"`
-	AvgDeliveryDays float64 `json:"avg_delivery_days" db:"avg_delivery_days"`
-	MaxDeliveryDays float64 `json:"max_delivery_days" db:"max_delivery_days"`
-	MinDeliveryDays float64 `json:"min_delivery_days" db:"min_delivery_days"`
-	LateShipments   int     `json:"late_shipments" db:"late_shipments"`
-	OnTimeRate      float64 `json:"on_time_rate" db:"on_time_rate"`
-	Year            *int    `json:"year,omitempty" db:"year"`
+	ShipperID       *int     `json:"shipper_id" db:"shipper_id"`
+	ShipperName     *string  `json:"shipper_name" db:"shipper_name"`
+	EmployeeID      *int     `json:"employee_id" db:"employee_id"`
+	EmployeeName    *string  `json:"employee_name" db:"employee_name"`
+	TotalOrders     int      `json:"total_orders" db:"total_orders"`
+	ShippedOrders   int      `json:"shipped_orders" db:"shipped_orders"`
+	AvgDeliveryDays *float64 `json:"avg_delivery_days" db:"avg_delivery_days"`
+	MaxDeliveryDays *float64 `json:"max_delivery_days" db:"max_delivery_days"`
+	MinDeliveryDays *float64 `json:"min_delivery_days" db:"min_delivery_days"`
+	LateShipments   int      `json:"late_shipments" db:"late_shipments"`
+	OnTimeRate      *float64 `json:"on_time_rate" db:"on_time_rate"`
+	OpenOrders      int      `json:"open_orders" db:"open_orders"`
+	OverdueOrders   int      `json:"overdue_orders" db:"overdue_orders"`
+	Year            *int     `json:"year,omitempty" db:"year"`
 }
@@ -1,10 +1,13 @@
 package models
 
+// APISchema is an OpenAPI 3.0 document.
 type APISchema struct {
-	OpenAPI string             `json:"openapi"`
-	Info    APIInfo            `json:"info"`
-	Tags    []APITag           `json:"tags,omitempty"`
-	Paths   map[string]APIPath `json:"paths"`
+	OpenAPI    string             `json:"openapi"`
+	Info       APIInfo            `json:"info"`
+	Servers    []APIServer        `json:"servers,omitempty"`
+	Tags       []APITag           `json:"tags,omitempty"`
+	Paths      map[string]APIPath `json:"paths"`
+	Components APIComponents      `json:"components"`
 }
 
 type APIInfo struct {
@@ -13,13 +16,17 @@ type APIInfo struct {
 	Description string `json:"description"`
 }
 
+type APIServer struct {
+	URL string `json:"url"`
+}
+
 type APITag struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 }
-type APIPath struct {
-	Get *APIEndpoint `json:"get,omitempty"`
-}
+
+// APIPath holds the operations on one path, keyed by lower-case method.
+type APIPath map[string]*APIEndpoint
 
 type APIEndpoint struct {
 	Tags        []string               `json:"tags,omitempty"`
@@ -27,17 +34,63 @@ type APIEndpoint struct {
 	Summary     string                 `json:"summary"`
 	Description string                 `json:"description"`
 	Parameters  []APIParameter         `json:"parameters,omitempty"`
+	RequestBody *APIRequestBody        `json:"requestBody,omitempty"`
 	Responses   map[string]APIResponse `json:"responses"`
+	// Security lists the alternative schemes that authenticate the
+	// operation; none means it is public.
+	Security []map[string][]string `json:"security,omitempty"`
+	// RequiredScope is the scope the caller's credentials must hold.
+	RequiredScope string `json:"x-required-scope,omitempty"`
 }
 
 type APIParameter struct {
-	Name        string `json:"name"`
-	In          string `json:"in"`
-	Description string `json:"description,omitempty"`
-	Required    bool   `json:"required,omitempty"`
-	SchemaType  string `json:"schemaType"`
+	Name        string           `json:"name"`
+	In          string           `json:"in"`
+	Description string           `json:"description,omitempty"`
+	Required    bool             `json:"required,omitempty"`
+	Schema      *APISchemaObject `json:"schema"`
+}
+
+type APIRequestBody struct {
+	Required bool                    `json:"required"`
+	Content  map[string]APIMediaType `json:"content"`
 }
 
 type APIResponse struct {
-	Description string `json:"description"`
+	Description string                  `json:"description"`
+	Content     map[string]APIMediaType `json:"content,omitempty"`
+}
+
+type APIMediaType struct {
+	Schema *APISchemaObject `json:"schema"`
+}
+
+// APISchemaObject is the OpenAPI 3.0 subset of JSON Schema describing a
+// parameter or a body. Ref, when set, points into the components instead.
+type APISchemaObject struct {
+	Ref                  string                      `json:"$ref,omitempty"`
+	Type                 string                      `json:"type,omitempty"`
+	Format               string                      `json:"format,omitempty"`
+	Description          string                      `json:"description,omitempty"`
+	Nullable             bool                        `json:"nullable,omitempty"`
+	MaxLength            int                         `json:"maxLength,omitempty"`
+	AllOf                []*APISchemaObject          `json:"allOf,omitempty"`
+	Items                *APISchemaObject            `json:"items,omitempty"`
+	Properties           map[string]*APISchemaObject `json:"properties,omitempty"`
+	Required             []string                    `json:"required,omitempty"`
+	AdditionalProperties any                         `json:"additionalProperties,omitempty"`
+}
+
+type APIComponents struct {
+	Schemas         map[string]*APISchemaObject  `json:"schemas"`
+	SecuritySchemes map[string]APISecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type APISecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
 }
@@ -20,3 +20,23 @@ type Order struct {
 	ShipPostal   *string    `json:"ship_postal" db:"ship_postal_code"`
 	ShipCountry  string     `json:"ship_country" db:"ship_country"`
 }
+
+// OrderInput is the body of POST, PUT and PATCH /orders. Nil fields are left
+// unchanged by PATCH and cleared by PUT. Dates are YYYY-MM-DD. Details are
+// only read when creating an order.
+type OrderInput struct {
+	CustomerID   *string            `json:"customer_id"`
+	EmployeeID   *int               `json:"employee_id"`
+	OrderDate    *string            `json:"order_date"`
+	RequiredDate *string            `json:"required_date"`
+	ShippedDate  *string            `json:"shipped_date"`
+	ShipVia      *int               `json:"ship_via"`
+	Freight      *float64           `json:"freight"`
+	ShipName     *string            `json:"ship_name"`
+	ShipAddress  *string            `json:"ship_address"`
+	ShipCity     *string            `json:"ship_city"`
+	ShipRegion   *string            `json:"ship_region"`
+	ShipPostal   *string            `json:"ship_postal"`
+	ShipCountry  *string            `json:"ship_country"`
+	Details      []OrderDetailInput `json:"details,omitempty"`
+}
@@ -11,3 +11,13 @@ type OrderDetail struct {
 	Discount      float64 `json:"discount" db:"discount"`
 	ExtendedPrice float64 `json:"extended_price" db:"extended_price"`
 }
+
+// OrderDetailInput is one line item in a write to /orders or
+// /orders/{id}/details. UnitPrice defaults to the product's list price and
+// Discount to zero; PATCH leaves nil fields unchanged.
+type OrderDetailInput struct {
+	ProductID int      `json:"product_id"`
+	Quantity  *int     `json:"quantity"`
+	UnitPrice *float64 `json:"unit_price"`
+	Discount  *float64 `json:"discount"`
+}
@@ -1,14 +1,17 @@
 package models
 
+// Product is both the row returned by the product endpoints and the body
+// accepted when writing one. The server assigns product_id and ignores the
+// supplier and category names, which follow from their ids.
 type Product struct {
 	ProductID       int     `json:"product_id" db:"product_id"`
-	ProductName     string  `json:"product_name" db:"product_name"`
-	SupplierID      int     `json:"supplier_id" db:"supplier_id"`
+	ProductName     string  `json:"product_name" db:"product_name" binding:"required,max=40"`
+	SupplierID      int     `json:"supplier_id" db:"supplier_id" binding:"required,gte=1"`
 	SupplierName    string  `json:"supplier_name" db:"supplier_name"`
-	CategoryID      int     `json:"category_id" db:"category_id"`
+	CategoryID      int     `json:"category_id" db:"category_id" binding:"required,gte=1"`
 	CategoryName    string  `json:"category_name" db:"category_name"`
-	QuantityPerUnit string  `json:"quantity_per_unit" db:"quantity_per_unit"`
-	UnitPrice       float64 `json:"unit_price" db:"unit_price"`
-	UnitsInStock    int     `json:"units_in_stock" db:"units_in_stock"`
+	QuantityPerUnit string  `json:"quantity_per_unit" db:"quantity_per_unit" binding:"max=20"`
+	UnitPrice       float64 `json:"unit_price" db:"unit_price" binding:"gte=0"`
+	UnitsInStock    int     `json:"units_in_stock" db:"units_in_stock" binding:"gte=0"`
 	Discontinued    bool    `json:"discontinued" db:"discontinued"`
 }
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// RowScope confines a caller to the rows of one employee or customer, as
+// dimension=value pairs such as {"employee_id": "5"}. A caller confined on
+// several dimensions sees only rows matching every one.
+type RowScope map[string]string
+
+// ParseRowScope reads a RowScope written as comma-separated dimension=value
+// pairs, such as "employee_id=5".
+func ParseRowScope(s string) (RowScope, error) {
+	rs := RowScope{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		dim, value, ok := strings.Cut(pair, "=")
+		if !ok || dim == "" || value == "" {
+			return nil, fmt.Errorf("row scope %q is not dimension=value", pair)
+		}
+		rs[strings.TrimSpace(dim)] = strings.TrimSpace(value)
+	}
+	if len(rs) == 0 {
+		return nil, nil
+	}
+	return rs, nil
+}
+
+// String writes rs as ParseRowScope reads it, in dimension order.
+func (rs RowScope) String() string {
+	pairs := []string{}
+	for _, dim := range slices.Sorted(maps.Keys(rs)) {
+		pairs = append(pairs, dim+"="+rs[dim])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// UnmarshalJSON accepts numbers as well as strings, so clients can send
+// {"employee_id": 5}.
+func (rs *RowScope) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*rs = nil
+		return nil
+	}
+	out := RowScope{}
+	for dim, v := range raw {
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			out[dim] = s
+			continue
+		}
+		var n json.Number
+		if err := json.Unmarshal(v, &n); err != nil {
+			return fmt.Errorf("row scope %s must be a string or a number", dim)
+		}
+		out[dim] = n.String()
+	}
+	*rs = out
+	return nil
+}
+
+func (rs *RowScope) Scan(v any) error {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		*rs = nil
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into RowScope", v)
+	}
+	parsed, err := ParseRowScope(s)
+	if err != nil {
+		return err
+	}
+	*rs = parsed
+	return nil
+}
+
+func (rs RowScope) Value() (driver.Value, error) {
+	if len(rs) == 0 {
+		return nil, nil
+	}
+	return rs.String(), nil
+}
@@ -1,7 +1,30 @@
 package models
 
+import "time"
+
 type SalesSummary struct {
 	GroupKey   string  `json:"group_key" db:"group_key"` // Value dependent on the summary type
 	TotalSales float64 `json:"total_sales" db:"total_sales"`
 	OrderCount int     `json:"order_count" db:"order_count"`
 }
+
+// SalesPeriod is one period of a sales time series, optionally split by a
+// dimension. Periods without sales are reported with zero totals.
+type SalesPeriod struct {
+	Period      string    `json:"period" db:"period"` // e.g. 1997-03-14, 1997-W11, 1997-03, 1997-Q1 or 1997
+	PeriodStart time.Time `json:"period_start" db:"period_start"`
+	GroupKey    *string   `json:"group_key" db:"group_key"` // Value of the split dimension; null when not split
+	TotalSales  float64   `json:"total_sales" db:"total_sales"`
+	OrderCount  int       `json:"order_count" db:"order_count"`
+}
+
+// SalesComparison is a SalesSummary row set against the same group in an
+// earlier period. PctGrowth is null when the group sold nothing before.
+type SalesComparison struct {
+	GroupKey        string   `json:"group_key" db:"group_key"`
+	TotalSales      float64  `json:"total_sales" db:"total_sales"`
+	OrderCount      int      `json:"order_count" db:"order_count"`
+	PriorTotalSales float64  `json:"prior_total_sales" db:"prior_total_sales"`
+	Change          float64  `json:"change" db:"change"`
+	PctGrowth       *float64 `json:"pct_growth" db:"pct_growth"` // Percent, e.g. 12.5 for 12.5% growth
+}
@@ -1,13 +1,15 @@
 package models
 
+// Supplier is both the row returned by the supplier endpoints and the body
+// accepted when writing one. The server assigns supplier_id.
 type Supplier struct {
 	SupplierID   int     `json:"supplier_id" db:"supplier_id"`
-	CompanyName  string  `json:"company_name" db:"company_name"`
-	ContactName  string  `json:"contact_name" db:"contact_name"`
-	ContactTitle string  `json:"contact_title" db:"contact_title"`
-	City         string  `json:"city" db:"city"`
-	Country      string  `json:"country" db:"country"`
-	Phone        string  `json:"phone" db:"phone"`
-	Fax          *string `json:"fax" db:"fax"`
+	CompanyName  string  `json:"company_name" db:"company_name" binding:"required,max=40"`
+	ContactName  string  `json:"contact_name" db:"contact_name" binding:"max=30"`
+	ContactTitle string  `json:"contact_title" db:"contact_title" binding:"max=30"`
+	City         string  `json:"city" db:"city" binding:"max=15"`
+	Country      string  `json:"country" db:"country" binding:"max=15"`
+	Phone        string  `json:"phone" db:"phone" binding:"max=24"`
+	Fax          *string `json:"fax" db:"fax" binding:"omitempty,max=24"`
 	HomePage     *string `json:"homepage" db:"homepage"`
 }
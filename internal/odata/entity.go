@@ -0,0 +1,207 @@
+package odata
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nicholasraynes/northwind-api/internal/fieldset"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// Namespace qualifies the entity types in $metadata.
+const Namespace = "Northwind"
+
+// EDM primitive types used by the models.
+const (
+	edmString         = "Edm.String"
+	edmInt32          = "Edm.Int32"
+	edmDouble         = "Edm.Double"
+	edmBoolean        = "Edm.Boolean"
+	edmDateTimeOffset = "Edm.DateTimeOffset"
+)
+
+// property is one structural property of an entity type: a JSON field of its
+// model.
+type property struct {
+	name      string
+	edmType   string
+	nullable  bool
+	maxLength int
+}
+
+// entitySet is a collection served at /odata/<name>, whose entity type is
+// derived from a model.
+type entitySet struct {
+	name       string
+	typeName   string
+	key        []string
+	properties []property
+	// query runs a store list method and returns the page of rows trimmed to
+	// q.Fields, how many there are and the size of the whole filtered
+	// collection.
+	query func(ctx context.Context, q store.Query) (rows any, count int, total int, err error)
+}
+
+// newEntitySet describes the collection name of model T, keyed by the given
+// JSON fields and read through list.
+func newEntitySet[T any](name, typeName string, key []string, list func(ctx context.Context, q store.Query) (store.Rows[T], error)) entitySet {
+	return entitySet{
+		name:       name,
+		typeName:   typeName,
+		key:        key,
+		properties: propertiesOf[T](),
+		query: func(ctx context.Context, q store.Query) (any, int, int, error) {
+			// The total travels with the rows, so a request for none reads
+			// one and drops it.
+			none := q.Page.Limit == 0
+			if none {
+				q.Page.Limit = 1
+			}
+			rows, err := list(ctx, q)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+			res, err := store.Collect(rows)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+			if none {
+				res.Rows = res.Rows[:0]
+			}
+			return fieldset.Project(res.Rows, q.Fields), len(res.Rows), res.Total, nil
+		},
+	}
+}
+
+// property returns the property called name.
+func (s entitySet) property(name string) (property, bool) {
+	for _, p := range s.properties {
+		if p.name == name {
+			return p, true
+		}
+	}
+	return property{}, false
+}
+
+// propertiesOf lists a property for every JSON field of model T. Pointer
+// fields are nullable, and a max=N binding on a string sets its MaxLength.
+func propertiesOf[T any]() []property {
+	types := map[string]reflect.StructField{}
+	for _, f := range reflect.VisibleFields(reflect.TypeFor[T]()) {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		types[name] = f
+	}
+
+	props := []property{}
+	for _, name := range fieldset.Of[T]() {
+		f := types[name]
+		t := f.Type
+		p := property{name: name, nullable: t.Kind() == reflect.Pointer}
+		if p.nullable {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.String:
+			p.edmType = edmString
+			for _, rule := range strings.Split(f.Tag.Get("binding"), ",") {
+				if n, ok := strings.CutPrefix(rule, "max="); ok {
+					p.maxLength, _ = strconv.Atoi(n)
+				}
+			}
+		case reflect.Bool:
+			p.edmType = edmBoolean
+		case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64:
+			p.edmType = edmInt32
+		case reflect.Float32, reflect.Float64:
+			p.edmType = edmDouble
+		default:
+			if t != reflect.TypeFor[time.Time]() {
+				panic(fmt.Sprintf("odata: no EDM type for %s", t))
+			}
+			p.edmType = edmDateTimeOffset
+		}
+		props = append(props, p)
+	}
+	return props
+}
+
+// CSDL XML elements of the $metadata document.
+type (
+	edmx struct {
+		XMLName xml.Name     `xml:"edmx:Edmx"`
+		Version string       `xml:"Version,attr"`
+		Xmlns   string       `xml:"xmlns:edmx,attr"`
+		Data    dataServices `xml:"edmx:DataServices"`
+	}
+	dataServices struct {
+		Schema schema `xml:"Schema"`
+	}
+	schema struct {
+		Xmlns     string       `xml:"xmlns,attr"`
+		Namespace string       `xml:"Namespace,attr"`
+		Types     []entityType `xml:"EntityType"`
+		Container container    `xml:"EntityContainer"`
+	}
+	entityType struct {
+		Name       string         `xml:"Name,attr"`
+		Key        []propertyRef  `xml:"Key>PropertyRef"`
+		Properties []csdlProperty `xml:"Property"`
+	}
+	propertyRef struct {
+		Name string `xml:"Name,attr"`
+	}
+	csdlProperty struct {
+		Name      string `xml:"Name,attr"`
+		Type      string `xml:"Type,attr"`
+		Nullable  string `xml:"Nullable,attr,omitempty"`
+		MaxLength int    `xml:"MaxLength,attr,omitempty"`
+	}
+	container struct {
+		Name string    `xml:"Name,attr"`
+		Sets []csdlSet `xml:"EntitySet"`
+	}
+	csdlSet struct {
+		Name       string `xml:"Name,attr"`
+		EntityType string `xml:"EntityType,attr"`
+	}
+)
+
+// metadata renders the CSDL document describing sets.
+func metadata(sets []entitySet) ([]byte, error) {
+	doc := edmx{
+		Version: "4.0",
+		Xmlns:   "http://docs.oasis-open.org/odata/ns/edmx",
+		Data: dataServices{Schema: schema{
+			Xmlns:     "http://docs.oasis-open.org/odata/ns/edm",
+			Namespace: Namespace,
+			Container: container{Name: "Container"},
+		}},
+	}
+	s := &doc.Data.Schema
+	for _, set := range sets {
+		t := entityType{Name: set.typeName}
+		for _, k := range set.key {
+			t.Key = append(t.Key, propertyRef{Name: k})
+		}
+		for _, p := range set.properties {
+			cp := csdlProperty{Name: p.name, Type: p.edmType, MaxLength: p.maxLength}
+			if !p.nullable {
+				cp.Nullable = "false"
+			}
+			t.Properties = append(t.Properties, cp)
+		}
+		s.Types = append(s.Types, t)
+		s.Container.Sets = append(s.Container.Sets, csdlSet{Name: set.name, EntityType: Namespace + "." + set.typeName})
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
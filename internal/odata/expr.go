@@ -0,0 +1,383 @@
+package odata
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+)
+
+// The $filter grammar accepted here is the common subset of OData v4:
+//
+//	expr    = and { "or" and }
+//	and     = unary { "and" unary }
+//	unary   = "not" unary | "(" expr ")" | match | compare | boolean property
+//	match   = ( "contains" | "startswith" | "endswith" ) "(" operand "," string ")"
+//	compare = operand ( op literal | "in" "(" literal { "," literal } ")" )
+//	operand = property | ( "tolower" | "toupper" ) "(" property ")"
+//	op      = "eq" | "ne" | "gt" | "ge" | "lt" | "le"
+//
+// Literals are 'quoted strings' (with '' for a quote), integers, decimals,
+// true, false, null, dates (1997-01-01) and date-times with an offset.
+
+// maxDepth bounds the nesting of parentheses, not and case functions, so a
+// hostile $filter cannot recurse the parser without end.
+const maxDepth = 32
+
+var compareOps = map[string]filter.CompareOp{
+	"eq": filter.Eq, "ne": filter.Ne, "gt": filter.Gt, "ge": filter.Ge, "lt": filter.Lt, "le": filter.Le,
+}
+
+var matchKinds = map[string]filter.MatchKind{
+	"contains": filter.ContainsText, "startswith": filter.StartsWith, "endswith": filter.EndsWith,
+}
+
+var folds = map[string]filter.Fold{"tolower": filter.Lower, "toupper": filter.Upper}
+
+type tokenKind int
+
+const (
+	tokEnd tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits a $filter expression into tokens. Numbers, dates and
+// date-times are read as one run and told apart by the parser.
+func lex(s string) ([]token, error) {
+	toks := []token{}
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(' || c == ')' || c == ',':
+			toks = append(toks, token{tokPunct, string(c), i})
+			i++
+		case c == '\'':
+			var b strings.Builder
+			j := i + 1
+			for {
+				if j >= len(s) {
+					return nil, fmt.Errorf("unterminated string at position %d", i)
+				}
+				if s[j] == '\'' {
+					if j+1 < len(s) && s[j+1] == '\'' {
+						b.WriteByte('\'')
+						j += 2
+						continue
+					}
+					break
+				}
+				b.WriteByte(s[j])
+				j++
+			}
+			toks = append(toks, token{tokString, b.String(), i})
+			i = j + 1
+		case c == '-' || unicode.IsDigit(c):
+			j := i + 1
+			for j < len(s) && strings.ContainsRune("0123456789.-+:TZeE", rune(s[j])) {
+				j++
+			}
+			toks = append(toks, token{tokNumber, s[i:j], i})
+			i = j
+		case c == '_' || unicode.IsLetter(c):
+			j := i + 1
+			for j < len(s) && (s[j] == '_' || unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			toks = append(toks, token{tokIdent, s[i:j], i})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", c, i)
+		}
+	}
+	return append(toks, token{tokEnd, "", len(s)}), nil
+}
+
+// parser turns the tokens of a $filter into a filter.Expr over the
+// properties of one entity set, checking every literal against the type of
+// the property it is compared with.
+type parser struct {
+	set   entitySet
+	toks  []token
+	i     int
+	depth int
+}
+
+// parseFilter parses a $filter expression for set.
+func parseFilter(set entitySet, s string) (filter.Expr, error) {
+	toks, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{set: set, toks: toks}
+	e, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEnd {
+		return nil, p.unexpected(t)
+	}
+	return e, nil
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEnd {
+		p.i++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the identifier or punctuation s.
+func (p *parser) accept(s string) bool {
+	if t := p.peek(); (t.kind == tokIdent || t.kind == tokPunct) && t.text == s {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) error {
+	if !p.accept(s) {
+		return fmt.Errorf("expected %q at position %d", s, p.peek().pos)
+	}
+	return nil
+}
+
+// nest enters one more level of nesting at t, failing beyond maxDepth. The
+// caller leaves it with p.depth--.
+func (p *parser) nest(t token) error {
+	if p.depth++; p.depth > maxDepth {
+		return fmt.Errorf("expression nested deeper than %d levels at position %d", maxDepth, t.pos)
+	}
+	return nil
+}
+
+func (p *parser) unexpected(t token) error {
+	if t.kind == tokEnd {
+		return fmt.Errorf("unexpected end of expression")
+	}
+	return fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+}
+
+func (p *parser) or() (filter.Expr, error) {
+	left, err := p.and()
+	for err == nil && p.accept("or") {
+		var right filter.Expr
+		if right, err = p.and(); err == nil {
+			left = filter.Or{Left: left, Right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) and() (filter.Expr, error) {
+	left, err := p.unary()
+	for err == nil && p.accept("and") {
+		var right filter.Expr
+		if right, err = p.unary(); err == nil {
+			left = filter.And{Left: left, Right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) unary() (filter.Expr, error) {
+	if err := p.nest(p.peek()); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+
+	if p.accept("not") {
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return filter.Not{X: x}, nil
+	}
+	if p.accept("(") {
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(")")
+	}
+
+	t := p.peek()
+	if t.kind != tokIdent {
+		return nil, p.unexpected(t)
+	}
+	if kind, ok := matchKinds[t.text]; ok {
+		p.next()
+		return p.match(kind)
+	}
+
+	ref, prop, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	if p.accept("in") {
+		return p.oneOf(ref, prop)
+	}
+	if op, ok := compareOps[p.peek().text]; ok && p.peek().kind == tokIdent {
+		p.next()
+		v, err := p.literal(prop)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil && op != filter.Eq && op != filter.Ne {
+			return nil, fmt.Errorf("null can only be compared with eq or ne")
+		}
+		return filter.Compare{Ref: ref, Op: op, Value: v}, nil
+	}
+	if prop.edmType == edmBoolean && ref.Fold == filter.NoFold {
+		return filter.Compare{Ref: ref, Op: filter.Eq, Value: true}, nil
+	}
+	return nil, p.unexpected(p.peek())
+}
+
+// match parses the arguments of contains, startswith or endswith.
+func (p *parser) match(kind filter.MatchKind) (filter.Expr, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	ref, prop, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	if prop.edmType != edmString {
+		return nil, fmt.Errorf("%s is not a string property", prop.name)
+	}
+	if err := p.expect(","); err != nil {
+		return nil, err
+	}
+	t := p.next()
+	if t.kind != tokString {
+		return nil, fmt.Errorf("expected a string at position %d", t.pos)
+	}
+	return filter.Match{Ref: ref, Kind: kind, Value: t.text}, p.expect(")")
+}
+
+// oneOf parses the list after "in".
+func (p *parser) oneOf(ref filter.Ref, prop property) (filter.Expr, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	values := []any{}
+	for {
+		v, err := p.literal(prop)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			return nil, fmt.Errorf("null cannot be used with in")
+		}
+		values = append(values, v)
+		if !p.accept(",") {
+			break
+		}
+	}
+	return filter.OneOf{Ref: ref, Values: values}, p.expect(")")
+}
+
+// operand parses a property, optionally wrapped in tolower or toupper.
+func (p *parser) operand() (filter.Ref, property, error) {
+	t := p.next()
+	if t.kind != tokIdent {
+		return filter.Ref{}, property{}, p.unexpected(t)
+	}
+	if fold, ok := folds[t.text]; ok {
+		if err := p.nest(t); err != nil {
+			return filter.Ref{}, property{}, err
+		}
+		defer func() { p.depth-- }()
+		if err := p.expect("("); err != nil {
+			return filter.Ref{}, property{}, err
+		}
+		ref, prop, err := p.operand()
+		if err != nil {
+			return ref, prop, err
+		}
+		if prop.edmType != edmString || ref.Fold != filter.NoFold {
+			return ref, prop, fmt.Errorf("%s needs a string property", t.text)
+		}
+		ref.Fold = fold
+		return ref, prop, p.expect(")")
+	}
+
+	prop, ok := p.set.property(t.text)
+	if !ok {
+		return filter.Ref{}, property{}, fmt.Errorf("%s has no property %q", p.set.name, t.text)
+	}
+	return filter.Ref{Field: prop.name}, prop, nil
+}
+
+// literal parses a value for prop, converting it to prop's Go type. null
+// yields a nil value.
+func (p *parser) literal(prop property) (any, error) {
+	t := p.next()
+	if t.kind == tokIdent && t.text == "null" {
+		return nil, nil
+	}
+	mismatch := fmt.Errorf("%s needs %s, not %q", prop.name, describe[prop.edmType], t.text)
+
+	switch prop.edmType {
+	case edmString:
+		if t.kind == tokString {
+			return t.text, nil
+		}
+	case edmBoolean:
+		if t.kind == tokIdent && (t.text == "true" || t.text == "false") {
+			return t.text == "true", nil
+		}
+	case edmInt32:
+		if t.kind == tokNumber {
+			if n, err := strconv.ParseInt(t.text, 10, 32); err == nil {
+				return n, nil
+			}
+		}
+	case edmDouble:
+		if t.kind == tokNumber {
+			if f, err := strconv.ParseFloat(t.text, 64); err == nil {
+				return f, nil
+			}
+		}
+	case edmDateTimeOffset:
+		if t.kind == tokNumber {
+			if d, err := time.Parse(time.DateOnly, t.text); err == nil {
+				return d, nil
+			}
+			if d, err := time.Parse(time.RFC3339, t.text); err == nil {
+				return d, nil
+			}
+		}
+	}
+	if t.kind == tokEnd {
+		return nil, p.unexpected(t)
+	}
+	return nil, mismatch
+}
+
+// describe names the literals each EDM type accepts, for error messages.
+var describe = map[string]string{
+	edmString:         "a 'quoted' string",
+	edmBoolean:        "true or false",
+	edmInt32:          "an integer",
+	edmDouble:         "a number",
+	edmDateTimeOffset: "a date such as 1997-01-01 or a date-time such as 1997-01-01T00:00:00Z",
+}
@@ -0,0 +1,146 @@
+package odata
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+)
+
+// thing has a property of every EDM type the models use.
+type thing struct {
+	ID      int        `json:"id"`
+	Name    string     `json:"name" binding:"max=40"`
+	Region  *string    `json:"region"`
+	Price   float64    `json:"price"`
+	Active  bool       `json:"active"`
+	Shipped *time.Time `json:"shipped"`
+}
+
+var things = entitySet{name: "Things", typeName: "Thing", key: []string{"id"}, properties: propertiesOf[thing]()}
+
+func ref(field string) filter.Ref { return filter.Ref{Field: field} }
+
+func TestLex(t *testing.T) {
+	toks, err := lex(`name eq 'O''Brien' and(price ge -1.5e3)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, tok := range toks {
+		got = append(got, tok.text)
+	}
+	want := []string{"name", "eq", "O'Brien", "and", "(", "price", "ge", "-1.5e3", ")", ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lex = %q, want %q", got, want)
+	}
+
+	for _, bad := range []string{`name eq 'open`, `price gt 1 ; drop`, `name eq "x"`, `id eq $1`} {
+		if _, err := lex(bad); err == nil {
+			t.Errorf("lex(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	date := time.Date(1997, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want filter.Expr
+	}{
+		{"id eq 5", filter.Compare{Ref: ref("id"), Op: filter.Eq, Value: int64(5)}},
+		{"price lt 2.5", filter.Compare{Ref: ref("price"), Op: filter.Lt, Value: 2.5}},
+		{"price ge 3", filter.Compare{Ref: ref("price"), Op: filter.Ge, Value: 3.0}},
+		{"region eq null", filter.Compare{Ref: ref("region"), Op: filter.Eq}},
+		{"region ne null", filter.Compare{Ref: ref("region"), Op: filter.Ne}},
+		{"active", filter.Compare{Ref: ref("active"), Op: filter.Eq, Value: true}},
+		{"active eq false", filter.Compare{Ref: ref("active"), Op: filter.Eq, Value: false}},
+		{"shipped gt 1997-01-01", filter.Compare{Ref: ref("shipped"), Op: filter.Gt, Value: date}},
+		{"shipped le 1997-01-01T00:00:00Z", filter.Compare{Ref: ref("shipped"), Op: filter.Le, Value: date}},
+		{"name in ('a', 'b''c')", filter.OneOf{Ref: ref("name"), Values: []any{"a", "b'c"}}},
+		{"contains(tolower(name), 'ab')", filter.Match{Ref: filter.Ref{Field: "name", Fold: filter.Lower}, Kind: filter.ContainsText, Value: "ab"}},
+		{"startswith(name,'A')", filter.Match{Ref: ref("name"), Kind: filter.StartsWith, Value: "A"}},
+		{"toupper(name) eq 'X'", filter.Compare{Ref: filter.Ref{Field: "name", Fold: filter.Upper}, Op: filter.Eq, Value: "X"}},
+		{
+			// and binds tighter than or.
+			"id eq 1 or id eq 2 and not active",
+			filter.Or{
+				Left: filter.Compare{Ref: ref("id"), Op: filter.Eq, Value: int64(1)},
+				Right: filter.And{
+					Left:  filter.Compare{Ref: ref("id"), Op: filter.Eq, Value: int64(2)},
+					Right: filter.Not{X: filter.Compare{Ref: ref("active"), Op: filter.Eq, Value: true}},
+				},
+			},
+		},
+		{
+			"(id eq 1 or id eq 2) and endswith(name, 'z')",
+			filter.And{
+				Left: filter.Or{
+					Left:  filter.Compare{Ref: ref("id"), Op: filter.Eq, Value: int64(1)},
+					Right: filter.Compare{Ref: ref("id"), Op: filter.Eq, Value: int64(2)},
+				},
+				Right: filter.Match{Ref: ref("name"), Kind: filter.EndsWith, Value: "z"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got, err := parseFilter(things, tt.in)
+		if err != nil {
+			t.Errorf("parseFilter(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseFilter(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseFilterRejects(t *testing.T) {
+	tests := []struct {
+		in, err string
+	}{
+		{"", "unexpected end"},
+		{"secret eq 1", `Things has no property "secret"`},
+		{"id eq 'x'", "id needs an integer"},
+		{"id eq 99999999999", "id needs an integer"},
+		{"price eq true", "price needs a number"},
+		{"name eq 5", "name needs a 'quoted' string"},
+		{"active eq 1", "active needs true or false"},
+		{"shipped eq 1997-13-01", "shipped needs a date"},
+		{"region gt null", "null can only be compared with eq or ne"},
+		{"id in (1, null)", "null cannot be used with in"},
+		{"contains(id, '1')", "id is not a string property"},
+		{"contains(name, 1)", "expected a string"},
+		{"tolower(id) eq 1", "tolower needs a string property"},
+		{"tolower(toupper(name)) eq 'a'", "tolower needs a string property"},
+		{"name", "unexpected end"},
+		{"id eq 1 id eq 2", `unexpected "id" at position 8`},
+		{"(id eq 1", `expected ")"`},
+		{"id eq", "unexpected end"},
+		{"not", "unexpected end"},
+		{strings.Repeat("(", 1000) + "active" + strings.Repeat(")", 1000), "nested deeper than"},
+		{strings.Repeat("not ", 100000) + "active", "nested deeper than"},
+		{strings.Repeat("tolower(", 1000) + "name" + strings.Repeat(")", 1000) + " eq 'a'", "nested deeper than"},
+	}
+	for _, tt := range tests {
+		_, err := parseFilter(things, tt.in)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			name := tt.in
+			if len(name) > 40 {
+				name = name[:40] + "…"
+			}
+			t.Errorf("parseFilter(%q) = %v, want an error containing %q", name, err, tt.err)
+		}
+	}
+}
+
+// TestParseFilterNesting checks that the depth limit leaves room for the
+// nesting real filters use.
+func TestParseFilterNesting(t *testing.T) {
+	in := strings.Repeat("(not ", 10) + "active" + strings.Repeat(")", 10)
+	if _, err := parseFilter(things, in); err != nil {
+		t.Errorf("ten levels: %v", err)
+	}
+}
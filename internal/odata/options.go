@@ -0,0 +1,138 @@
+package odata
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nicholasraynes/northwind-api/internal/fieldset"
+	"github.com/nicholasraynes/northwind-api/internal/page"
+	"github.com/nicholasraynes/northwind-api/internal/sorting"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// options are the system query options of one collection request.
+type options struct {
+	store.Query
+	// top is the number of rows the client asked for, or -1 for all.
+	top   int
+	skip  int
+	count bool
+}
+
+// supported lists the system query options this service understands. Any
+// other $-prefixed option is rejected rather than ignored.
+var supported = []string{"$filter", "$select", "$orderby", "$top", "$skip", "$count", "$format"}
+
+// parseOptions reads the system query options in q for set. A page holds at
+// most page.MaxLimit rows; longer results continue at @odata.nextLink.
+func parseOptions(set entitySet, q url.Values) (options, error) {
+	o := options{top: -1}
+	for name := range q {
+		if strings.HasPrefix(name, "$") && !slices.Contains(supported, name) {
+			return o, fmt.Errorf("the query option %s is not supported", name)
+		}
+	}
+	if f := q.Get("$format"); f != "" && f != "json" && !strings.HasPrefix(f, "application/json") {
+		return o, fmt.Errorf("$format must be json")
+	}
+
+	if raw := strings.TrimSpace(q.Get("$filter")); raw != "" {
+		e, err := parseFilter(set, raw)
+		if err != nil {
+			return o, fmt.Errorf("$filter: %w", err)
+		}
+		o.Expr = e
+	}
+
+	if raw := strings.TrimSpace(q.Get("$select")); raw != "" && raw != "*" {
+		sel := fieldset.Set{}
+		for _, name := range strings.Split(raw, ",") {
+			name = strings.TrimSpace(name)
+			if _, ok := set.property(name); !ok {
+				return o, fmt.Errorf("$select: %s has no property %q", set.name, name)
+			}
+			if !slices.Contains(sel, name) {
+				sel = append(sel, name)
+			}
+		}
+		o.Fields = sel
+	}
+
+	if raw := strings.TrimSpace(q.Get("$orderby")); raw != "" {
+		for _, item := range strings.Split(raw, ",") {
+			parts := strings.Fields(item)
+			if len(parts) == 0 || len(parts) > 2 {
+				return o, fmt.Errorf("$orderby: cannot parse %q", strings.TrimSpace(item))
+			}
+			if _, ok := set.property(parts[0]); !ok {
+				return o, fmt.Errorf("$orderby: %s has no property %q", set.name, parts[0])
+			}
+			k := sorting.Key{Field: parts[0]}
+			if len(parts) == 2 {
+				switch parts[1] {
+				case "asc":
+				case "desc":
+					k.Desc = true
+				default:
+					return o, fmt.Errorf("$orderby: direction must be asc or desc, not %q", parts[1])
+				}
+			}
+			o.Sort = append(o.Sort, k)
+		}
+	}
+
+	var err error
+	if o.top, err = count(q, "$top", -1); err != nil {
+		return o, err
+	}
+	if o.skip, err = count(q, "$skip", 0); err != nil {
+		return o, err
+	}
+
+	switch q.Get("$count") {
+	case "", "false":
+	case "true":
+		o.count = true
+	default:
+		return o, fmt.Errorf("$count must be true or false")
+	}
+
+	o.Page = page.Request{Limit: page.MaxLimit, Offset: o.skip}
+	if o.top >= 0 && o.top < page.MaxLimit {
+		o.Page.Limit = o.top
+	}
+	return o, nil
+}
+
+// count reads a non-negative integer option, or def when it is absent.
+func count(q url.Values, name string, def int) (int, error) {
+	raw := q.Get(name)
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", name)
+	}
+	return n, nil
+}
+
+// nextLink returns the URL of the rows after a page of n rows out of total,
+// or "" when the client has all it asked for.
+func (o options) nextLink(u url.URL, n, total int) string {
+	next := o.skip + n
+	remaining := o.top - n
+	if next >= total || n == 0 || (o.top >= 0 && remaining <= 0) {
+		return ""
+	}
+	q := u.Query()
+	q.Set("$skip", strconv.Itoa(next))
+	if o.top >= 0 {
+		q.Set("$top", strconv.Itoa(remaining))
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
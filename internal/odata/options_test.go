@@ -0,0 +1,110 @@
+package odata
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nicholasraynes/northwind-api/internal/fieldset"
+	"github.com/nicholasraynes/northwind-api/internal/page"
+	"github.com/nicholasraynes/northwind-api/internal/sorting"
+)
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		query  string
+		fields fieldset.Set
+		sort   sorting.Order
+		page   page.Request
+		top    int
+		count  bool
+	}{
+		{"", nil, nil, page.Request{Limit: page.MaxLimit}, -1, false},
+		{"$select=*", nil, nil, page.Request{Limit: page.MaxLimit}, -1, false},
+		{"$select=name, id,name", fieldset.Set{"name", "id"}, nil, page.Request{Limit: page.MaxLimit}, -1, false},
+		{"$orderby=price desc,id", nil, sorting.Order{{Field: "price", Desc: true}, {Field: "id"}}, page.Request{Limit: page.MaxLimit}, -1, false},
+		{"$top=20&$skip=40&$count=true", nil, nil, page.Request{Limit: 20, Offset: 40}, 20, true},
+		{"$top=0", nil, nil, page.Request{Limit: 0}, 0, false},
+		{"$top=5000", nil, nil, page.Request{Limit: page.MaxLimit}, 5000, false},
+		{"$format=application/json%3Bodata.metadata%3Dminimal&$count=false", nil, nil, page.Request{Limit: page.MaxLimit}, -1, false},
+		{"other=ignored", nil, nil, page.Request{Limit: page.MaxLimit}, -1, false},
+	}
+	for _, tt := range tests {
+		q, err := url.ParseQuery(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		o, err := parseOptions(things, q)
+		if err != nil {
+			t.Errorf("parseOptions(%q): %v", tt.query, err)
+			continue
+		}
+		if !reflect.DeepEqual(o.Fields, tt.fields) || !reflect.DeepEqual(o.Sort, tt.sort) {
+			t.Errorf("parseOptions(%q): fields %v sort %v, want %v %v", tt.query, o.Fields, o.Sort, tt.fields, tt.sort)
+		}
+		if o.Page.Limit != tt.page.Limit || o.Page.Offset != tt.page.Offset || o.top != tt.top || o.count != tt.count {
+			t.Errorf("parseOptions(%q): page %+v top %d count %v, want %+v %d %v", tt.query, o.Page, o.top, o.count, tt.page, tt.top, tt.count)
+		}
+	}
+}
+
+func TestParseOptionsRejects(t *testing.T) {
+	tests := []struct {
+		query, err string
+	}{
+		{"$expand=Orders", "$expand is not supported"},
+		{"$format=xml", "$format must be json"},
+		{"$filter=id eq", "$filter: unexpected end"},
+		{"$select=id,secret", `$select: Things has no property "secret"`},
+		{"$orderby=secret", `$orderby: Things has no property "secret"`},
+		{"$orderby=id sideways", "direction must be asc or desc"},
+		{"$orderby=id asc extra", "cannot parse"},
+		{"$orderby=id,,name", "cannot parse"},
+		{"$top=-1", "$top must be a non-negative integer"},
+		{"$skip=x", "$skip must be a non-negative integer"},
+		{"$count=yes", "$count must be true or false"},
+	}
+	for _, tt := range tests {
+		q, err := url.ParseQuery(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := parseOptions(things, q); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("parseOptions(%q) = %v, want an error containing %q", tt.query, err, tt.err)
+		}
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	tests := []struct {
+		query    string
+		n, total int
+		want     string // query of the link, or "" for none
+	}{
+		{"", 1000, 2500, "%24skip=1000"},
+		{"$skip=1000", 1000, 2500, "%24skip=2000"},
+		{"$skip=2000", 500, 2500, ""},
+		{"$top=1500", 1000, 2500, "%24skip=1000&%24top=500"},
+		{"$top=1000", 1000, 2500, ""},
+		{"$top=10", 10, 2500, ""},
+		{"$filter=id gt 5", 1000, 1001, "%24filter=id+gt+5&%24skip=1000"},
+		{"", 0, 0, ""},
+	}
+	for _, tt := range tests {
+		q, _ := url.ParseQuery(tt.query)
+		o, err := parseOptions(things, q)
+		if err != nil {
+			t.Fatalf("parseOptions(%q): %v", tt.query, err)
+		}
+		u := url.URL{Scheme: "https", Host: "api.example.com", Path: "/odata/Things", RawQuery: q.Encode()}
+		got := o.nextLink(u, tt.n, tt.total)
+		want := ""
+		if tt.want != "" {
+			want = "https://api.example.com/odata/Things?" + tt.want
+		}
+		if got != want {
+			t.Errorf("nextLink(%q, %d of %d) = %q, want %q", tt.query, tt.n, tt.total, got, want)
+		}
+	}
+}
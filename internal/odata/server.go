@@ -0,0 +1,147 @@
+// Package odata serves the core collections as OData v4 entity sets, for
+// clients such as Power Automate and Copilot Studio connectors that speak
+// OData natively.
+//
+// Customers, Orders, OrderDetails, Products and Suppliers are read through
+// the same store list methods as the REST endpoints. $filter is parsed into a
+// filter.Expr, $select and $orderby into the store's projection and sort
+// order, and $top and $skip into its page, so every request runs the same
+// parameterized SQL. The $metadata document is generated from the models.
+package odata
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/nicholasraynes/northwind-api/internal/logging"
+	"github.com/nicholasraynes/northwind-api/internal/origin"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// Server answers OData requests under one path prefix.
+type Server struct {
+	prefix   string
+	origins  *origin.Resolver
+	sets     []entitySet
+	metadata []byte
+}
+
+// NewServer describes the entity sets over st, served under prefix, e.g.
+// "/odata". Absolute links start at the origin origins resolves.
+func NewServer(st store.Store, prefix string, origins *origin.Resolver) (*Server, error) {
+	sets := []entitySet{
+		newEntitySet("Customers", "Customer", []string{"customer_id"}, st.ListCustomers),
+		newEntitySet("Orders", "Order", []string{"order_id"}, st.ListOrders),
+		newEntitySet("OrderDetails", "OrderDetail", []string{"order_id", "product_id"}, st.ListOrderDetails),
+		newEntitySet("Products", "Product", []string{"product_id"}, st.ListProducts),
+		newEntitySet("Suppliers", "Supplier", []string{"supplier_id"}, st.ListSuppliers),
+	}
+	doc, err := metadata(sets)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{prefix: strings.TrimSuffix(prefix, "/"), origins: origins, sets: sets, metadata: doc}, nil
+}
+
+// ServeHTTP answers the service document at the prefix, the CSDL document
+// at $metadata, each entity set at its name and its size at <name>/$count.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, "NotImplemented", "only GET is supported")
+		return
+	}
+	w.Header().Set("OData-Version", "4.0")
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, s.prefix), "/")
+	switch path {
+	case "":
+		s.serveServiceDocument(w, r)
+		return
+	case "$metadata":
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write(s.metadata)
+		return
+	}
+
+	name, rest, _ := strings.Cut(path, "/")
+	for _, set := range s.sets {
+		if set.name == name && (rest == "" || rest == "$count") {
+			s.serveSet(w, r, set, rest == "$count")
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "NotFound", "no resource at "+r.URL.Path)
+}
+
+// serviceRoot is the absolute URL of the service, ending in a slash.
+func (s *Server) serviceRoot(r *http.Request) string {
+	return s.origins.Of(r) + s.prefix + "/"
+}
+
+func (s *Server) serveServiceDocument(w http.ResponseWriter, r *http.Request) {
+	sets := []map[string]string{}
+	for _, set := range s.sets {
+		sets = append(sets, map[string]string{"name": set.name, "kind": "EntitySet", "url": set.name})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"@odata.context": s.serviceRoot(r) + "$metadata",
+		"value":          sets,
+	})
+}
+
+// serveSet answers a collection request, or with onlyCount its $count.
+func (s *Server) serveSet(w http.ResponseWriter, r *http.Request, set entitySet, onlyCount bool) {
+	o, err := parseOptions(set, r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequest", err.Error())
+		return
+	}
+	if onlyCount {
+		o.Page.Limit = 0
+	}
+
+	rows, n, total, err := set.query(r.Context(), o.Query)
+	if err != nil {
+		f := store.Classify(r.Context(), err)
+		logging.Fault(r.Context(), f, err)
+		writeError(w, f.Status, f.Code, f.Message)
+		return
+	}
+
+	if onlyCount {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(strconv.Itoa(total)))
+		return
+	}
+
+	contextURL := s.serviceRoot(r) + "$metadata#" + set.name
+	if o.Fields != nil {
+		contextURL += "(" + strings.Join(o.Fields, ",") + ")"
+	}
+	body := map[string]any{"@odata.context": contextURL, "value": rows}
+	if o.count {
+		body["@odata.count"] = total
+	}
+	u, _ := url.Parse(s.origins.Of(r) + r.URL.RequestURI())
+	if link := o.nextLink(*u, n, total); link != "" {
+		body["@odata.nextLink"] = link
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json;odata.metadata=minimal")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers with an OData error object.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]any{
+		"error": map[string]string{"code": code, "message": message},
+	})
+}
@@ -0,0 +1,124 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nicholasraynes/northwind-api/internal/models"
+)
+
+var modelsPkg = reflect.TypeFor[models.Customer]().PkgPath()
+
+// schemas derives body schemas from Go types. Named types from the models
+// package become components, referenced wherever they appear; everything
+// else, such as the handlers' response envelopes, is written inline.
+type schemas struct {
+	components map[string]*models.APISchemaObject
+}
+
+// of describes values of t as encoding/json writes them.
+func (s *schemas) of(t reflect.Type) *models.APISchemaObject {
+	if t.Kind() == reflect.Pointer {
+		o := s.of(t.Elem())
+		if o.Ref != "" {
+			// Siblings of $ref are ignored, so a nullable reference is
+			// wrapped.
+			return &models.APISchemaObject{Nullable: true, AllOf: []*models.APISchemaObject{o}}
+		}
+		o.Nullable = true
+		return o
+	}
+
+	switch {
+	case t == reflect.TypeFor[time.Time]():
+		return &models.APISchemaObject{Type: "string", Format: "date-time"}
+	case t.Implements(reflect.TypeFor[json.Marshaler]()):
+		// Custom encodings, such as fieldset.Object, are open.
+		return &models.APISchemaObject{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &models.APISchemaObject{Type: "string"}
+	case reflect.Bool:
+		return &models.APISchemaObject{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &models.APISchemaObject{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &models.APISchemaObject{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return &models.APISchemaObject{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Map:
+		return &models.APISchemaObject{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	case reflect.Interface:
+		return &models.APISchemaObject{}
+	case reflect.Struct:
+		if t.PkgPath() != modelsPkg || t.Name() == "" {
+			return s.object(t, true)
+		}
+		if _, ok := s.components[t.Name()]; !ok {
+			// Reserve the name first, in case t refers to itself.
+			s.components[t.Name()] = nil
+			s.components[t.Name()] = s.object(t, true)
+		}
+		return &models.APISchemaObject{Ref: "#/components/schemas/" + t.Name()}
+	}
+	panic(fmt.Sprintf("openapi: no schema for %s", t))
+}
+
+// object describes the JSON fields of struct t. With required set, the
+// fields the validator requires, through a binding:"required" tag, are
+// required.
+func (s *schemas) object(t reflect.Type, required bool) *models.APISchemaObject {
+	o := &models.APISchemaObject{Type: "object", Properties: map[string]*models.APISchemaObject{}}
+	for _, f := range reflect.VisibleFields(t) {
+		if f.Anonymous || !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		rules := strings.Split(f.Tag.Get("binding"), ",")
+		p := s.of(f.Type)
+		if p.Type == "string" {
+			p.MaxLength = maxLength(rules)
+		}
+		o.Properties[name] = p
+		if required && slices.Contains(rules, "required") {
+			o.Required = append(o.Required, name)
+		}
+	}
+	return o
+}
+
+// partial describes a body of type t whose fields may each be left out, as
+// for PATCH.
+func (s *schemas) partial(t reflect.Type) *models.APISchemaObject {
+	if t.Kind() != reflect.Struct {
+		return s.of(t)
+	}
+	return s.object(t, false)
+}
+
+// maxLength reads the max=N rule among the rules of a binding tag, or 0
+// without one.
+func maxLength(rules []string) int {
+	for _, rule := range rules {
+		if n, ok := strings.CutPrefix(rule, "max="); ok {
+			limit, _ := strconv.Atoi(n)
+			return limit
+		}
+	}
+	return 0
+}
@@ -0,0 +1,59 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/nicholasraynes/northwind-api/internal/handlers"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/origin"
+)
+
+// Server answers with the document and with Swagger UI rendering it.
+type Server struct {
+	doc     models.APISchema
+	origins *origin.Resolver
+}
+
+// NewServer describes routes.
+func NewServer(routes []handlers.Route, origins *origin.Resolver) *Server {
+	return &Server{doc: Build(routes), origins: origins}
+}
+
+// ServeSpec answers with the document, naming the origin the request reached,
+// as origins resolves it, as its server. When there is none, the server is
+// the document's own.
+func (s *Server) ServeSpec(w http.ResponseWriter, r *http.Request) {
+	doc := s.doc
+	server := s.origins.Of(r)
+	if server == "" {
+		server = "/"
+	}
+	doc.Servers = []models.APIServer{{URL: server}}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(doc)
+}
+
+// ServeDocs answers with Swagger UI, loaded from a CDN, pointed at the
+// document beside it.
+func (s *Server) ServeDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(docsPage))
+}
+
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Northwind API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: "openapi.json", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`
@@ -0,0 +1,180 @@
+// Package openapi describes the REST endpoints as an OpenAPI 3.0 document,
+// built from the route registry in the handlers package, and serves it at
+// /openapi.json with Swagger UI at /docs.
+//
+// Each operation takes its parameters from the route's Params and its body
+// schemas from the Go types of the route's Body and Response, so the document
+// cannot drift from the handlers.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/nicholasraynes/northwind-api/internal/auth"
+	"github.com/nicholasraynes/northwind-api/internal/export"
+	"github.com/nicholasraynes/northwind-api/internal/handlers"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+)
+
+var info = models.APIInfo{
+	Title:   "Northwind API",
+	Version: "1.0.0",
+	Description: "Customers, orders, products and suppliers of the Northwind dataset, with sales summaries and analytics. " +
+		"Every GET operation is also an MCP tool at /mcp.",
+}
+
+// tags group the operations by the first segment of their path.
+var tags = []struct {
+	segment string
+	tag     models.APITag
+}{
+	{"health", models.APITag{Name: "Health", Description: "Service status"}},
+	{"livez", models.APITag{Name: "Health", Description: "Service status"}},
+	{"readyz", models.APITag{Name: "Health", Description: "Service status"}},
+	{"customers", models.APITag{Name: "Customers", Description: "Customer records"}},
+	{"orders", models.APITag{Name: "Orders", Description: "Orders and their line items"}},
+	{"products", models.APITag{Name: "Products", Description: "Product records"}},
+	{"suppliers", models.APITag{Name: "Suppliers", Description: "Supplier records"}},
+	{"summary", models.APITag{Name: "Summaries", Description: "Sales totals by country, category, employee, year, shipper and period"}},
+	{"query", models.APITag{Name: "Query", Description: "Sales measures aggregated by any dimensions"}},
+	{"analytics", models.APITag{Name: "Analytics", Description: "Customer, product, supplier, employee and shipping analytics"}},
+	{"admin", models.APITag{Name: "Admin", Description: "API key management"}},
+}
+
+// Names of the security schemes.
+const (
+	apiKeyScheme = "apiKey"
+	bearerScheme = "bearer"
+)
+
+var securitySchemes = map[string]models.APISecurityScheme{
+	apiKeyScheme: {
+		Type:        "apiKey",
+		Description: "An API key issued by POST /admin/keys. Each operation requires the scope named by its x-required-scope; admin holds every scope.",
+		Name:        auth.KeyHeader,
+		In:          "header",
+	},
+	bearerScheme: {
+		Type:         "http",
+		Description:  "An OAuth access token, such as one Entra ID issues, when the server is configured with a JWKS. Its scp, scope and roles claims grant scopes.",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+	},
+}
+
+// Build describes routes.
+func Build(routes []handlers.Route) models.APISchema {
+	doc := models.APISchema{
+		OpenAPI:    "3.0.3",
+		Info:       info,
+		Paths:      map[string]models.APIPath{},
+		Components: models.APIComponents{Schemas: map[string]*models.APISchemaObject{}, SecuritySchemes: securitySchemes},
+	}
+	s := &schemas{components: doc.Components.Schemas}
+	s.components["Error"] = s.of(reflect.TypeFor[handlers.ErrorBody]())
+
+	used := map[string]bool{}
+	for _, rt := range routes {
+		tag := tagOf(rt.Path)
+		if !used[tag.Name] {
+			used[tag.Name] = true
+			doc.Tags = append(doc.Tags, tag)
+		}
+
+		path := pathOf(rt.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = models.APIPath{}
+		}
+		doc.Paths[path][strings.ToLower(rt.Method)] = operation(s, rt, tag.Name)
+	}
+	return doc
+}
+
+func operation(s *schemas, rt handlers.Route, tag string) *models.APIEndpoint {
+	op := &models.APIEndpoint{
+		Tags:        []string{tag},
+		OperationID: rt.Name,
+		Summary:     rt.Summary,
+		Description: rt.Description,
+		Responses: map[string]models.APIResponse{
+			"default": {Description: "Error", Content: jsonContent(&models.APISchemaObject{Ref: "#/components/schemas/Error"})},
+		},
+	}
+
+	if rt.Scope != "" {
+		op.Security = []map[string][]string{{apiKeyScheme: {}}, {bearerScheme: {}}}
+		op.RequiredScope = string(rt.Scope)
+	}
+
+	exports := false
+	for _, p := range rt.Params {
+		in := p.In
+		if in == "" {
+			in = handlers.InQuery
+		}
+		op.Parameters = append(op.Parameters, models.APIParameter{
+			Name:        p.Name,
+			In:          in,
+			Description: p.Description,
+			Required:    in == handlers.InPath,
+			Schema:      &models.APISchemaObject{Type: p.Type},
+		})
+		exports = exports || p.Name == export.Param
+	}
+
+	if rt.Body != nil {
+		t := reflect.TypeOf(rt.Body)
+		schema := s.of(t)
+		if rt.Method == http.MethodPatch {
+			schema = s.partial(t)
+		}
+		op.RequestBody = &models.APIRequestBody{Required: true, Content: jsonContent(schema)}
+	}
+
+	status := rt.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	res := models.APIResponse{Description: http.StatusText(status)}
+	if rt.Response != nil {
+		res.Content = jsonContent(s.of(reflect.TypeOf(rt.Response)))
+		if exports {
+			for _, f := range export.Formats[1:] {
+				res.Content[f.MediaType()] = models.APIMediaType{Schema: &models.APISchemaObject{Type: "string", Format: "binary"}}
+			}
+		}
+	}
+	op.Responses[strconv.Itoa(status)] = res
+	return op
+}
+
+func jsonContent(schema *models.APISchemaObject) map[string]models.APIMediaType {
+	return map[string]models.APIMediaType{export.JSON.MediaType(): {Schema: schema}}
+}
+
+// pathOf spells a Gin path in OpenAPI's template syntax: /orders/:id becomes
+// /orders/{id}.
+func pathOf(path string) string {
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		if name, ok := strings.CutPrefix(p, ":"); ok {
+			parts[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// tagOf returns the tag for path. A segment without a declared tag gets one
+// named after it, with no description.
+func tagOf(path string) models.APITag {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	for _, t := range tags {
+		if t.segment == segment {
+			return t.tag
+		}
+	}
+	return models.APITag{Name: strings.ToUpper(segment[:1]) + segment[1:]}
+}
@@ -0,0 +1,125 @@
+// Package origin works out the scheme and host a request reached, for the
+// absolute links the OData service and the OpenAPI document hand back.
+//
+// Behind a reverse proxy the scheme and host the client used arrive in
+// X-Forwarded-Proto and X-Forwarded-Host. Anyone can send those headers, so
+// they are only read from the proxies the operator names; other requests get
+// the connection's own scheme and Host header.
+package origin
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Resolver derives request origins, trusting forwarded headers from a fixed
+// set of peers.
+type Resolver struct {
+	trusted []netip.Prefix
+}
+
+// NewResolver trusts the peers in proxies, each an IP address such as
+// 10.0.0.1 or a CIDR range such as 10.0.0.0/8. With none, forwarded headers
+// are ignored.
+func NewResolver(proxies []string) (*Resolver, error) {
+	res := &Resolver{}
+	for _, p := range proxies {
+		prefix, err := netip.ParsePrefix(p)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(p)
+			if addrErr != nil {
+				return nil, fmt.Errorf("trusted proxy %q is neither an IP address nor a CIDR range", p)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		res.trusted = append(res.trusted, prefix.Masked())
+	}
+	return res, nil
+}
+
+// Of returns the origin r reached, e.g. "https://api.example.com", without a
+// trailing slash. It returns "" when the host is not a valid host name, so
+// links built on it stay relative.
+func (res *Resolver) Of(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	host := r.Host
+	if res.trusts(r.RemoteAddr) {
+		if p := first(r.Header.Get("X-Forwarded-Proto")); p == "http" || p == "https" {
+			scheme = p
+		}
+		if h := first(r.Header.Get("X-Forwarded-Host")); h != "" {
+			host = h
+		}
+	}
+	if !validHost(host) {
+		return ""
+	}
+	return scheme + "://" + host
+}
+
+// trusts reports whether the peer at remoteAddr, a host:port pair, is one of
+// the trusted proxies.
+func (res *Resolver) trusts(remoteAddr string) bool {
+	if len(res.trusted) == 0 {
+		return false
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range res.trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// first returns the first of the comma-separated values a chain of proxies
+// leaves in a forwarded header, which is the one the client sent.
+func first(v string) string {
+	v, _, _ = strings.Cut(v, ",")
+	return strings.ToLower(strings.TrimSpace(v))
+}
+
+// validHost reports whether h is a host name or IP address, with an
+// optional port, and nothing else.
+func validHost(h string) bool {
+	if h == "" {
+		return false
+	}
+	name := h
+	if host, port, err := net.SplitHostPort(h); err == nil {
+		if port == "" || strings.Trim(port, "0123456789") != "" {
+			return false
+		}
+		name = host
+	} else if strings.HasPrefix(h, "[") {
+		name = strings.TrimSuffix(strings.TrimPrefix(h, "["), "]")
+	}
+	if _, err := netip.ParseAddr(name); err == nil {
+		return true
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
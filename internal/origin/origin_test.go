@@ -0,0 +1,139 @@
+package origin
+
+import (
+	"crypto/tls"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOf(t *testing.T) {
+	res, err := NewResolver([]string{"10.0.0.0/8", "192.0.2.7", "2001:db8::/32"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		remote  string
+		host    string
+		tls     bool
+		headers map[string]string
+		want    string
+	}{
+		{name: "direct", remote: "203.0.113.5:4000", host: "api.example.com", want: "http://api.example.com"},
+		{name: "direct TLS", remote: "203.0.113.5:4000", host: "api.example.com", tls: true, want: "https://api.example.com"},
+		{name: "port kept", remote: "203.0.113.5:4000", host: "localhost:8080", want: "http://localhost:8080"},
+		{
+			name:    "untrusted peer",
+			remote:  "203.0.113.5:4000",
+			host:    "api.example.com",
+			headers: map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "evil.example"},
+			want:    "http://api.example.com",
+		},
+		{
+			name:    "trusted range",
+			remote:  "10.1.2.3:4000",
+			host:    "internal:8080",
+			headers: map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "api.example.com"},
+			want:    "https://api.example.com",
+		},
+		{
+			name:    "trusted address",
+			remote:  "192.0.2.7:4000",
+			host:    "api.example.com",
+			headers: map[string]string{"X-Forwarded-Proto": "HTTPS"},
+			want:    "https://api.example.com",
+		},
+		{
+			name:    "trusted IPv6",
+			remote:  "[2001:db8::1]:4000",
+			host:    "api.example.com",
+			headers: map[string]string{"X-Forwarded-Proto": "https"},
+			want:    "https://api.example.com",
+		},
+		{
+			name:    "first of a chain",
+			remote:  "10.1.2.3:4000",
+			host:    "internal",
+			headers: map[string]string{"X-Forwarded-Proto": "https, http", "X-Forwarded-Host": "api.example.com, internal"},
+			want:    "https://api.example.com",
+		},
+		{
+			name:    "bad scheme",
+			remote:  "10.1.2.3:4000",
+			host:    "api.example.com",
+			headers: map[string]string{"X-Forwarded-Proto": "javascript"},
+			want:    "http://api.example.com",
+		},
+		{
+			name:    "bad forwarded host",
+			remote:  "10.1.2.3:4000",
+			host:    "api.example.com",
+			headers: map[string]string{"X-Forwarded-Host": "evil.example/path?"},
+			want:    "",
+		},
+		{name: "bad host", remote: "203.0.113.5:4000", host: "a@b", want: ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/odata/", nil)
+		r.RemoteAddr = tt.remote
+		r.Host = tt.host
+		if tt.tls {
+			r.TLS = &tls.ConnectionState{}
+		}
+		for k, v := range tt.headers {
+			r.Header.Set(k, v)
+		}
+		if got := res.Of(r); got != tt.want {
+			t.Errorf("%s: Of = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNoProxies(t *testing.T) {
+	res, err := NewResolver(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest("GET", "/openapi.json", nil)
+	r.RemoteAddr = "127.0.0.1:4000"
+	r.Host = "localhost:8080"
+	r.Header.Set("X-Forwarded-Proto", "https")
+	r.Header.Set("X-Forwarded-Host", "evil.example")
+	if got := res.Of(r); got != "http://localhost:8080" {
+		t.Errorf("Of = %q, want forwarded headers ignored", got)
+	}
+}
+
+func TestNewResolver(t *testing.T) {
+	for _, p := range []string{"10.0.0", "example.com", "10.0.0.0/33", ""} {
+		if _, err := NewResolver([]string{p}); err == nil {
+			t.Errorf("NewResolver accepted %q", p)
+		}
+	}
+}
+
+func TestValidHost(t *testing.T) {
+	tests := map[string]bool{
+		"api.example.com":      true,
+		"localhost:8080":       true,
+		"127.0.0.1":            true,
+		"[::1]:8080":           true,
+		"[::1]":                true,
+		"":                     false,
+		"example.com:":         false,
+		"example.com:http":     false,
+		"exa mple.com":         false,
+		"-example.com":         false,
+		"example..com":         false,
+		"evil.example/x":       false,
+		"user@evil.example":    false,
+		"evil.example#x":       false,
+		"api.example.com:8080": true,
+	}
+	for h, want := range tests {
+		if got := validHost(h); got != want {
+			t.Errorf("validHost(%q) = %v, want %v", h, got, want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Package page parses limit/offset/cursor pagination parameters and builds
+// the opaque cursors handed back to clients.
+package page
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+)
+
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// All requests every row of a result set. It is for exports that did not ask
+// for a page; JSON clients always get one.
+var All = Request{Limit: math.MaxInt32}
+
+// Query parameters consumed by this package.
+const (
+	LimitParam  = "limit"
+	OffsetParam = "offset"
+	CursorParam = "cursor"
+)
+
+// Request is the slice of a result set a client asked for.
+type Request struct {
+	Limit  int
+	Offset int
+	// fingerprint identifies the query the cursor was issued for, so a
+	// cursor cannot be replayed against different filters.
+	fingerprint string
+}
+
+type cursor struct {
+	Offset      int    `json:"o"`
+	Fingerprint string `json:"f"`
+}
+
+// Error reports an invalid pagination parameter.
+type Error struct {
+	Param   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Param + " " + e.Message
+}
+
+// Parse reads limit, offset and cursor from q. A cursor replaces offset and is
+// only valid for the query it was issued for.
+func Parse(q url.Values) (Request, error) {
+	r := Request{Limit: DefaultLimit, fingerprint: Fingerprint(q)}
+
+	if raw := q.Get(LimitParam); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > MaxLimit {
+			return Request{}, &Error{Param: LimitParam, Message: fmt.Sprintf("must be an integer between 1 and %d", MaxLimit)}
+		}
+		r.Limit = n
+	}
+
+	rawCursor := q.Get(CursorParam)
+	rawOffset := q.Get(OffsetParam)
+	if rawCursor != "" && rawOffset != "" {
+		return Request{}, &Error{Param: CursorParam, Message: "cannot be combined with offset"}
+	}
+
+	if rawOffset != "" {
+		n, err := strconv.Atoi(rawOffset)
+		if err != nil || n < 0 {
+			return Request{}, &Error{Param: OffsetParam, Message: "must be a non-negative integer"}
+		}
+		r.Offset = n
+	}
+
+	if rawCursor != "" {
+		c, err := decode(rawCursor)
+		if err != nil || c.Offset < 0 {
+			return Request{}, &Error{Param: CursorParam, Message: "is not a valid cursor"}
+		}
+		if c.Fingerprint != r.fingerprint {
+			return Request{}, &Error{Param: CursorParam, Message: "was issued for a different query"}
+		}
+		r.Offset = c.Offset
+	}
+
+	return r, nil
+}
+
+// Explicit reports whether q asks for a particular page.
+func Explicit(q url.Values) bool {
+	return q.Has(LimitParam) || q.Has(OffsetParam) || q.Has(CursorParam)
+}
+
+// Next returns the cursor for the page after r, or "" when r reached the end
+// of a result set holding total rows.
+func (r Request) Next(total int) string {
+	next := r.Offset + r.Limit
+	if next >= total {
+		return ""
+	}
+	b, _ := json.Marshal(cursor{Offset: next, Fingerprint: r.fingerprint})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decode(raw string) (cursor, error) {
+	var c cursor
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
+
+// Fingerprint hashes every query parameter except the pagination ones.
+// Parameters may come in any order, but repeated values keep theirs, as
+// the filters read only the first.
+func Fingerprint(q url.Values) string {
+	rest := url.Values{}
+	for k, vals := range q {
+		if k == LimitParam || k == OffsetParam || k == CursorParam {
+			continue
+		}
+		rest[k] = vals
+	}
+	// Encode sorts by key and escapes every value, so "a=1,2" and
+	// "a=1&a=2" differ.
+	sum := sha256.Sum256([]byte(rest.Encode()))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,124 @@
+package page
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query  string
+		limit  int
+		offset int
+		param  string // of the expected error, if any
+	}{
+		{"", DefaultLimit, 0, ""},
+		{"limit=25&offset=50", 25, 50, ""},
+		{"limit=1000", MaxLimit, 0, ""},
+		{"limit=0", 0, 0, LimitParam},
+		{"limit=1001", 0, 0, LimitParam},
+		{"limit=ten", 0, 0, LimitParam},
+		{"offset=-1", 0, 0, OffsetParam},
+		{"offset=1.5", 0, 0, OffsetParam},
+		{"cursor=!!", 0, 0, CursorParam},
+		{"cursor=e30&offset=5", 0, 0, CursorParam},
+	}
+	for _, tt := range tests {
+		q, _ := url.ParseQuery(tt.query)
+		r, err := Parse(q)
+		var pe *Error
+		switch {
+		case tt.param != "":
+			if !errors.As(err, &pe) || pe.Param != tt.param {
+				t.Errorf("Parse(%q) = %v, want an error for %s", tt.query, err, tt.param)
+			}
+		case err != nil:
+			t.Errorf("Parse(%q): %v", tt.query, err)
+		case r.Limit != tt.limit || r.Offset != tt.offset:
+			t.Errorf("Parse(%q) = limit %d offset %d, want %d %d", tt.query, r.Limit, r.Offset, tt.limit, tt.offset)
+		}
+	}
+}
+
+func TestCursor(t *testing.T) {
+	q := url.Values{"country": {"Germany"}, "limit": {"10"}}
+	first, err := Parse(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := first.Next(25)
+	if next == "" {
+		t.Fatal("no cursor after the first of three pages")
+	}
+
+	q.Set(CursorParam, next)
+	second, err := Parse(q)
+	if err != nil {
+		t.Fatalf("Parse with cursor: %v", err)
+	}
+	if second.Offset != 10 {
+		t.Errorf("cursor offset %d, want 10", second.Offset)
+	}
+	if last := second.Next(25); last == "" {
+		t.Error("no cursor after the second of three pages")
+	} else if third, _ := Parse(url.Values{"country": {"Germany"}, "limit": {"10"}, CursorParam: {last}}); third.Next(25) != "" {
+		t.Error("cursor after the last page")
+	}
+
+	// A cursor may not be replayed against other filters, but the limit may
+	// change between pages.
+	other := url.Values{"country": {"France"}, CursorParam: {next}}
+	var pe *Error
+	if _, err := Parse(other); !errors.As(err, &pe) || pe.Param != CursorParam {
+		t.Errorf("cursor replayed against another query: %v", err)
+	}
+	if _, err := Parse(url.Values{"country": {"Germany"}, "limit": {"50"}, CursorParam: {next}}); err != nil {
+		t.Errorf("cursor with another limit: %v", err)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	same := [][2]string{
+		{"a=1&b=2", "b=2&a=1"},
+		{"a=1", "a=1&limit=5&offset=10"},
+		{"a=1", "a=1&cursor=xyz"},
+	}
+	for _, p := range same {
+		x, _ := url.ParseQuery(p[0])
+		y, _ := url.ParseQuery(p[1])
+		if Fingerprint(x) != Fingerprint(y) {
+			t.Errorf("Fingerprint(%q) != Fingerprint(%q)", p[0], p[1])
+		}
+	}
+	differ := [][2]string{
+		{"a=1", "a=2"},
+		{"a=1", "b=1"},
+		{"a=1,2", "a=1&a=2"},
+		{"a=1&a=2", "a=2&a=1"},
+		{"a=1%26b%3D2", "a=1&b=2"},
+	}
+	for _, p := range differ {
+		x, _ := url.ParseQuery(p[0])
+		y, _ := url.ParseQuery(p[1])
+		if Fingerprint(x) == Fingerprint(y) {
+			t.Errorf("Fingerprint(%q) == Fingerprint(%q)", p[0], p[1])
+		}
+	}
+}
+
+func TestExplicit(t *testing.T) {
+	for query, want := range map[string]bool{
+		"":                  false,
+		"country=Germany":   false,
+		"limit=10":          true,
+		"offset=0":          true,
+		"cursor=abc&a=1":    true,
+		"format=csv&sort=x": false,
+	} {
+		q, _ := url.ParseQuery(query)
+		if got := Explicit(q); got != want {
+			t.Errorf("Explicit(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
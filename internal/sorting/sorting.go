@@ -0,0 +1,94 @@
+// Package sorting parses the sort query parameter against the fields an
+// endpoint allows clients to order by.
+//
+// The parameter is a comma-separated list of field names, each optionally
+// prefixed with "-" for descending order: sort=country,-total_sales.
+package sorting
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Param is the query parameter read by Spec.Parse.
+const Param = "sort"
+
+// Spec lists the fields an endpoint can be sorted by, named as they appear in
+// the JSON response.
+type Spec []string
+
+// Key orders by one field.
+type Key struct {
+	Field string
+	Desc  bool
+}
+
+// Order is a list of keys, most significant first. The zero value means the
+// endpoint's default order.
+type Order []Key
+
+// Error reports an invalid sort parameter.
+type Error struct {
+	Message string
+}
+
+func (e *Error) Error() string {
+	return Param + " " + e.Message
+}
+
+// Has reports whether field is sortable.
+func (s Spec) Has(field string) bool {
+	for _, f := range s {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse validates the sort parameter in q against s. An absent or empty
+// parameter yields a nil Order.
+func (s Spec) Parse(q url.Values) (Order, error) {
+	raw := strings.TrimSpace(q.Get(Param))
+	if raw == "" {
+		return nil, nil
+	}
+
+	var o Order
+	seen := map[string]bool{}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		k := Key{Field: part}
+		if strings.HasPrefix(part, "-") {
+			k = Key{Field: part[1:], Desc: true}
+		}
+
+		if !s.Has(k.Field) {
+			return nil, &Error{Message: fmt.Sprintf("cannot use %q; sortable fields are %s", k.Field, strings.Join(s, ", "))}
+		}
+		if seen[k.Field] {
+			return nil, &Error{Message: fmt.Sprintf("lists %q more than once", k.Field)}
+		}
+		seen[k.Field] = true
+		o = append(o, k)
+	}
+	return o, nil
+}
+
+// String renders o in the form accepted by Parse.
+func (o Order) String() string {
+	parts := make([]string, len(o))
+	for i, k := range o {
+		if k.Desc {
+			parts[i] = "-" + k.Field
+		} else {
+			parts[i] = k.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+)
+
+// CompareParam names the query parameter that asks a sales summary to compare
+// each group with an earlier period.
+const CompareParam = "compare_to"
+
+// Values of CompareParam besides a four-digit year.
+const (
+	PreviousYear   = "previous_year"
+	PreviousPeriod = "previous_period"
+)
+
+// CompareError reports a compare_to value that cannot be applied to the
+// request's filters.
+type CompareError struct {
+	Message string
+}
+
+func (e *CompareError) Error() string {
+	return CompareParam + " " + e.Message
+}
+
+// PriorPeriod returns f moved to the period compareTo names. The current
+// period is given by the year filter and the order_date bounds in f; the
+// other filters carry over unchanged.
+//
+//   - previous_year moves the period back one year.
+//   - previous_period moves it back by its own length: the number of years
+//     selected, or the length of the order_date range in whole years, months
+//     or days.
+//   - a year such as 1996 moves a period within one year to the same dates
+//     in that year.
+func PriorPeriod(f filter.Set, compareTo string) (filter.Set, error) {
+	years := f.Ints("year")
+	from, to := f.Bounds("order_date")
+	if len(years) == 0 && from == nil && to == nil {
+		return filter.Set{}, &CompareError{"needs a year or order_date filter to define the current period"}
+	}
+
+	shift := func(y, m, d int) filter.Set {
+		return f.AddInt("year", int64(y)).AddDate("order_date", y, m, d)
+	}
+
+	switch compareTo {
+	case PreviousYear:
+		return shift(-1, 0, 0), nil
+
+	case PreviousPeriod:
+		if len(years) > 0 {
+			span := slices.Max(years) - slices.Min(years) + 1
+			return shift(-int(span), 0, 0), nil
+		}
+		if from == nil || to == nil {
+			return filter.Set{}, &CompareError{"previous_period needs both order_date_from and order_date_to, or a year"}
+		}
+		y, m, d := length(*from, *to)
+		return f.AddDate("order_date", -y, -m, -d), nil
+	}
+
+	target, err := strconv.Atoi(compareTo)
+	if err != nil || len(compareTo) != 4 {
+		return filter.Set{}, &CompareError{fmt.Sprintf("must be %s, %s or a year such as 1996", PreviousYear, PreviousPeriod)}
+	}
+
+	var current int
+	switch {
+	case len(years) == 1:
+		current = int(years[0])
+	case len(years) == 0 && from != nil && to != nil && from.Year() == to.AddDate(0, 0, -1).Year():
+		current = from.Year()
+	default:
+		return filter.Set{}, &CompareError{"with a year needs a single year or an order_date range within one year"}
+	}
+	return shift(target-current, 0, 0), nil
+}
+
+// length measures [from, to) in whole years, whole months or days, in that
+// order of preference, so that calendar periods move to the matching
+// calendar period.
+func length(from, to time.Time) (years, months, days int) {
+	months = (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+	if months > 0 && from.AddDate(0, months, 0).Equal(to) {
+		if months%12 == 0 {
+			return months / 12, 0, 0
+		}
+		return 0, months, 0
+	}
+	return 0, 0, int(to.Sub(from).Hours() / 24)
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ErrNotFound is returned when the addressed record does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrExists is returned when creating a record whose key is already taken.
+var ErrExists = errors.New("already exists")
+
+// ErrVersionMismatch is returned when an If-Match precondition names a
+// version other than the record's current one.
+var ErrVersionMismatch = errors.New("version mismatch")
+
+// InvalidError reports write input the store rejected, such as a reference
+// to a customer that does not exist.
+type InvalidError struct {
+	Field   string
+	Message string
+}
+
+func (e *InvalidError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// StockError reports a line item asking for more units than are in stock.
+type StockError struct {
+	ProductID int `json:"product_id"`
+	Requested int `json:"requested"`
+	Available int `json:"available"`
+}
+
+func (e *StockError) Error() string {
+	return fmt.Sprintf("product %d has %d units in stock, %d requested", e.ProductID, e.Available, e.Requested)
+}
+
+// Codes of the failures Classify tells apart.
+const (
+	CodeUnavailable  = "database_unavailable"
+	CodeInvalidValue = "invalid_value"
+	CodeConstraint   = "constraint_violation"
+	CodeInternal     = "internal_error"
+	CodeOutOfScope   = "out_of_scope"
+	CodeTimeout      = "timeout"
+	CodeCanceled     = "canceled"
+)
+
+// StatusClientClosed answers a request whose caller went away before it was
+// done. Nobody reads it, but logs and metrics tell it from a failure.
+const StatusClientClosed = 499
+
+// Fault describes a failed store call to a client: a stable code, the HTTP
+// status that reports it and a message that reveals nothing of the query or
+// the driver.
+type Fault struct {
+	Code    string
+	Status  int
+	Message string
+}
+
+func (f *Fault) Error() string {
+	return f.Message
+}
+
+// sqlState is implemented by the errors of Postgres drivers such as lib/pq
+// and pgx.
+type sqlState interface {
+	SQLState() string
+}
+
+// Classify describes err, returned by a store method called with ctx, for a
+// client. It is meant for errors none of the other types in this package
+// cover; anything it cannot place is an internal error. Once ctx is done,
+// err is put down to its deadline or cancellation, whatever the driver
+// reported for the statement it canceled.
+func Classify(ctx context.Context, err error) *Fault {
+	if errors.Is(err, ErrOutOfScope) {
+		return &Fault{Code: CodeOutOfScope, Status: http.StatusForbidden, Message: "this data is not available to callers confined to one employee or customer"}
+	}
+	timeout := &Fault{Code: CodeTimeout, Status: http.StatusGatewayTimeout, Message: "the query took too long and was canceled; narrow the filters or try again later"}
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded), errors.Is(err, context.DeadlineExceeded):
+		return timeout
+	case errors.Is(ctx.Err(), context.Canceled), errors.Is(err, context.Canceled):
+		return &Fault{Code: CodeCanceled, Status: StatusClientClosed, Message: "the request was canceled"}
+	}
+	unavailable := &Fault{Code: CodeUnavailable, Status: http.StatusServiceUnavailable, Message: "the database is unavailable; try again later"}
+
+	var st sqlState
+	if errors.As(err, &st) {
+		switch state := st.SQLState(); {
+		// query_canceled, as by statement_timeout.
+		case state == "57014":
+			return timeout
+		// Connection exceptions, insufficient resources and server shutdown.
+		case strings.HasPrefix(state, "08"), strings.HasPrefix(state, "53"), strings.HasPrefix(state, "57P"):
+			return unavailable
+		// Data exceptions, such as a malformed or out of range value.
+		case strings.HasPrefix(state, "22"):
+			return &Fault{Code: CodeInvalidValue, Status: http.StatusBadRequest, Message: "a value in the request cannot be used by the database"}
+		// Integrity constraint violations.
+		case strings.HasPrefix(state, "23"):
+			return &Fault{Code: CodeConstraint, Status: http.StatusConflict, Message: "the change conflicts with existing data"}
+		}
+	}
+
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.As(err, &netErr) {
+		return unavailable
+	}
+	return &Fault{Code: CodeInternal, Status: http.StatusInternalServerError, Message: "internal error"}
+}
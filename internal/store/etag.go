@@ -0,0 +1,35 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// AnyVersion is the If-Match value that accepts whatever version is current.
+const AnyVersion = "*"
+
+// ETag returns a strong entity tag for v, derived from its JSON encoding so it
+// changes whenever any field a client can see changes.
+func ETag(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return `"` + hex.EncodeToString(sum[:12]) + `"`
+}
+
+// Matches reports whether the If-Match or If-None-Match value header names
+// the current version of v. header may list several tags.
+func Matches(header string, v any) bool {
+	current := ETag(v)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == AnyVersion || tag == current {
+			return true
+		}
+	}
+	return false
+}
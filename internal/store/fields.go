@@ -0,0 +1,80 @@
+package store
+
+import (
+	"github.com/nicholasraynes/northwind-api/internal/fieldset"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+)
+
+// salesFields is shared by the sales-by-* summaries.
+var salesFields = fieldset.Of[models.SalesSummary]()
+
+// SalesComparisonFields are returned by CompareSales.
+var SalesComparisonFields = fieldset.Of[models.SalesComparison]()
+
+// CustomerFields are returned by ListCustomers.
+var CustomerFields = fieldset.Of[models.Customer]()
+
+// OrderFields are returned by ListOrders.
+var OrderFields = fieldset.Of[models.Order]()
+
+// OrderDetailFields are returned by ListOrderDetails.
+var OrderDetailFields = fieldset.Of[models.OrderDetail]()
+
+// ProductFields are returned by ListProducts.
+var ProductFields = fieldset.Of[models.Product]()
+
+// SupplierFields are returned by ListSuppliers.
+var SupplierFields = fieldset.Of[models.Supplier]()
+
+// SalesByCountryFields are returned by SalesByCountry.
+var SalesByCountryFields = salesFields
+
+// SalesByCategoryFields are returned by SalesByCategory.
+var SalesByCategoryFields = salesFields
+
+// SalesByEmployeeFields are returned by SalesByEmployee.
+var SalesByEmployeeFields = salesFields
+
+// SalesByShipperFields are returned by SalesByShipper.
+var SalesByShipperFields = salesFields
+
+// SalesByYearFields are returned by SalesByYear.
+var SalesByYearFields = salesFields
+
+// SalesOverTimeFields are returned by SalesOverTime.
+var SalesOverTimeFields = fieldset.Of[models.SalesPeriod]()
+
+// TopCustomersFields are returned by TopCustomers.
+var TopCustomersFields = fieldset.Of[models.TopCustomer]()
+
+// CustomerOrdersFields are returned by CustomerOrders.
+var CustomerOrdersFields = fieldset.Of[models.CustomerOrderSummary]()
+
+// CustomerLTVFields are returned by CustomerLTV.
+var CustomerLTVFields = fieldset.Of[models.CustomerLTV]()
+
+// CustomerRetentionFields are returned by CustomerRetention.
+var CustomerRetentionFields = fieldset.Of[models.CustomerRetention]()
+
+// TopProductsFields are returned by TopProducts.
+var TopProductsFields = fieldset.Of[models.TopProduct]()
+
+// SupplierPerformanceFields are returned by SupplierPerformance.
+var SupplierPerformanceFields = fieldset.Of[models.SupplierPerformance]()
+
+// InventoryStatusFields are returned by InventoryStatus.
+var InventoryStatusFields = fieldset.Of[models.InventoryStatus]()
+
+// EmployeePerformanceFields are returned by EmployeePerformance.
+var EmployeePerformanceFields = fieldset.Of[models.EmployeePerformance]()
+
+// ShippingCostsFields are returned by ShippingCosts. The model's year is
+// never filled by this endpoint.
+var ShippingCostsFields = fieldset.Of[models.ShippingCosts]().Without("year")
+
+// DeliveryTimesFields are returned by DeliveryTimes. The model's year is
+// never filled by this endpoint.
+var DeliveryTimesFields = fieldset.Of[models.DeliveryTimes]().Without("year")
+
+// APIKeyFields are returned by ListAPIKeys.
+var APIKeyFields = fieldset.Of[models.APIKey]()
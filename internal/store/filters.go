@@ -0,0 +1,223 @@
+package store
+
+import "github.com/nicholasraynes/northwind-api/internal/filter"
+
+// yearFilter is shared by every endpoint that can be narrowed to order years.
+var yearFilter = filter.Field{Name: "year", Type: filter.Int, Mode: filter.In, Description: "Filter by order year"}
+
+// CustomerFilters are accepted by ListCustomers.
+var CustomerFilters = filter.Spec{
+	{Name: "country", Type: filter.Text, Mode: filter.Exact, Description: "Filter by country"},
+	{Name: "city", Type: filter.Text, Mode: filter.Exact, Description: "Filter by city"},
+	{Name: "customer_id", Type: filter.Text, Mode: filter.In, Description: "Filter by customer ID"},
+	{Name: "company_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by company name"},
+	{Name: "contact_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by contact name"},
+	{Name: "contact_title", Type: filter.Text, Mode: filter.Contains, Description: "Filter by contact title"},
+	{Name: "address", Type: filter.Text, Mode: filter.Contains, Description: "Filter by address"},
+	{Name: "region", Type: filter.Text, Mode: filter.Contains, Description: "Filter by region"},
+	{Name: "postal_code", Type: filter.Text, Mode: filter.Prefix, Description: "Filter by postal code"},
+	{Name: "phone", Type: filter.Text, Mode: filter.Contains, Description: "Filter by phone"},
+	{Name: "fax", Type: filter.Text, Mode: filter.Contains, Description: "Filter by fax"},
+}
+
+// OrderFilters are accepted by ListOrders.
+var OrderFilters = filter.Spec{
+	{Name: "customer_id", Type: filter.Text, Mode: filter.In, Description: "Filter by customer ID"},
+	{Name: "employee", Type: filter.Text, Mode: filter.Contains, Description: "Filter by employee"},
+	yearFilter,
+	{Name: "country", Type: filter.Text, Mode: filter.Exact, Description: "Filter by ship country"},
+	{Name: "order_id", Type: filter.Int, Mode: filter.In, Description: "Filter by order ID"},
+	{Name: "customer_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by customer name"},
+	{Name: "employee_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by employee name"},
+	{Name: "order_date", Type: filter.Date, Mode: filter.Range, Description: "Filter by order date"},
+	{Name: "required_date", Type: filter.Date, Mode: filter.Range, Description: "Filter by required date"},
+	{Name: "shipped_date", Type: filter.Date, Mode: filter.Range, Description: "Filter by shipped date"},
+	{Name: "ship_via", Type: filter.Int, Mode: filter.In, Description: "Filter by shipper ID"},
+	{Name: "shipper_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by shipper name"},
+	{Name: "freight", Type: filter.Money, Mode: filter.Range, Description: "Filter by freight cost"},
+	{Name: "ship_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by ship name"},
+	{Name: "ship_address", Type: filter.Text, Mode: filter.Contains, Description: "Filter by ship address"},
+	{Name: "ship_city", Type: filter.Text, Mode: filter.Contains, Description: "Filter by ship city"},
+	{Name: "ship_region", Type: filter.Text, Mode: filter.Contains, Description: "Filter by ship region"},
+	{Name: "ship_postal_code", Type: filter.Text, Mode: filter.Prefix, Description: "Filter by ship postal code"},
+	{Name: "ship_country", Type: filter.Text, Mode: filter.Contains, Description: "Filter by ship country (partial match)"},
+}
+
+// OrderDetailFilters are accepted by ListOrderDetails.
+var OrderDetailFilters = filter.Spec{
+	{Name: "order_id", Type: filter.Int, Mode: filter.In, Description: "Filter by order ID"},
+	{Name: "customer_id", Type: filter.Text, Mode: filter.In, Description: "Filter by customer ID"},
+	{Name: "product_id", Type: filter.Int, Mode: filter.In, Description: "Filter by product ID"},
+	{Name: "product_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by product name"},
+	{Name: "category_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by category name"},
+	{Name: "supplier_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by supplier name"},
+}
+
+// ProductFilters are accepted by ListProducts.
+var ProductFilters = filter.Spec{
+	{Name: "product_id", Type: filter.Int, Mode: filter.In, Description: "Filter by product ID"},
+	{Name: "product_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by product name"},
+	{Name: "supplier_id", Type: filter.Int, Mode: filter.In, Description: "Filter by supplier ID"},
+	{Name: "supplier_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by supplier name"},
+	{Name: "category_id", Type: filter.Int, Mode: filter.In, Description: "Filter by category ID"},
+	{Name: "category_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by category name"},
+	{Name: "unit_price", Type: filter.Money, Mode: filter.Range, Description: "Filter by unit price"},
+	{Name: "discontinued", Type: filter.Bool, Mode: filter.Exact, Description: "Filter by discontinued status"},
+}
+
+// SupplierFilters are accepted by ListSuppliers.
+var SupplierFilters = filter.Spec{
+	{Name: "country", Type: filter.Text, Mode: filter.Exact, Description: "Filter by country"},
+	{Name: "supplier_id", Type: filter.Int, Mode: filter.In, Description: "Filter by supplier ID"},
+	{Name: "company_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by company name"},
+	{Name: "contact_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by contact name"},
+	{Name: "contact_title", Type: filter.Text, Mode: filter.Contains, Description: "Filter by contact title"},
+	{Name: "city", Type: filter.Text, Mode: filter.Contains, Description: "Filter by city"},
+	{Name: "phone", Type: filter.Text, Mode: filter.Contains, Description: "Filter by phone"},
+	{Name: "fax", Type: filter.Text, Mode: filter.Contains, Description: "Filter by fax"},
+}
+
+// orderDateFilter bounds the sales summaries to a range of order dates.
+var orderDateFilter = filter.Field{Name: "order_date", Type: filter.Date, Mode: filter.Range, Description: "Filter by order date"}
+
+// SalesByCountryFilters are accepted by SalesByCountry.
+var SalesByCountryFilters = filter.Spec{
+	yearFilter,
+	orderDateFilter,
+	{Name: "country", Type: filter.Text, Mode: filter.Contains, Description: "Filter by country"},
+}
+
+// SalesByCategoryFilters are accepted by SalesByCategory.
+var SalesByCategoryFilters = filter.Spec{
+	yearFilter,
+	orderDateFilter,
+	{Name: "category_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by category name"},
+}
+
+// SalesByEmployeeFilters are accepted by SalesByEmployee.
+var SalesByEmployeeFilters = filter.Spec{
+	yearFilter,
+	orderDateFilter,
+	{Name: "employee_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by employee name"},
+}
+
+// SalesByShipperFilters are accepted by SalesByShipper.
+var SalesByShipperFilters = filter.Spec{
+	yearFilter,
+	orderDateFilter,
+	{Name: "company_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by company name"},
+}
+
+// SalesByYearFilters are accepted by SalesByYear. It takes none; the spec
+// exists so every collection parses its query the same way.
+var SalesByYearFilters = filter.Spec{}
+
+// SalesOverTimeFilters are accepted by SalesOverTime. Bounds on order_date
+// also fix the first and last period reported.
+var SalesOverTimeFilters = filter.Spec{
+	yearFilter,
+	{Name: "order_date", Type: filter.Date, Mode: filter.Range, Description: "Filter by order date"},
+	{Name: "country", Type: filter.Text, Mode: filter.Exact, Description: "Filter by ship country"},
+	{Name: "category_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by category name"},
+	{Name: "employee_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by employee name"},
+	{Name: "shipper_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by shipper name"},
+}
+
+// TopCustomersFilters are accepted by TopCustomers.
+var TopCustomersFilters = filter.Spec{
+	{Name: "country", Type: filter.Text, Mode: filter.Exact, Description: "Filter by country"},
+	yearFilter,
+	{Name: "customer_id", Type: filter.Text, Mode: filter.In, Description: "Filter by customer ID"},
+	{Name: "company_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by company name"},
+}
+
+// CustomerOrdersFilters are accepted by CustomerOrders.
+var CustomerOrdersFilters = filter.Spec{
+	{Name: "customer_id", Type: filter.Text, Mode: filter.In, Description: "Filter by customer ID"},
+	yearFilter,
+	{Name: "order_id", Type: filter.Int, Mode: filter.In, Description: "Filter by order ID"},
+	{Name: "company_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by company name"},
+	{Name: "order_date", Type: filter.Date, Mode: filter.Range, Description: "Filter by order date"},
+	{Name: "shipped_date", Type: filter.Date, Mode: filter.Range, Description: "Filter by shipped date"},
+	{Name: "country", Type: filter.Text, Mode: filter.Contains, Description: "Filter by country"},
+}
+
+// CustomerLTVFilters are accepted by CustomerLTV.
+var CustomerLTVFilters = filter.Spec{
+	{Name: "country", Type: filter.Text, Mode: filter.Exact, Description: "Filter by country"},
+	{Name: "customer_id", Type: filter.Text, Mode: filter.In, Description: "Filter by customer ID"},
+	{Name: "company_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by company name"},
+}
+
+// CustomerRetentionFilters are accepted by CustomerRetention. Unlike the
+// other endpoints, year keeps customers that were active in that year.
+var CustomerRetentionFilters = filter.Spec{
+	{Name: "year", Type: filter.Int, Mode: filter.Exact, Description: "Keep customers active in this year"},
+	{Name: "customer_id", Type: filter.Text, Mode: filter.In, Description: "Filter by customer ID"},
+	{Name: "company_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by company name"},
+	{Name: "country", Type: filter.Text, Mode: filter.Contains, Description: "Filter by country"},
+	{Name: "repeat_customer", Type: filter.Bool, Mode: filter.Exact, Description: "Filter by repeat customer status"},
+}
+
+// TopProductsFilters are accepted by TopProducts.
+var TopProductsFilters = filter.Spec{
+	yearFilter,
+	{Name: "product_id", Type: filter.Int, Mode: filter.In, Description: "Filter by product ID"},
+	{Name: "product_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by product name"},
+	{Name: "category_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by category name"},
+	{Name: "supplier_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by supplier name"},
+}
+
+// SupplierPerformanceFilters are accepted by SupplierPerformance.
+var SupplierPerformanceFilters = filter.Spec{
+	yearFilter,
+	{Name: "supplier_id", Type: filter.Int, Mode: filter.In, Description: "Filter by supplier ID"},
+	{Name: "supplier_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by supplier name"},
+	{Name: "country", Type: filter.Text, Mode: filter.Contains, Description: "Filter by country"},
+	{Name: "top_category", Type: filter.Text, Mode: filter.Contains, Description: "Filter by top category"},
+}
+
+// InventoryStatusFilters are accepted by InventoryStatus.
+var InventoryStatusFilters = filter.Spec{
+	{Name: "product_id", Type: filter.Int, Mode: filter.In, Description: "Filter by product ID"},
+	{Name: "product_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by product name"},
+	{Name: "supplier_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by supplier name"},
+	{Name: "category_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by category name"},
+	{Name: "units_in_stock", Type: filter.Int, Mode: filter.Range, Description: "Filter by units in stock"},
+	{Name: "discontinued", Type: filter.Bool, Mode: filter.Exact, Description: "Filter by discontinued status"},
+	{Name: "needs_reorder", Type: filter.Bool, Mode: filter.Exact, Description: "Filter by reorder status"},
+}
+
+// EmployeePerformanceFilters are accepted by EmployeePerformance.
+var EmployeePerformanceFilters = filter.Spec{
+	yearFilter,
+	{Name: "employee_id", Type: filter.Int, Mode: filter.In, Description: "Filter by employee ID"},
+	{Name: "full_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by full name"},
+	{Name: "title", Type: filter.Text, Mode: filter.Contains, Description: "Filter by job title"},
+	{Name: "country", Type: filter.Text, Mode: filter.Contains, Description: "Filter by country"},
+}
+
+// ShippingCostsFilters are accepted by ShippingCosts.
+var ShippingCostsFilters = filter.Spec{
+	yearFilter,
+	{Name: "shipper_id", Type: filter.Int, Mode: filter.In, Description: "Filter by shipper ID"},
+	{Name: "company_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by company name"},
+}
+
+// DeliveryTimesFilters are accepted by DeliveryTimes.
+var DeliveryTimesFilters = filter.Spec{
+	yearFilter,
+	{Name: "order_date", Type: filter.Date, Mode: filter.Range, Description: "Filter by order date"},
+	{Name: "shipper_id", Type: filter.Int, Mode: filter.In, Description: "Filter by shipper ID"},
+	{Name: "shipper_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by shipper name"},
+	{Name: "employee_id", Type: filter.Int, Mode: filter.In, Description: "Filter by employee ID"},
+	{Name: "employee_name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by employee name"},
+	{Name: "ship_country", Type: filter.Text, Mode: filter.Exact, Description: "Filter by destination country"},
+}
+
+// APIKeyFilters are accepted by ListAPIKeys.
+var APIKeyFilters = filter.Spec{
+	{Name: "key_id", Type: filter.Int, Mode: filter.In, Description: "Filter by key ID"},
+	{Name: "name", Type: filter.Text, Mode: filter.Contains, Description: "Filter by key name"},
+	{Name: "prefix", Type: filter.Text, Mode: filter.Exact, Description: "Filter by key prefix"},
+}
@@ -0,0 +1,44 @@
+package store
+
+// Some aggregates take options that change their shape rather than narrow
+// their rows. Each option lists its accepted values with the default first.
+
+// DeliveryGrouping selects the groups DeliveryTimes reports on.
+type DeliveryGrouping string
+
+const (
+	ByShipper            DeliveryGrouping = "shipper"
+	ByEmployee           DeliveryGrouping = "employee"
+	ByShipperAndEmployee DeliveryGrouping = "both"
+)
+
+// DeliveryGroupings lists every DeliveryGrouping, the default first.
+var DeliveryGroupings = []string{string(ByShipper), string(ByEmployee), string(ByShipperAndEmployee)}
+
+// Granularity is the length of the periods SalesOverTime reports on.
+type Granularity string
+
+const (
+	Day     Granularity = "day"
+	Week    Granularity = "week"
+	Month   Granularity = "month"
+	Quarter Granularity = "quarter"
+	Year    Granularity = "year"
+)
+
+// Granularities lists every Granularity, the default first.
+var Granularities = []string{string(Month), string(Day), string(Week), string(Quarter), string(Year)}
+
+// SalesSplit selects the dimension SalesOverTime splits each period by.
+type SalesSplit string
+
+const (
+	NoSplit         SalesSplit = "none"
+	SplitByCountry  SalesSplit = "country"
+	SplitByCategory SalesSplit = "category"
+	SplitByEmployee SalesSplit = "employee"
+	SplitByShipper  SalesSplit = "shipper"
+)
+
+// SalesSplits lists every SalesSplit, the default first.
+var SalesSplits = []string{string(NoSplit), string(SplitByCountry), string(SplitByCategory), string(SplitByEmployee), string(SplitByShipper)}
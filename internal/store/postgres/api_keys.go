@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+var apiKeysView = view{
+	query: `
+		SELECT key_id, name, prefix, scopes, row_scope, created_at,
+		       rotated_at, last_used_at, revoked_at
+		FROM api_keys
+		{{where}}
+	`,
+	inner: filter.Columns{
+		"key_id": "key_id",
+		"name":   "name",
+		"prefix": "prefix",
+	},
+	order: "key_id",
+}
+
+// ListAPIKeys runs the query behind GET /admin/keys, revoked keys included.
+func (s *Store) ListAPIKeys(ctx context.Context, q store.Query) (store.Rows[models.APIKey], error) {
+	return list[models.APIKey](ctx, s.db, apiKeysView, q)
+}
+
+// getAPIKey returns the key with the given id, revoked or not.
+func (s *Store) getAPIKey(ctx context.Context, id int) (models.APIKey, error) {
+	found, err := lookup[models.APIKey](ctx, s.db, apiKeysView, "key_id", id)
+	if err != nil {
+		return models.APIKey{}, err
+	}
+	if len(found) == 0 {
+		return models.APIKey{}, store.ErrNotFound
+	}
+	return found[0], nil
+}
+
+// CreateAPIKey stores a new key.
+func (s *Store) CreateAPIKey(ctx context.Context, k models.APIKey, hash string) (models.APIKey, error) {
+	var id int
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO api_keys (name, prefix, key_hash, scopes, row_scope)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING key_id
+	`, k.Name, k.Prefix, hash, k.Scopes, k.RowScope).Scan(&id)
+	if err != nil {
+		return models.APIKey{}, err
+	}
+	return s.getAPIKey(ctx, id)
+}
+
+// RotateAPIKey swaps the stored hash, so the old key stops working at once.
+func (s *Store) RotateAPIKey(ctx context.Context, id int, prefix, hash string) (models.APIKey, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE api_keys SET prefix = $2, key_hash = $3, rotated_at = now()
+		WHERE key_id = $1 AND revoked_at IS NULL
+	`, id, prefix, hash)
+	if err != nil {
+		return models.APIKey{}, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return models.APIKey{}, err
+	} else if n == 0 {
+		return models.APIKey{}, store.ErrNotFound
+	}
+	return s.getAPIKey(ctx, id)
+}
+
+// RevokeAPIKey stops a live key from authenticating.
+func (s *Store) RevokeAPIKey(ctx context.Context, id int) error {
+	res, err := s.db.ExecContext(ctx, "UPDATE api_keys SET revoked_at = now() WHERE key_id = $1 AND revoked_at IS NULL", id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err == nil && n == 0 {
+		err = store.ErrNotFound
+	}
+	return err
+}
+
+// UseAPIKey looks a key up by hash on every authenticated request. Writing
+// last_used_at at most once a minute keeps busy keys from turning each read
+// into a write.
+func (s *Store) UseAPIKey(ctx context.Context, hash string) (models.APIKey, error) {
+	var k models.APIKey
+	err := s.db.QueryRowContext(ctx, `
+		WITH used AS (
+			UPDATE api_keys SET last_used_at = now()
+			WHERE key_hash = $1 AND revoked_at IS NULL
+			  AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+			RETURNING key_id, last_used_at
+		)
+		SELECT k.key_id, k.name, k.prefix, k.scopes, k.row_scope, k.created_at,
+		       k.rotated_at, COALESCE(used.last_used_at, k.last_used_at), k.revoked_at
+		FROM api_keys k
+		LEFT JOIN used ON used.key_id = k.key_id
+		WHERE k.key_hash = $1 AND k.revoked_at IS NULL
+	`, hash).Scan(&k.KeyID, &k.Name, &k.Prefix, &k.Scopes, &k.RowScope, &k.CreatedAt, &k.RotatedAt, &k.LastUsedAt, &k.RevokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.APIKey{}, store.ErrNotFound
+	}
+	return k, err
+}
@@ -0,0 +1,182 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+var topCustomersView = view{
+	query: `
+		SELECT
+			c.customer_id,
+			c.company_name,
+			c.country,
+			SUM(od.unit_price * od.quantity * (1 - od.discount)) AS total_sales,
+			COUNT(DISTINCT o.order_id) AS order_count,
+			AVG(od.unit_price * od.quantity * (1 - od.discount)) AS average_order
+		FROM order_details od
+		JOIN orders o ON od.order_id = o.order_id
+		JOIN customers c ON o.customer_id = c.customer_id
+		{{where}}
+		GROUP BY c.customer_id, c.company_name, c.country
+	`,
+	inner: filter.Columns{
+		"country":      "c.country",
+		"year":         "EXTRACT(YEAR FROM o.order_date)",
+		"customer_id":  "c.customer_id",
+		"company_name": "c.company_name",
+	},
+	order:  "total_sales DESC, customer_id",
+	owners: orderOwners,
+}
+
+var customerOrdersView = view{
+	query: `
+		SELECT
+			c.customer_id,
+			c.company_name,
+			o.order_id,
+			TO_CHAR(o.order_date, 'YYYY-MM-DD') AS order_date,
+			SUM(od.unit_price * od.quantity * (1 - od.discount)) AS total_amount,
+			COALESCE(TO_CHAR(o.shipped_date, 'YYYY-MM-DD'), '') AS shipped_date,
+			c.country
+		FROM order_details od
+		JOIN orders o ON od.order_id = o.order_id
+		JOIN customers c ON o.customer_id = c.customer_id
+		{{where}}
+		GROUP BY c.customer_id, c.company_name, o.order_id, o.order_date, o.shipped_date, c.country
+	`,
+	inner: filter.Columns{
+		"customer_id":  "c.customer_id",
+		"year":         "EXTRACT(YEAR FROM o.order_date)",
+		"order_id":     "o.order_id",
+		"company_name": "c.company_name",
+		"order_date":   "o.order_date",
+		"shipped_date": "o.shipped_date",
+		"country":      "c.country",
+	},
+	order:  "order_date DESC, order_id DESC",
+	owners: orderOwners,
+}
+
+var customerLTVView = view{
+	query: `
+		SELECT
+			c.customer_id,
+			c.company_name,
+			c.country,
+			MIN(TO_CHAR(o.order_date, 'YYYY-MM-DD')) AS first_order,
+			MAX(TO_CHAR(o.order_date, 'YYYY-MM-DD')) AS last_order,
+			COUNT(DISTINCT o.order_id) AS order_count,
+			SUM(od.unit_price * od.quantity * (1 - od.discount)) AS total_sales,
+			AVG(od.unit_price * od.quantity * (1 - od.discount)) AS avg_order
+		FROM order_details od
+		JOIN orders o ON od.order_id = o.order_id
+		JOIN customers c ON o.customer_id = c.customer_id
+		{{where}}
+		GROUP BY c.customer_id, c.company_name, c.country
+	`,
+	inner: filter.Columns{
+		"country":      "c.country",
+		"customer_id":  "c.customer_id",
+		"company_name": "c.company_name",
+	},
+	order:  "total_sales DESC, customer_id",
+	owners: orderOwners,
+}
+
+var customerRetentionView = view{
+	query: `
+		WITH customer_years AS (
+			SELECT
+				c.customer_id,
+				c.company_name,
+				c.country,
+				MIN(EXTRACT(YEAR FROM o.order_date))::int AS first_order_year,
+				MAX(EXTRACT(YEAR FROM o.order_date))::int AS last_order_year,
+				COUNT(DISTINCT o.order_id) AS order_count,
+				COUNT(DISTINCT EXTRACT(YEAR FROM o.order_date)) AS active_years
+			FROM orders o
+			JOIN customers c ON o.customer_id = c.customer_id
+			{{where}}
+			GROUP BY c.customer_id, c.company_name, c.country
+		)
+		SELECT
+			customer_id,
+			company_name,
+			country,
+			first_order_year,
+			last_order_year,
+			order_count,
+			active_years,
+			CASE WHEN active_years > 1 THEN true ELSE false END AS repeat_customer
+		FROM customer_years
+	`,
+	outer: filter.Columns{
+		"customer_id":     "customer_id",
+		"company_name":    "company_name",
+		"country":         "country",
+		"repeat_customer": "repeat_customer",
+	},
+	// year means "active in that year", which spans two columns.
+	where: func(f filter.Set, args *[]any) []string {
+		year, ok := f.Value("year")
+		if !ok {
+			return nil
+		}
+		*args = append(*args, year)
+		return []string{fmt.Sprintf("first_order_year <= $%d AND last_order_year >= $%d", len(*args), len(*args))}
+	},
+	order:  "repeat_customer DESC, active_years DESC, order_count DESC, customer_id",
+	owners: orderOwners,
+}
+
+// TopCustomers runs the query behind GET /analytics/top-customers.
+func (s *Store) TopCustomers(ctx context.Context, q store.Query) (store.Rows[models.TopCustomer], error) {
+	return list[models.TopCustomer](ctx, s.db, topCustomersView, q)
+}
+
+// CustomerOrders runs the query behind GET /analytics/customer-orders.
+func (s *Store) CustomerOrders(ctx context.Context, q store.Query) (store.Rows[models.CustomerOrderSummary], error) {
+	return list[models.CustomerOrderSummary](ctx, s.db, customerOrdersView, q)
+}
+
+// CustomerLTV runs the query behind GET /analytics/customer-ltv.
+func (s *Store) CustomerLTV(ctx context.Context, q store.Query) (store.Rows[models.CustomerLTV], error) {
+	return list[models.CustomerLTV](ctx, s.db, customerLTVView, q)
+}
+
+// CustomerRetention runs the query behind GET /analytics/customer-retention.
+func (s *Store) CustomerRetention(ctx context.Context, q store.Query) (store.Rows[models.CustomerRetention], error) {
+	return list[models.CustomerRetention](ctx, s.db, customerRetentionView, q)
+}
+
+// CustomerRetentionSummary aggregates retention over every customer matching
+// f, independent of paging.
+func (s *Store) CustomerRetentionSummary(ctx context.Context, f filter.Set) (models.RetentionSummary, error) {
+	var sum models.RetentionSummary
+	v, err := confined(ctx, customerRetentionView)
+	if err != nil {
+		return sum, err
+	}
+
+	args := []any{}
+	query := fmt.Sprintf(`
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE repeat_customer)
+		FROM (%s) AS f
+	`, v.filtered(store.Query{Filters: f}, nil, &args))
+
+	if err := s.db.QueryRowContext(ctx, query, args...).Scan(&sum.TotalCustomers, &sum.RepeatCustomers); err != nil {
+		return sum, err
+	}
+	if sum.TotalCustomers > 0 {
+		sum.RetentionRate = float64(sum.RepeatCustomers) / float64(sum.TotalCustomers)
+	}
+	return sum, nil
+}
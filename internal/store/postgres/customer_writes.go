@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+var customersTable = table{name: "customers", key: "customer_id", view: customersView}
+
+func customerColumns(c models.Customer) []column {
+	return []column{
+		{"company_name", c.CompanyName, true},
+		{"contact_name", c.ContactName, true},
+		{"contact_title", c.ContactTitle, true},
+		{"address", c.Address, true},
+		{"city", c.City, true},
+		{"region", c.Region, true},
+		{"postal_code", c.PostalCode, true},
+		{"country", c.Country, true},
+		{"phone", c.Phone, true},
+		{"fax", c.Fax, true},
+	}
+}
+
+// GetCustomer returns a single customer as GET /customers would.
+func (s *Store) GetCustomer(ctx context.Context, id string) (models.Customer, error) {
+	return get[models.Customer](ctx, s.db, customersTable, id)
+}
+
+// CreateCustomer inserts a customer under the id the client chose. Ids of
+// deleted customers stay taken.
+func (s *Store) CreateCustomer(ctx context.Context, c models.Customer) (models.Customer, error) {
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		ok, err := insert(ctx, tx, customersTable, c.CustomerID, customerColumns(c))
+		if err == nil && !ok {
+			err = store.ErrExists
+		}
+		return err
+	})
+	if err != nil {
+		return models.Customer{}, err
+	}
+	return s.GetCustomer(ctx, c.CustomerID)
+}
+
+// UpdateCustomer overwrites every field of a customer.
+func (s *Store) UpdateCustomer(ctx context.Context, c models.Customer, ifMatch string) (models.Customer, error) {
+	return update[models.Customer](ctx, s, customersTable, c.CustomerID, ifMatch, customerColumns(c), nil)
+}
+
+// DeleteCustomer hides a customer; their orders are kept.
+func (s *Store) DeleteCustomer(ctx context.Context, id string, ifMatch string) error {
+	return softDelete[models.Customer](ctx, s, customersTable, id, ifMatch)
+}
@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+var customersView = view{
+	query: `
+		SELECT customer_id, company_name, contact_name, contact_title, address,
+		       city, region, postal_code, country, phone, fax
+		FROM customers
+		{{where}}
+	`,
+	scope: []string{"deleted_at IS NULL"},
+	inner: filter.Columns{
+		"country":       "country",
+		"city":          "city",
+		"customer_id":   "customer_id",
+		"company_name":  "company_name",
+		"contact_name":  "contact_name",
+		"contact_title": "contact_title",
+		"address":       "address",
+		"region":        "region",
+		"postal_code":   "postal_code",
+		"phone":         "phone",
+		"fax":           "fax",
+	},
+	order:  "company_name, customer_id",
+	owners: customerOwners,
+}
+
+// customerOwners confines customers to the caller's own record, or to those
+// that ordered through the caller.
+var customerOwners = map[string]string{
+	store.RowEmployee: "customer_id IN (SELECT customer_id FROM orders WHERE employee_id = %s)",
+	store.RowCustomer: "customer_id = %s",
+}
+
+// ListCustomers runs the query behind GET /customers.
+func (s *Store) ListCustomers(ctx context.Context, q store.Query) (store.Rows[models.Customer], error) {
+	return list[models.Customer](ctx, s.db, customersView, q)
+}
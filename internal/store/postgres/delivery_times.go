@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// deliveryDimensions are the shipper and employee output columns of
+// deliveryTimesView. Each selects either its expression or NULL, depending on
+// the grouping.
+var deliveryDimensions = []struct {
+	name, expr, null string
+	// omittedBy is the grouping that leaves this dimension out.
+	omittedBy store.DeliveryGrouping
+}{
+	{"shipper_id", "s.shipper_id", "NULL::int", store.ByEmployee},
+	{"shipper_name", "s.company_name", "NULL::text", store.ByEmployee},
+	{"employee_id", "e.employee_id", "NULL::int", store.ByShipper},
+	{"employee_name", "e.first_name || ' ' || e.last_name", "NULL::text", store.ByShipper},
+}
+
+// deliveryTimesView groups orders by the shipper, the employee or both.
+func deliveryTimesView(by store.DeliveryGrouping) view {
+	selects := []string{}
+	groups := []string{}
+	for _, d := range deliveryDimensions {
+		if by == d.omittedBy {
+			selects = append(selects, d.null+" AS "+d.name)
+			continue
+		}
+		selects = append(selects, fmt.Sprintf("%s AS %s", d.expr, d.name))
+		groups = append(groups, d.expr)
+	}
+
+	return view{
+		query: fmt.Sprintf(`
+			SELECT
+				%s,
+				COUNT(*) AS total_orders,
+				COUNT(o.shipped_date) AS shipped_orders,
+				AVG(o.shipped_date - o.order_date)::float8 AS avg_delivery_days,
+				MAX(o.shipped_date - o.order_date)::float8 AS max_delivery_days,
+				MIN(o.shipped_date - o.order_date)::float8 AS min_delivery_days,
+				COUNT(*) FILTER (WHERE o.shipped_date > o.required_date) AS late_shipments,
+				(COUNT(o.shipped_date) - COUNT(*) FILTER (WHERE o.shipped_date > o.required_date))::float8
+					/ NULLIF(COUNT(o.shipped_date), 0) AS on_time_rate,
+				COUNT(*) FILTER (WHERE o.shipped_date IS NULL AND NOT COALESCE(o.required_date < CURRENT_DATE, false)) AS open_orders,
+				COUNT(*) FILTER (WHERE o.shipped_date IS NULL AND o.required_date < CURRENT_DATE) AS overdue_orders
+			FROM orders o
+			LEFT JOIN shippers s ON o.ship_via = s.shipper_id
+			JOIN employees e ON o.employee_id = e.employee_id
+			{{where}}
+			GROUP BY %s
+		`, strings.Join(selects, ",\n\t\t\t\t"), strings.Join(groups, ", ")),
+		inner: filter.Columns{
+			"year":          "EXTRACT(YEAR FROM o.order_date)",
+			"order_date":    "o.order_date",
+			"shipper_id":    "s.shipper_id",
+			"shipper_name":  "s.company_name",
+			"employee_id":   "e.employee_id",
+			"employee_name": "e.first_name || ' ' || e.last_name",
+			"ship_country":  "o.ship_country",
+		},
+		order:  "shipper_name, employee_name, shipper_id, employee_id",
+		owners: orderOwners,
+	}
+}
+
+// DeliveryTimes runs the query behind GET /analytics/delivery-times.
+func (s *Store) DeliveryTimes(ctx context.Context, q store.Query, by store.DeliveryGrouping) (store.Rows[models.DeliveryTimes], error) {
+	return list[models.DeliveryTimes](ctx, s.db, deliveryTimesView(by), q)
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+var employeePerformanceView = view{
+	query: `
+		SELECT
+			e.employee_id,
+			(e.first_name || ' ' || e.last_name) AS full_name,
+			e.title,
+			e.country,
+			COUNT(DISTINCT o.order_id) AS order_count,
+			SUM(od.unit_price * od.quantity * (1 - od.discount)) AS total_revenue,
+			AVG(od.unit_price * od.quantity * (1 - od.discount)) AS avg_order
+		FROM order_details od
+		JOIN orders o ON od.order_id = o.order_id
+		JOIN employees e ON o.employee_id = e.employee_id
+		{{where}}
+		GROUP BY e.employee_id, e.first_name, e.last_name, e.title, e.country
+	`,
+	inner: filter.Columns{
+		"year":        "EXTRACT(YEAR FROM o.order_date)",
+		"employee_id": "e.employee_id",
+		"full_name":   "e.first_name || ' ' || e.last_name",
+		"title":       "e.title",
+		"country":     "e.country",
+	},
+	order:  "total_revenue DESC, employee_id",
+	owners: orderOwners,
+}
+
+var shippingCostsView = view{
+	query: `
+		WITH shipper_orders AS (
+			SELECT
+				s.shipper_id,
+				s.company_name,
+				o.order_id,
+				o.freight,
+				c.country
+			FROM orders o
+			JOIN shippers s ON o.ship_via = s.shipper_id
+			JOIN customers c ON c.customer_id = o.customer_id
+			{{where}}
+		),
+		shipper_stats AS (
+			SELECT
+				shipper_id,
+				company_name,
+				COUNT(DISTINCT order_id) AS total_orders,
+				SUM(freight) AS total_freight,
+				AVG(freight) AS avg_freight
+			FROM shipper_orders
+			GROUP BY shipper_id, company_name
+		),
+		top_destinations AS (
+			SELECT
+				shipper_id,
+				country AS top_destination
+			FROM (
+				SELECT
+					shipper_id,
+					country,
+					COUNT(*) AS cnt,
+					ROW_NUMBER() OVER (PARTITION BY shipper_id ORDER BY COUNT(*) DESC) AS rn
+				FROM shipper_orders
+				GROUP BY shipper_id, country
+			) x
+			WHERE rn = 1
+		)
+		SELECT
+			ss.shipper_id,
+			ss.company_name,
+			ss.total_orders,
+			ss.total_freight,
+			ss.avg_freight,
+			td.top_destination
+		FROM shipper_stats ss
+		LEFT JOIN top_destinations td ON ss.shipper_id = td.shipper_id
+	`,
+	inner: filter.Columns{
+		"year": "EXTRACT(YEAR FROM o.order_date)",
+	},
+	outer: filter.Columns{
+		"shipper_id":   "shipper_id",
+		"company_name": "company_name",
+	},
+	order:  "total_freight DESC, shipper_id",
+	owners: orderOwners,
+}
+
+// EmployeePerformance runs the query behind GET /analytics/employee-performance.
+func (s *Store) EmployeePerformance(ctx context.Context, q store.Query) (store.Rows[models.EmployeePerformance], error) {
+	return list[models.EmployeePerformance](ctx, s.db, employeePerformanceView, q)
+}
+
+// ShippingCosts runs the query behind GET /analytics/shipping-costs.
+func (s *Store) ShippingCosts(ctx context.Context, q store.Query) (store.Rows[models.ShippingCosts], error) {
+	return list[models.ShippingCosts](ctx, s.db, shippingCostsView, q)
+}
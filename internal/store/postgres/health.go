@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// tables are the Northwind tables the API reads, and the API keys it
+// authenticates with.
+var tables = []string{
+	"api_keys", "categories", "customers", "employees",
+	"order_details", "orders", "products", "shippers", "suppliers",
+}
+
+// Ping checks that the database answers over a pooled connection.
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// MissingTables returns the tables the API reads that are not visible on
+// the connection's search path.
+func (s *Store) MissingTables(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t
+		FROM unnest($1::text[]) AS t
+		WHERE to_regclass(t) IS NULL
+		ORDER BY t
+	`, pq.Array(tables))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	missing := []string{}
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		missing = append(missing, t)
+	}
+	return missing, rows.Err()
+}
+
+// PoolStats returns the statistics of the connection pool.
+func (s *Store) PoolStats() sql.DBStats {
+	return s.db.Stats()
+}
@@ -0,0 +1,5 @@
+-- Customers, products and suppliers are soft-deleted so historical orders
+-- keep resolving their names.
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+ALTER TABLE suppliers ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
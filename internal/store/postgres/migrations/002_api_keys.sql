@@ -0,0 +1,14 @@
+-- API keys callers authenticate with. Only a SHA-256 hash of each key is
+-- kept; prefix is its first characters, to tell keys apart. scopes is
+-- space-separated.
+CREATE TABLE IF NOT EXISTS api_keys (
+    key_id       serial PRIMARY KEY,
+    name         text NOT NULL,
+    prefix       text NOT NULL,
+    key_hash     text NOT NULL UNIQUE,
+    scopes       text NOT NULL,
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    rotated_at   timestamptz,
+    last_used_at timestamptz,
+    revoked_at   timestamptz
+);
@@ -0,0 +1,3 @@
+-- Confines a key to one employee's or customer's rows, as comma-separated
+-- dimension=value pairs such as employee_id=5. NULL means unconfined.
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS row_scope text;
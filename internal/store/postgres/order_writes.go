@@ -0,0 +1,467 @@
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// column is one writable orders column and the value supplied for it.
+type column struct {
+	name  string
+	value any
+	set   bool
+}
+
+func orderColumns(in models.OrderInput) []column {
+	return []column{
+		{"customer_id", in.CustomerID, in.CustomerID != nil},
+		{"employee_id", in.EmployeeID, in.EmployeeID != nil},
+		{"order_date", in.OrderDate, in.OrderDate != nil},
+		{"required_date", in.RequiredDate, in.RequiredDate != nil},
+		{"shipped_date", in.ShippedDate, in.ShippedDate != nil},
+		{"ship_via", in.ShipVia, in.ShipVia != nil},
+		{"freight", in.Freight, in.Freight != nil},
+		{"ship_name", in.ShipName, in.ShipName != nil},
+		{"ship_address", in.ShipAddress, in.ShipAddress != nil},
+		{"ship_city", in.ShipCity, in.ShipCity != nil},
+		{"ship_region", in.ShipRegion, in.ShipRegion != nil},
+		{"ship_postal_code", in.ShipPostal, in.ShipPostal != nil},
+		{"ship_country", in.ShipCountry, in.ShipCountry != nil},
+	}
+}
+
+// inTx runs fn in a transaction, committing only if it succeeds.
+func (s *Store) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// checkOrderRefs rejects customer, employee and shipper ids that do not
+// exist. Deleted customers cannot take new orders.
+func checkOrderRefs(ctx context.Context, tx *sql.Tx, in models.OrderInput) error {
+	return checkRefs(ctx, tx, []ref{
+		{column{"customer_id", in.CustomerID, in.CustomerID != nil}, "SELECT EXISTS (SELECT 1 FROM customers WHERE customer_id = $1 AND deleted_at IS NULL)"},
+		{column{"employee_id", in.EmployeeID, in.EmployeeID != nil}, "SELECT EXISTS (SELECT 1 FROM employees WHERE employee_id = $1)"},
+		{column{"ship_via", in.ShipVia, in.ShipVia != nil}, "SELECT EXISTS (SELECT 1 FROM shippers WHERE shipper_id = $1)"},
+	})
+}
+
+// lockOrder locks the order row for the rest of the transaction.
+func lockOrder(ctx context.Context, tx *sql.Tx, id int) error {
+	err := tx.QueryRowContext(ctx, "SELECT order_id FROM orders WHERE order_id = $1 FOR UPDATE", id).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return store.ErrNotFound
+	}
+	return err
+}
+
+// GetOrder returns a single order as GET /orders would.
+func (s *Store) GetOrder(ctx context.Context, id int) (models.Order, error) {
+	orders, err := lookup[models.Order](ctx, s.db, ordersView, "order_id", id)
+	if err != nil {
+		return models.Order{}, err
+	}
+	if len(orders) == 0 {
+		return models.Order{}, store.ErrNotFound
+	}
+	return orders[0], nil
+}
+
+func (s *Store) orderDetails(ctx context.Context, id int) ([]models.OrderDetail, error) {
+	return lookup[models.OrderDetail](ctx, s.db, orderDetailsView, "order_id", id)
+}
+
+// CreateOrder inserts an order and its line items, taking their quantities
+// out of stock.
+func (s *Store) CreateOrder(ctx context.Context, in models.OrderInput) (models.Order, []models.OrderDetail, error) {
+	if in.OrderDate == nil {
+		today := time.Now().Format(time.DateOnly)
+		in.OrderDate = &today
+	}
+
+	var id int
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := checkOrderRefs(ctx, tx, in); err != nil {
+			return err
+		}
+
+		// order_id has no default in the Northwind schema, so ids are
+		// allocated under a lock that only blocks other writers.
+		if _, err := tx.ExecContext(ctx, "LOCK TABLE orders IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+			return err
+		}
+		if err := tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(order_id), 0) + 1 FROM orders").Scan(&id); err != nil {
+			return err
+		}
+
+		names := []string{"order_id"}
+		placeholders := []string{"$1"}
+		args := []any{id}
+		for _, c := range orderColumns(in) {
+			args = append(args, c.value)
+			names = append(names, c.name)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+		query := fmt.Sprintf("INSERT INTO orders (%s) VALUES (%s)", strings.Join(names, ", "), strings.Join(placeholders, ", "))
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+
+		for _, i := range byProduct(in.Details) {
+			if err := writeLine(ctx, tx, id, i, in.Details[i], nil, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Order{}, nil, err
+	}
+
+	order, err := s.GetOrder(ctx, id)
+	if err != nil {
+		return models.Order{}, nil, err
+	}
+	details, err := s.orderDetails(ctx, id)
+	return order, details, err
+}
+
+// ReplaceOrder overwrites every column of an order; nil fields become NULL.
+func (s *Store) ReplaceOrder(ctx context.Context, id int, in models.OrderInput) (models.Order, error) {
+	return s.updateOrder(ctx, id, in, false)
+}
+
+// PatchOrder overwrites only the columns set in in.
+func (s *Store) PatchOrder(ctx context.Context, id int, in models.OrderInput) (models.Order, error) {
+	return s.updateOrder(ctx, id, in, true)
+}
+
+func (s *Store) updateOrder(ctx context.Context, id int, in models.OrderInput, partial bool) (models.Order, error) {
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := lockOrder(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := checkOrderRefs(ctx, tx, in); err != nil {
+			return err
+		}
+
+		sets := []string{}
+		args := []any{}
+		for _, c := range orderColumns(in) {
+			if partial && !c.set {
+				continue
+			}
+			args = append(args, c.value)
+			sets = append(sets, fmt.Sprintf("%s = $%d", c.name, len(args)))
+		}
+		if len(sets) == 0 {
+			return nil
+		}
+
+		args = append(args, id)
+		query := fmt.Sprintf("UPDATE orders SET %s WHERE order_id = $%d", strings.Join(sets, ", "), len(args))
+		_, err := tx.ExecContext(ctx, query, args...)
+		return err
+	})
+	if err != nil {
+		return models.Order{}, err
+	}
+	return s.GetOrder(ctx, id)
+}
+
+// DeleteOrder removes an order and returns its line items to stock.
+func (s *Store) DeleteOrder(ctx context.Context, id int) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := lockOrder(ctx, tx, id); err != nil {
+			return err
+		}
+		current, err := currentLines(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		for _, productID := range productIDs(current) {
+			if err := removeLine(ctx, tx, id, productID, current[productID]); err != nil {
+				return err
+			}
+		}
+		_, err = tx.ExecContext(ctx, "DELETE FROM orders WHERE order_id = $1", id)
+		return err
+	})
+}
+
+// AddOrderDetails adds products that are not yet on the order.
+func (s *Store) AddOrderDetails(ctx context.Context, id int, lines []models.OrderDetailInput) ([]models.OrderDetail, error) {
+	return s.changeLines(ctx, id, func(tx *sql.Tx, current map[int]line) error {
+		for _, i := range byProduct(lines) {
+			l := lines[i]
+			if _, ok := current[l.ProductID]; ok {
+				return &store.InvalidError{Field: fmt.Sprintf("details[%d].product_id", i), Message: "is already on the order; use PATCH to change it"}
+			}
+			if err := writeLine(ctx, tx, id, i, l, nil, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReplaceOrderDetails makes lines the order's complete set of line items.
+// Stock moves by the net change for each product.
+func (s *Store) ReplaceOrderDetails(ctx context.Context, id int, lines []models.OrderDetailInput) ([]models.OrderDetail, error) {
+	return s.changeLines(ctx, id, func(tx *sql.Tx, current map[int]line) error {
+		// Writes and removals go in one pass in product order, so their
+		// stock updates are ordered like every other writer's.
+		next := map[int]int{}
+		for i, l := range lines {
+			next[l.ProductID] = i
+		}
+		ids := productIDs(current)
+		for productID := range next {
+			if _, ok := current[productID]; !ok {
+				ids = append(ids, productID)
+			}
+		}
+		slices.Sort(ids)
+		for _, productID := range ids {
+			cur, onOrder := current[productID]
+			i, kept := next[productID]
+			var err error
+			switch {
+			case !kept:
+				err = removeLine(ctx, tx, id, productID, cur)
+			case onOrder:
+				err = writeLine(ctx, tx, id, i, lines[i], &cur, false)
+			default:
+				err = writeLine(ctx, tx, id, i, lines[i], nil, false)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// PatchOrderDetails changes line items already on the order.
+func (s *Store) PatchOrderDetails(ctx context.Context, id int, lines []models.OrderDetailInput) ([]models.OrderDetail, error) {
+	return s.changeLines(ctx, id, func(tx *sql.Tx, current map[int]line) error {
+		for _, i := range byProduct(lines) {
+			l := lines[i]
+			cur, ok := current[l.ProductID]
+			if !ok {
+				return &store.InvalidError{Field: fmt.Sprintf("details[%d].product_id", i), Message: "is not on the order"}
+			}
+			if err := writeLine(ctx, tx, id, i, l, &cur, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteOrderDetails removes the given products from the order, or every line
+// item when productIDs is empty, returning their units to stock.
+func (s *Store) DeleteOrderDetails(ctx context.Context, id int, ids []int) ([]models.OrderDetail, error) {
+	return s.changeLines(ctx, id, func(tx *sql.Tx, current map[int]line) error {
+		if len(ids) == 0 {
+			ids = productIDs(current)
+		}
+		for _, productID := range slices.Sorted(slices.Values(ids)) {
+			cur, ok := current[productID]
+			if !ok {
+				return &store.InvalidError{Field: "product_id", Message: fmt.Sprintf("%d is not on the order", productID)}
+			}
+			if err := removeLine(ctx, tx, id, productID, cur); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// changeLines locks an order, hands its current line items to fn and returns
+// the line items after the transaction commits.
+func (s *Store) changeLines(ctx context.Context, id int, fn func(tx *sql.Tx, current map[int]line) error) ([]models.OrderDetail, error) {
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := lockOrder(ctx, tx, id); err != nil {
+			return err
+		}
+		current, err := currentLines(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		return fn(tx, current)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.orderDetails(ctx, id)
+}
+
+// line is the stored state of one order_details row.
+type line struct {
+	quantity  int
+	unitPrice float64
+	discount  float64
+}
+
+// productIDs lists the products of lines in ascending order. Every writer
+// updates stock rows in ascending product order, so concurrent writers
+// cannot deadlock on them.
+func productIDs(lines map[int]line) []int {
+	ids := make([]int, 0, len(lines))
+	for id := range lines {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// byProduct returns the indexes of lines in ascending product order, so
+// they can be written in that order while errors still name the line the
+// client sent.
+func byProduct(lines []models.OrderDetailInput) []int {
+	idx := make([]int, len(lines))
+	for i := range idx {
+		idx[i] = i
+	}
+	slices.SortStableFunc(idx, func(a, b int) int {
+		return cmp.Compare(lines[a].ProductID, lines[b].ProductID)
+	})
+	return idx
+}
+
+func currentLines(ctx context.Context, tx *sql.Tx, orderID int) (map[int]line, error) {
+	rs, err := tx.QueryContext(ctx, `
+		SELECT product_id, quantity, unit_price, discount
+		FROM order_details
+		WHERE order_id = $1
+		FOR UPDATE
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	lines := map[int]line{}
+	for rs.Next() {
+		var productID int
+		var l line
+		if err := rs.Scan(&productID, &l.quantity, &l.unitPrice, &l.discount); err != nil {
+			return nil, err
+		}
+		lines[productID] = l
+	}
+	return lines, rs.Err()
+}
+
+// writeLine inserts in as line i of the order, or updates it when prev is the
+// stored row. With merge set, fields missing from in keep their stored
+// values; otherwise they default to the product's list price and no
+// discount.
+func writeLine(ctx context.Context, tx *sql.Tx, orderID, i int, in models.OrderDetailInput, prev *line, merge bool) error {
+	var next line
+	if prev != nil && merge {
+		next = *prev
+	} else {
+		var discontinued, deleted bool
+		err := tx.QueryRowContext(ctx, "SELECT unit_price, discontinued, deleted_at IS NOT NULL FROM products WHERE product_id = $1", in.ProductID).
+			Scan(&next.unitPrice, &discontinued, &deleted)
+		if errors.Is(err, sql.ErrNoRows) {
+			return &store.InvalidError{Field: fmt.Sprintf("details[%d].product_id", i), Message: "does not reference an existing product"}
+		}
+		if err != nil {
+			return err
+		}
+		if prev == nil && deleted {
+			return &store.InvalidError{Field: fmt.Sprintf("details[%d].product_id", i), Message: "does not reference an existing product"}
+		}
+		if prev == nil && discontinued {
+			return &store.InvalidError{Field: fmt.Sprintf("details[%d].product_id", i), Message: "is discontinued"}
+		}
+	}
+	if in.Quantity != nil {
+		next.quantity = *in.Quantity
+	}
+	if in.UnitPrice != nil {
+		next.unitPrice = *in.UnitPrice
+	}
+	if in.Discount != nil {
+		next.discount = *in.Discount
+	}
+
+	delta := next.quantity
+	if prev != nil {
+		delta -= prev.quantity
+	}
+	if err := adjustStock(ctx, tx, in.ProductID, delta); err != nil {
+		return err
+	}
+
+	if prev == nil {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO order_details (order_id, product_id, unit_price, quantity, discount)
+			VALUES ($1, $2, $3, $4, $5)
+		`, orderID, in.ProductID, next.unitPrice, next.quantity, next.discount)
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `
+		UPDATE order_details
+		SET unit_price = $3, quantity = $4, discount = $5
+		WHERE order_id = $1 AND product_id = $2
+	`, orderID, in.ProductID, next.unitPrice, next.quantity, next.discount)
+	return err
+}
+
+func removeLine(ctx context.Context, tx *sql.Tx, orderID, productID int, l line) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM order_details WHERE order_id = $1 AND product_id = $2", orderID, productID); err != nil {
+		return err
+	}
+	return adjustStock(ctx, tx, productID, -l.quantity)
+}
+
+// adjustStock takes delta units of a product out of stock, or returns them
+// when delta is negative. The check and the decrement are one statement, so
+// concurrent orders cannot oversell.
+func adjustStock(ctx context.Context, tx *sql.Tx, productID, delta int) error {
+	if delta == 0 {
+		return nil
+	}
+	if delta < 0 {
+		_, err := tx.ExecContext(ctx, "UPDATE products SET units_in_stock = units_in_stock + $2 WHERE product_id = $1", productID, -delta)
+		return err
+	}
+
+	var left int
+	err := tx.QueryRowContext(ctx, `
+		UPDATE products
+		SET units_in_stock = units_in_stock - $2
+		WHERE product_id = $1 AND units_in_stock >= $2
+		RETURNING units_in_stock
+	`, productID, delta).Scan(&left)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	var available int
+	if err := tx.QueryRowContext(ctx, "SELECT units_in_stock FROM products WHERE product_id = $1", productID).Scan(&available); err != nil {
+		return err
+	}
+	return &store.StockError{ProductID: productID, Requested: delta, Available: available}
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+var ordersView = view{
+	query: `
+		SELECT
+			o.order_id,
+			o.customer_id,
+			c.company_name AS customer_name,
+			(e.first_name || ' ' || e.last_name) AS employee_name,
+			o.order_date,
+			o.required_date,
+			o.shipped_date,
+			o.ship_via,
+			s.company_name AS shipper_name,
+			o.freight,
+			o.ship_name,
+			o.ship_address,
+			o.ship_city,
+			o.ship_region,
+			o.ship_postal_code,
+			o.ship_country
+		FROM orders o
+		JOIN customers c ON o.customer_id = c.customer_id
+		JOIN employees e ON o.employee_id = e.employee_id
+		JOIN shippers s ON o.ship_via = s.shipper_id
+		{{where}}
+	`,
+	inner: filter.Columns{
+		"customer_id":      "o.customer_id",
+		"employee":         "e.first_name || ' ' || e.last_name",
+		"year":             "EXTRACT(YEAR FROM o.order_date)",
+		"country":          "o.ship_country",
+		"order_id":         "o.order_id",
+		"customer_name":    "c.company_name",
+		"employee_name":    "e.first_name || ' ' || e.last_name",
+		"order_date":       "o.order_date",
+		"required_date":    "o.required_date",
+		"shipped_date":     "o.shipped_date",
+		"ship_via":         "o.ship_via",
+		"shipper_name":     "s.company_name",
+		"freight":          "o.freight",
+		"ship_name":        "o.ship_name",
+		"ship_address":     "o.ship_address",
+		"ship_city":        "o.ship_city",
+		"ship_region":      "o.ship_region",
+		"ship_postal_code": "o.ship_postal_code",
+		"ship_country":     "o.ship_country",
+	},
+	order:  "order_date DESC, order_id DESC",
+	owners: orderOwners,
+}
+
+var orderDetailsView = view{
+	query: `
+		SELECT
+			od.order_id,
+			p.product_id,
+			p.product_name,
+			ca.category_name,
+			s.company_name AS supplier_name,
+			od.unit_price,
+			od.quantity,
+			od.discount,
+			(od.unit_price * od.quantity * (1 - od.discount)) AS extended_price
+		FROM order_details od
+		JOIN products p ON od.product_id = p.product_id
+		JOIN categories ca ON p.category_id = ca.category_id
+		JOIN suppliers s ON p.supplier_id = s.supplier_id
+		JOIN orders o ON od.order_id = o.order_id
+		{{where}}
+	`,
+	inner: filter.Columns{
+		"order_id":      "od.order_id",
+		"customer_id":   "o.customer_id",
+		"product_id":    "p.product_id",
+		"product_name":  "p.product_name",
+		"category_name": "ca.category_name",
+		"supplier_name": "s.company_name",
+	},
+	order:  "order_id, product_name, product_id",
+	owners: orderOwners,
+}
+
+// ListOrders runs the query behind GET /orders.
+func (s *Store) ListOrders(ctx context.Context, q store.Query) (store.Rows[models.Order], error) {
+	return list[models.Order](ctx, s.db, ordersView, q)
+}
+
+// ListOrderDetails runs the query behind GET /orders/details.
+func (s *Store) ListOrderDetails(ctx context.Context, q store.Query) (store.Rows[models.OrderDetail], error) {
+	return list[models.OrderDetail](ctx, s.db, orderDetailsView, q)
+}
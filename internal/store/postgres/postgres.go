@@ -0,0 +1,20 @@
+// Package postgres implements store.Store against the Northwind Postgres
+// schema.
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+var _ store.Store = (*Store)(nil)
+
+// Store runs every query against a single *sql.DB.
+type Store struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Store {
+	return &Store{db: db}
+}
@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+var topProductsView = view{
+	query: `
+		SELECT
+			p.product_id,
+			p.product_name,
+			ca.category_name,
+			s.company_name AS supplier_name,
+			SUM(od.quantity) AS units_sold,
+			SUM(od.unit_price * od.quantity * (1 - od.discount)) AS total_revenue,
+			AVG(od.unit_price) AS average_price
+		FROM order_details od
+		JOIN orders o ON od.order_id = o.order_id
+		JOIN products p ON od.product_id = p.product_id
+		JOIN categories ca ON p.category_id = ca.category_id
+		JOIN suppliers s ON p.supplier_id = s.supplier_id
+		{{where}}
+		GROUP BY p.product_id, p.product_name, ca.category_name, s.company_name
+	`,
+	inner: filter.Columns{
+		"year":          "EXTRACT(YEAR FROM o.order_date)",
+		"product_id":    "p.product_id",
+		"product_name":  "p.product_name",
+		"category_name": "ca.category_name",
+		"supplier_name": "s.company_name",
+	},
+	order:  "total_revenue DESC, product_id",
+	owners: orderOwners,
+}
+
+var supplierPerformanceView = view{
+	query: `
+		WITH supplier_stats AS (
+			SELECT
+				s.supplier_id,
+				s.company_name AS supplier_name,
+				s.country,
+				COUNT(DISTINCT p.product_id) AS product_count,
+				SUM(od.quantity) AS units_sold,
+				SUM(od.unit_price * od.quantity * (1 - od.discount)) AS total_revenue,
+				AVG(od.unit_price) AS average_price,
+				ca.category_name,
+				ROW_NUMBER() OVER (PARTITION BY s.supplier_id ORDER BY SUM(od.unit_price * od.quantity) DESC) AS cat_rank
+			FROM order_details od
+			JOIN orders o ON od.order_id = o.order_id
+			JOIN products p ON od.product_id = p.product_id
+			JOIN categories ca ON p.category_id = ca.category_id
+			JOIN suppliers s ON p.supplier_id = s.supplier_id
+			{{where}}
+			GROUP BY s.supplier_id, s.company_name, s.country, ca.category_name
+		)
+		SELECT
+			supplier_id,
+			supplier_name,
+			country,
+			product_count,
+			units_sold,
+			total_revenue,
+			average_price,
+			category_name AS top_category
+		FROM supplier_stats
+		WHERE cat_rank = 1
+	`,
+	inner: filter.Columns{
+		"year": "EXTRACT(YEAR FROM o.order_date)",
+	},
+	outer: filter.Columns{
+		"supplier_id":   "supplier_id",
+		"supplier_name": "supplier_name",
+		"country":       "country",
+		"top_category":  "top_category",
+	},
+	order:  "total_revenue DESC, supplier_id",
+	owners: orderOwners,
+}
+
+var inventoryStatusView = view{
+	query: `
+		SELECT
+			p.product_id,
+			p.product_name,
+			s.company_name AS supplier_name,
+			ca.category_name,
+			p.units_in_stock,
+			p.reorder_level,
+			p.discontinued,
+			CASE WHEN p.units_in_stock <= p.reorder_level THEN true ELSE false END AS needs_reorder
+		FROM products p
+		JOIN suppliers s ON p.supplier_id = s.supplier_id
+		JOIN categories ca ON p.category_id = ca.category_id
+		{{where}}
+	`,
+	inner: filter.Columns{
+		"product_id":     "p.product_id",
+		"product_name":   "p.product_name",
+		"supplier_name":  "s.company_name",
+		"category_name":  "ca.category_name",
+		"units_in_stock": "p.units_in_stock",
+		"discontinued":   "p.discontinued",
+		"needs_reorder":  "p.units_in_stock <= p.reorder_level",
+	},
+	order:  "needs_reorder DESC, units_in_stock ASC, product_id",
+	owners: unowned,
+}
+
+// TopProducts runs the query behind GET /analytics/top-products.
+func (s *Store) TopProducts(ctx context.Context, q store.Query) (store.Rows[models.TopProduct], error) {
+	return list[models.TopProduct](ctx, s.db, topProductsView, q)
+}
+
+// SupplierPerformance runs the query behind GET /analytics/supplier-performance.
+func (s *Store) SupplierPerformance(ctx context.Context, q store.Query) (store.Rows[models.SupplierPerformance], error) {
+	return list[models.SupplierPerformance](ctx, s.db, supplierPerformanceView, q)
+}
+
+// InventoryStatus runs the query behind GET /analytics/inventory-status.
+func (s *Store) InventoryStatus(ctx context.Context, q store.Query) (store.Rows[models.InventoryStatus], error) {
+	return list[models.InventoryStatus](ctx, s.db, inventoryStatusView, q)
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+var productsTable = table{name: "products", key: "product_id", view: productsView}
+
+// productColumns lists the writable product columns. Supplier and category
+// names are derived from the ids and cannot be written.
+func productColumns(p models.Product) []column {
+	return []column{
+		{"product_name", p.ProductName, true},
+		{"supplier_id", p.SupplierID, true},
+		{"category_id", p.CategoryID, true},
+		{"quantity_per_unit", p.QuantityPerUnit, true},
+		{"unit_price", p.UnitPrice, true},
+		{"units_in_stock", p.UnitsInStock, true},
+		{"discontinued", p.Discontinued, true},
+	}
+}
+
+// checkProductRefs rejects supplier and category ids that do not exist. A
+// deleted supplier cannot take on products.
+func checkProductRefs(ctx context.Context, tx *sql.Tx, p models.Product) error {
+	return checkRefs(ctx, tx, []ref{
+		{column{"supplier_id", p.SupplierID, true}, "SELECT EXISTS (SELECT 1 FROM suppliers WHERE supplier_id = $1 AND deleted_at IS NULL)"},
+		{column{"category_id", p.CategoryID, true}, "SELECT EXISTS (SELECT 1 FROM categories WHERE category_id = $1)"},
+	})
+}
+
+// GetProduct returns a single product as GET /products would.
+func (s *Store) GetProduct(ctx context.Context, id int) (models.Product, error) {
+	return get[models.Product](ctx, s.db, productsTable, id)
+}
+
+// CreateProduct inserts a product under the next free id.
+func (s *Store) CreateProduct(ctx context.Context, p models.Product) (models.Product, error) {
+	var id int
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := checkProductRefs(ctx, tx, p); err != nil {
+			return err
+		}
+		var err error
+		if id, err = nextID(ctx, tx, productsTable); err != nil {
+			return err
+		}
+		ok, err := insert(ctx, tx, productsTable, id, productColumns(p))
+		if err == nil && !ok {
+			err = store.ErrExists
+		}
+		return err
+	})
+	if err != nil {
+		return models.Product{}, err
+	}
+	return s.GetProduct(ctx, id)
+}
+
+// UpdateProduct overwrites every writable field of a product.
+func (s *Store) UpdateProduct(ctx context.Context, p models.Product, ifMatch string) (models.Product, error) {
+	return update[models.Product](ctx, s, productsTable, p.ProductID, ifMatch, productColumns(p), func(tx *sql.Tx) error {
+		return checkProductRefs(ctx, tx, p)
+	})
+}
+
+// DeleteProduct hides a product. Existing order lines keep it; new ones
+// cannot add it.
+func (s *Store) DeleteProduct(ctx context.Context, id int, ifMatch string) error {
+	return softDelete[models.Product](ctx, s, productsTable, id, ifMatch)
+}
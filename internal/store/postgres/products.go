@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+var productsView = view{
+	query: `
+		SELECT
+			p.product_id,
+			p.product_name,
+			p.supplier_id,
+			s.company_name AS supplier_name,
+			p.category_id,
+			ca.category_name,
+			p.quantity_per_unit,
+			p.unit_price,
+			p.units_in_stock,
+			p.discontinued
+		FROM products p
+		JOIN suppliers s ON p.supplier_id = s.supplier_id
+		JOIN categories ca ON p.category_id = ca.category_id
+		{{where}}
+	`,
+	scope: []string{"p.deleted_at IS NULL"},
+	inner: filter.Columns{
+		"product_id":    "p.product_id",
+		"product_name":  "p.product_name",
+		"supplier_id":   "p.supplier_id",
+		"supplier_name": "s.company_name",
+		"category_id":   "p.category_id",
+		"category_name": "ca.category_name",
+		"unit_price":    "p.unit_price",
+		"discontinued":  "p.discontinued",
+	},
+	order:  "product_name, product_id",
+	owners: unowned,
+}
+
+// ListProducts runs the query behind GET /products.
+func (s *Store) ListProducts(ctx context.Context, q store.Query) (store.Rows[models.Product], error) {
+	return list[models.Product](ctx, s.db, productsView, q)
+}
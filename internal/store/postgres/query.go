@@ -0,0 +1,381 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/lib/pq"
+	"github.com/nicholasraynes/northwind-api/internal/fieldset"
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/sorting"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// whereToken marks where a view's inner WHERE clause is spliced in.
+const whereToken = "{{where}}"
+
+// totalColumn carries COUNT(*) OVER () so one round trip returns both the
+// page and the size of the filtered collection.
+const totalColumn = "_total_count"
+
+// view describes one collection. Its query yields a row per result with
+// columns named after the model's db tags; everything else (outer filters,
+// ordering, paging) is applied by wrapping it.
+type view struct {
+	// query is the SQL for the collection. If it contains whereToken, the
+	// scope and inner filters are rendered there as a WHERE clause.
+	query string
+	// scope holds conditions on raw table columns that always apply, such as
+	// hiding soft-deleted rows.
+	scope []string
+	// inner maps filters that must apply before grouping to raw table
+	// columns inside query.
+	inner filter.Columns
+	// outer maps filters to output columns of query.
+	outer filter.Columns
+	// where adds conditions on output columns that a plain column mapping
+	// cannot express.
+	where func(f filter.Set, args *[]any) []string
+	// build, when set, renders the base query in place of query, scope and
+	// inner, for views assembled from other filtered views.
+	build func(q store.Query, args *[]any) string
+	// order is the default ORDER BY over output columns. It must end with a
+	// unique key so pages are deterministic, and it breaks ties left by a
+	// client-supplied sort.
+	order string
+	// owners maps each store.Row* dimension to a condition on raw table
+	// columns, with %s for the value, that keeps only the rows a caller
+	// confined on it may see. A dimension mapped to "" does not narrow the
+	// view; confined callers may read it whole. Confining on a dimension the
+	// view does not map fails with store.ErrOutOfScope.
+	owners map[string]string
+}
+
+// orderOwners confines the views over orders, which always alias the table
+// as o.
+var orderOwners = map[string]string{
+	store.RowEmployee: "o.employee_id = %s",
+	store.RowCustomer: "o.customer_id = %s",
+}
+
+// unowned is for views whose rows belong to no employee or customer, such
+// as the product catalogue.
+var unowned = map[string]string{
+	store.RowEmployee: "",
+	store.RowCustomer: "",
+}
+
+// confine returns v narrowed to the rows rs allows, as extra scope
+// conditions. The values are quoted as literals, since scope conditions take
+// no arguments; they come from a key or token, not the request.
+func (v view) confine(rs models.RowScope) (view, error) {
+	if len(rs) == 0 {
+		return v, nil
+	}
+	scope := slices.Clone(v.scope)
+	for _, dim := range slices.Sorted(maps.Keys(rs)) {
+		cond, ok := v.owners[dim]
+		if !ok {
+			return view{}, store.ErrOutOfScope
+		}
+		if cond != "" {
+			scope = append(scope, fmt.Sprintf(cond, pq.QuoteLiteral(rs[dim])))
+		}
+	}
+	if len(scope) > len(v.scope) && (v.build != nil || !strings.Contains(v.query, whereToken)) {
+		// Nowhere to put the conditions.
+		return view{}, store.ErrOutOfScope
+	}
+	v.scope = scope
+	v.owners = unowned
+	return v, nil
+}
+
+// confined is v as the caller ctx carries may see it.
+func confined(ctx context.Context, v view) (view, error) {
+	return v.confine(store.RowScopeFrom(ctx))
+}
+
+// filtered renders v with q's filters applied, appending bound values to
+// args. columns maps the JSON names used by q.Expr to output columns.
+func (v view) filtered(q store.Query, columns map[string]string, args *[]any) string {
+	var base string
+	if v.build != nil {
+		base = v.build(q, args)
+	} else {
+		inner := append(slices.Clone(v.scope), q.Filters.Where(v.inner, args)...)
+		base = strings.Replace(v.query, whereToken, where(inner), 1)
+	}
+
+	outer := q.Filters.Where(v.outer, args)
+	if v.where != nil {
+		outer = append(outer, v.where(q.Filters, args)...)
+	}
+	if q.Expr != nil {
+		outer = append(outer, q.Expr.SQL(columns, args))
+	}
+	return fmt.Sprintf("SELECT * FROM (%s) AS t%s", base, where(outer))
+}
+
+// page renders the statement for the page of v requested by q. columns maps
+// the JSON names used by q.Sort and q.Fields to output columns.
+func (v view) page(q store.Query, columns map[string]string, args *[]any) string {
+	filtered := v.filtered(q, columns, args)
+	*args = append(*args, q.Page.Limit, q.Page.Offset)
+	return fmt.Sprintf(
+		"SELECT %s, COUNT(*) OVER () AS %s FROM (%s) AS f ORDER BY %s LIMIT $%d OFFSET $%d",
+		selectList(q.Fields, columns), totalColumn, filtered, v.orderBy(q.Sort, columns), len(*args)-1, len(*args),
+	)
+}
+
+// selectList renders the output columns for projection s, or every column
+// when s is nil.
+func selectList(s fieldset.Set, columns map[string]string) string {
+	if s == nil {
+		return "f.*"
+	}
+	cols := []string{}
+	for _, name := range s {
+		if col, ok := columns[name]; ok {
+			cols = append(cols, "f."+col)
+		}
+	}
+	if len(cols) == 0 {
+		return "f.*"
+	}
+	return strings.Join(cols, ", ")
+}
+
+// orderBy renders o ahead of the default order. Sort keys are validated
+// against a whitelist before they reach the store, and any key without a
+// column is skipped rather than interpolated.
+func (v view) orderBy(o sorting.Order, columns map[string]string) string {
+	keys := []string{}
+	for _, k := range o {
+		col, ok := columns[k.Field]
+		if !ok {
+			continue
+		}
+		if k.Desc {
+			keys = append(keys, col+" DESC NULLS LAST")
+		} else {
+			keys = append(keys, col+" ASC NULLS LAST")
+		}
+	}
+	return strings.Join(append(keys, v.order), ", ")
+}
+
+// count renders a statement returning the size of the filtered collection.
+func (v view) count(q store.Query, columns map[string]string, args *[]any) string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS f", v.filtered(q, columns, args))
+}
+
+func where(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// lookup returns every row of v whose inner column for filter name equals
+// value, in the view's default order.
+func lookup[T any](ctx context.Context, db queryer, v view, name string, value any) ([]T, error) {
+	v, err := confined(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	conds := append(slices.Clone(v.scope), fmt.Sprintf("%s = $1", v.inner[name]))
+	query := strings.Replace(v.query, whereToken, where(conds), 1)
+	query = fmt.Sprintf("SELECT * FROM (%s) AS t ORDER BY %s", query, v.order)
+
+	rs, err := db.QueryContext(ctx, query, value)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := rs.Columns()
+	if err != nil {
+		rs.Close()
+		return nil, err
+	}
+	res, err := store.Collect[T](&rows[T]{ctx: ctx, db: db, v: v, rs: rs, cols: cols, fields: fieldsOf(reflect.TypeFor[T]())})
+	return res.Rows, err
+}
+
+// list runs v for q and returns a cursor that scans each row into a T by
+// matching column names to db struct tags.
+func list[T any](ctx context.Context, db queryer, v view, q store.Query) (store.Rows[T], error) {
+	v, err := confined(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	fields := fieldsOf(reflect.TypeFor[T]())
+
+	args := []any{}
+	rs, err := db.QueryContext(ctx, v.page(q, fields.columns, &args), args...)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := rs.Columns()
+	if err != nil {
+		rs.Close()
+		return nil, err
+	}
+	return &rows[T]{ctx: ctx, db: db, v: v, q: q, rs: rs, cols: cols, fields: fields}, nil
+}
+
+// objects runs v for q like list, but returns each row as a
+// fieldset.Object of its columns, for views whose columns vary by request.
+// names are v's output columns, which q may sort by.
+func objects(ctx context.Context, db queryer, v view, q store.Query, names []string) (store.Rows[fieldset.Object], error) {
+	v, err := confined(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+	fields := fields{columns: map[string]string{}}
+	for _, name := range names {
+		fields.columns[name] = name
+	}
+
+	args := []any{}
+	rs, err := db.QueryContext(ctx, v.page(q, fields.columns, &args), args...)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := rs.Columns()
+	if err != nil {
+		rs.Close()
+		return nil, err
+	}
+	return &rows[fieldset.Object]{ctx: ctx, db: db, v: v, q: q, rs: rs, cols: cols, fields: fields}, nil
+}
+
+// rows implements store.Rows over an open result set.
+type rows[T any] struct {
+	ctx    context.Context
+	db     queryer
+	v      view
+	q      store.Query
+	rs     *sql.Rows
+	cols   []string
+	fields fields
+
+	row   T
+	total int
+	seen  bool
+	done  bool
+	err   error
+}
+
+func (r *rows[T]) Next() bool {
+	if r.done {
+		return false
+	}
+	if !r.rs.Next() {
+		r.finish()
+		return false
+	}
+
+	var row T
+	obj, dynamic := any(&row).(*fieldset.Object)
+	rv := reflect.ValueOf(&row).Elem()
+	dest := make([]any, len(r.cols))
+	for i, col := range r.cols {
+		switch idx, ok := r.fields.index[col]; {
+		case col == totalColumn:
+			dest[i] = &r.total
+		case ok && !dynamic:
+			dest[i] = rv.FieldByIndex(idx).Addr().Interface()
+		default:
+			dest[i] = new(any)
+		}
+	}
+	if err := r.rs.Scan(dest...); err != nil {
+		r.err = err
+		r.done = true
+		r.rs.Close()
+		return false
+	}
+	if dynamic {
+		for i, col := range r.cols {
+			if col == totalColumn {
+				continue
+			}
+			value := *dest[i].(*any)
+			// The driver returns text-like types it has no Go type for as
+			// bytes.
+			if b, ok := value.([]byte); ok {
+				value = string(b)
+			}
+			*obj = append(*obj, fieldset.Member{Name: col, Value: value})
+		}
+	}
+	r.row = row
+	r.seen = true
+	return true
+}
+
+// finish records the result set's error and, when the page came back empty,
+// looks up the total it could not carry.
+func (r *rows[T]) finish() {
+	r.done = true
+	if r.err = r.rs.Err(); r.err != nil {
+		return
+	}
+	// An offset past the end returns no rows, and with them no total.
+	if !r.seen && r.q.Page.Offset > 0 {
+		args := []any{}
+		r.err = r.db.QueryRowContext(r.ctx, r.v.count(r.q, r.fields.columns, &args), args...).Scan(&r.total)
+	}
+}
+
+func (r *rows[T]) Row() T       { return r.row }
+func (r *rows[T]) Total() int   { return r.total }
+func (r *rows[T]) Err() error   { return r.err }
+func (r *rows[T]) Close() error { return r.rs.Close() }
+
+// fields describes how a model's struct fields line up with result columns.
+type fields struct {
+	// index maps db tags to field indexes.
+	index map[string][]int
+	// columns maps JSON names to db tags.
+	columns map[string]string
+}
+
+var fieldCache sync.Map // reflect.Type -> fields
+
+// fieldsOf describes struct type t.
+func fieldsOf(t reflect.Type) fields {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.(fields)
+	}
+	fs := fields{index: map[string][]int{}, columns: map[string]string{}}
+	for _, f := range reflect.VisibleFields(t) {
+		tag := f.Tag.Get("db")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		fs.index[tag] = f.Index
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" {
+			name = f.Name
+		}
+		if name != "-" {
+			fs.columns[name] = tag
+		}
+	}
+	fieldCache.Store(t, fs)
+	return fs
+}
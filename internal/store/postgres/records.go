@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// table is a soft-deletable table written through the single-record
+// endpoints. Its view must map key as an inner filter.
+type table struct {
+	name string
+	key  string
+	view view
+}
+
+// ref is a column that must name an existing row elsewhere. query takes the
+// value as $1 and yields whether the row exists.
+type ref struct {
+	column
+	query string
+}
+
+// checkRefs rejects set columns whose reference does not resolve.
+func checkRefs(ctx context.Context, tx *sql.Tx, refs []ref) error {
+	for _, ref := range refs {
+		if !ref.set {
+			continue
+		}
+
+		var ok bool
+		if err := tx.QueryRowContext(ctx, ref.query, ref.value).Scan(&ok); err != nil {
+			return err
+		}
+		if !ok {
+			return &store.InvalidError{Field: ref.name, Message: "does not reference an existing record"}
+		}
+	}
+	return nil
+}
+
+// get returns the live row of t with key id as its view presents it.
+func get[T any](ctx context.Context, db queryer, t table, id any) (T, error) {
+	var zero T
+	found, err := lookup[T](ctx, db, t.view, t.key, id)
+	if err != nil {
+		return zero, err
+	}
+	if len(found) == 0 {
+		return zero, store.ErrNotFound
+	}
+	return found[0], nil
+}
+
+// lockVersion locks the live row of t with key id for the rest of the
+// transaction and checks that ifMatch names its current version.
+func lockVersion[T any](ctx context.Context, tx *sql.Tx, t table, id any, ifMatch string) error {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1 AND deleted_at IS NULL FOR UPDATE", t.key, t.name, t.key)
+	var locked any
+	err := tx.QueryRowContext(ctx, query, id).Scan(&locked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return store.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	current, err := get[T](ctx, tx, t, id)
+	if err != nil {
+		return err
+	}
+	if !store.Matches(ifMatch, current) {
+		return store.ErrVersionMismatch
+	}
+	return nil
+}
+
+// nextID allocates the next integer key of t. Like order ids, keys have no
+// default in the Northwind schema, so they are taken under a lock that only
+// blocks other writers. Soft-deleted rows keep their keys.
+func nextID(ctx context.Context, tx *sql.Tx, t table) (int, error) {
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("LOCK TABLE %s IN SHARE ROW EXCLUSIVE MODE", t.name)); err != nil {
+		return 0, err
+	}
+	var id int
+	err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT COALESCE(MAX(%s), 0) + 1 FROM %s", t.key, t.name)).Scan(&id)
+	return id, err
+}
+
+// insert adds a row to t with the key id and the given columns. It reports
+// whether a row was added; false means the key was already taken.
+func insert(ctx context.Context, tx *sql.Tx, t table, id any, columns []column) (bool, error) {
+	names := []string{t.key}
+	placeholders := []string{"$1"}
+	args := []any{id}
+	for _, c := range columns {
+		args = append(args, c.value)
+		names = append(names, c.name)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+	query := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO NOTHING",
+		t.name, strings.Join(names, ", "), strings.Join(placeholders, ", "), t.key,
+	)
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// update overwrites columns of the live row of t with key id, provided
+// ifMatch names its current version, and returns the row as stored.
+func update[T any](ctx context.Context, s *Store, t table, id any, ifMatch string, columns []column, check func(tx *sql.Tx) error) (T, error) {
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := lockVersion[T](ctx, tx, t, id, ifMatch); err != nil {
+			return err
+		}
+		if check != nil {
+			if err := check(tx); err != nil {
+				return err
+			}
+		}
+
+		sets := []string{}
+		args := []any{}
+		for _, c := range columns {
+			args = append(args, c.value)
+			sets = append(sets, fmt.Sprintf("%s = $%d", c.name, len(args)))
+		}
+		args = append(args, id)
+		query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = $%d", t.name, strings.Join(sets, ", "), t.key, len(args))
+		_, err := tx.ExecContext(ctx, query, args...)
+		return err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return get[T](ctx, s.db, t, id)
+}
+
+// softDelete hides the live row of t with key id, provided ifMatch names its
+// current version. The row stays so that history referring to it resolves.
+func softDelete[T any](ctx context.Context, s *Store, t table, id any, ifMatch string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if err := lockVersion[T](ctx, tx, t, id, ifMatch); err != nil {
+			return err
+		}
+		query := fmt.Sprintf("UPDATE %s SET deleted_at = now() WHERE %s = $1", t.name, t.key)
+		_, err := tx.ExecContext(ctx, query, id)
+		return err
+	})
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// salesSummary runs a sales-by-* preset of the semantic layer.
+func (s *Store) salesSummary(ctx context.Context, preset store.Selection, q store.Query) (store.Rows[models.SalesSummary], error) {
+	v, err := semanticView(preset, q.Filters)
+	if err != nil {
+		return nil, err
+	}
+	return list[models.SalesSummary](ctx, s.db, v, q)
+}
+
+// SalesByCountry runs the query behind GET /summary/sales-by-country.
+func (s *Store) SalesByCountry(ctx context.Context, q store.Query) (store.Rows[models.SalesSummary], error) {
+	return s.salesSummary(ctx, store.SalesByCountryPreset, q)
+}
+
+// SalesByCategory runs the query behind GET /summary/sales-by-category.
+func (s *Store) SalesByCategory(ctx context.Context, q store.Query) (store.Rows[models.SalesSummary], error) {
+	return s.salesSummary(ctx, store.SalesByCategoryPreset, q)
+}
+
+// SalesByEmployee runs the query behind GET /summary/sales-by-employee.
+func (s *Store) SalesByEmployee(ctx context.Context, q store.Query) (store.Rows[models.SalesSummary], error) {
+	return s.salesSummary(ctx, store.SalesByEmployeePreset, q)
+}
+
+// SalesByYear runs the query behind GET /summary/sales-by-year.
+func (s *Store) SalesByYear(ctx context.Context, q store.Query) (store.Rows[models.SalesSummary], error) {
+	return s.salesSummary(ctx, store.SalesByYearPreset, q)
+}
+
+// SalesByShipper runs the query behind GET /summary/sales-by-shipper.
+func (s *Store) SalesByShipper(ctx context.Context, q store.Query) (store.Rows[models.SalesSummary], error) {
+	return s.salesSummary(ctx, store.SalesByShipperPreset, q)
+}
+
+// comparedView joins each group of v under the request's filters to the
+// same group under prior. Groups that only sold in the prior period are left
+// out, as they are from the uncompared summary. v must already be confined to
+// the caller's rows.
+func comparedView(v view, prior filter.Set) view {
+	return view{
+		build: func(q store.Query, args *[]any) string {
+			current := v.filtered(store.Query{Filters: q.Filters}, nil, args)
+			previous := v.filtered(store.Query{Filters: prior}, nil, args)
+			return fmt.Sprintf(`
+				SELECT
+					c.group_key,
+					c.total_sales,
+					c.order_count,
+					COALESCE(p.total_sales, 0) AS prior_total_sales,
+					c.total_sales - COALESCE(p.total_sales, 0) AS change,
+					(c.total_sales - p.total_sales) / NULLIF(p.total_sales, 0) * 100 AS pct_growth
+				FROM (%s) AS c
+				LEFT JOIN (%s) AS p ON p.group_key = c.group_key
+			`, current, previous)
+		},
+		order:  v.order,
+		owners: unowned,
+	}
+}
+
+// CompareSales runs a sales-by-* summary with compare_to.
+func (s *Store) CompareSales(ctx context.Context, by store.SalesSplit, q store.Query, prior filter.Set) (store.Rows[models.SalesComparison], error) {
+	preset, ok := store.SalesPresets[by]
+	if !ok {
+		return nil, fmt.Errorf("no sales summary by %s", by)
+	}
+	v, err := semanticView(preset, q.Filters)
+	if err != nil {
+		return nil, err
+	}
+	if v, err = confined(ctx, v); err != nil {
+		return nil, err
+	}
+	return list[models.SalesComparison](ctx, s.db, comparedView(v, prior), q)
+}
@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// periods maps each granularity to its date_trunc field, series step and
+// to_char label.
+var periods = map[store.Granularity]struct{ trunc, step, label string }{
+	store.Day:     {"day", "1 day", `YYYY-MM-DD`},
+	store.Week:    {"week", "1 week", `IYYY-"W"IW`},
+	store.Month:   {"month", "1 month", `YYYY-MM`},
+	store.Quarter: {"quarter", "3 months", `YYYY-"Q"Q`},
+	store.Year:    {"year", "1 year", `YYYY`},
+}
+
+// salesOverTimeFilters maps the filters of SalesOverTime to semantic
+// dimensions.
+var salesOverTimeFilters = map[string]string{
+	"country":       "country",
+	"category_name": "category",
+	"employee_name": "employee",
+	"shipper_name":  "shipper",
+}
+
+// salesOverTimeView reports sales per period from the first to the last
+// period covered by the order_date bounds in f, or by the matching orders
+// when unbounded. Every period appears for every group, with zero totals
+// where nothing sold. The totals come from the semantic layer, with the
+// period as an extra dimension.
+func salesOverTimeView(f filter.Set, g store.Granularity, split store.SalesSplit) (view, error) {
+	p := periods[g]
+
+	sel := store.Selection{
+		Measures: []store.Column{{Name: "revenue", As: "total_sales"}, {Name: "order_count"}},
+		Filters:  salesOverTimeFilters,
+	}
+	if split != store.NoSplit {
+		sel.Dimensions = []store.Column{{Name: string(split), As: "group_key", Text: true}}
+	}
+	sem, err := resolve(sel, f)
+	if err != nil {
+		return view{}, err
+	}
+	period := selected{term{expr: fmt.Sprintf("date_trunc('%s', o.order_date::timestamp)::date", p.trunc)}, "period_start"}
+	sem.dims = append([]selected{period}, sem.dims...)
+
+	// The bounds come from parsed dates, never raw input, so they are safe
+	// to inline.
+	first := "(SELECT MIN(period_start) FROM totals)"
+	last := "(SELECT MAX(period_start) FROM totals)"
+	from, to := f.Bounds("order_date")
+	if from != nil {
+		first = fmt.Sprintf("DATE '%s'", from.Format(time.DateOnly))
+	}
+	if to != nil {
+		last = fmt.Sprintf("DATE '%s'", to.AddDate(0, 0, -1).Format(time.DateOnly))
+	}
+
+	// Without a split there is a single group, present even when nothing
+	// sold, so a bounded range still yields its zero rows.
+	groups := "SELECT DISTINCT group_key FROM totals"
+	match := "t.period_start = pe.period_start AND t.group_key = g.group_key"
+	if split == store.NoSplit {
+		groups = "SELECT NULL::text AS group_key"
+		match = "t.period_start = pe.period_start"
+	}
+
+	return view{
+		query: fmt.Sprintf(`
+			WITH totals AS (%[1]s),
+			periods AS (
+				SELECT generate_series(
+					date_trunc('%[2]s', (%[3]s)::timestamp),
+					date_trunc('%[2]s', (%[4]s)::timestamp),
+					interval '%[5]s'
+				)::date AS period_start
+			),
+			groups AS (%[6]s)
+			SELECT
+				to_char(pe.period_start, '%[7]s') AS period,
+				pe.period_start,
+				g.group_key,
+				COALESCE(t.total_sales, 0) AS total_sales,
+				COALESCE(t.order_count, 0) AS order_count
+			FROM periods pe
+			CROSS JOIN groups g
+			LEFT JOIN totals t ON %[8]s
+		`, sem.sql(), p.trunc, first, last, p.step, groups, p.label, match),
+		inner:  sem.columns,
+		order:  "period_start, group_key",
+		owners: orderOwners,
+	}, nil
+}
+
+// SalesOverTime runs the query behind GET /summary/sales-over-time.
+func (s *Store) SalesOverTime(ctx context.Context, q store.Query, g store.Granularity, split store.SalesSplit) (store.Rows[models.SalesPeriod], error) {
+	v, err := salesOverTimeView(q.Filters, g, split)
+	if err != nil {
+		return nil, err
+	}
+	return list[models.SalesPeriod](ctx, s.db, v, q)
+}
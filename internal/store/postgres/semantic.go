@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nicholasraynes/northwind-api/internal/fieldset"
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// lineRevenue is the revenue of one order line after its discount.
+const lineRevenue = "od.unit_price * od.quantity * (1 - od.discount)"
+
+// source is a table the semantic layer can join onto order lines, which
+// always come with their order as od and o.
+type source struct {
+	name string
+	join string
+	// needs names the source the join condition refers to, if any.
+	needs string
+}
+
+// sources lists every joinable source, each after the one it needs.
+var sources = []source{
+	{name: "lines", join: "JOIN (SELECT order_id, COUNT(*) AS lines FROM order_details GROUP BY order_id) ol ON ol.order_id = o.order_id"},
+	{name: "products", join: "JOIN products p ON p.product_id = od.product_id"},
+	{name: "categories", join: "JOIN categories ca ON ca.category_id = p.category_id", needs: "products"},
+	{name: "suppliers", join: "JOIN suppliers su ON su.supplier_id = p.supplier_id", needs: "products"},
+	{name: "employees", join: "JOIN employees e ON e.employee_id = o.employee_id"},
+	{name: "shippers", join: "LEFT JOIN shippers s ON s.shipper_id = o.ship_via"},
+	{name: "customers", join: "JOIN customers c ON c.customer_id = o.customer_id"},
+}
+
+// term is the SQL for a measure or dimension and the source it reads, if
+// any beyond the order line and its order.
+type term struct {
+	expr   string
+	source string
+}
+
+// measureSQL holds the SQL for every store.Measures entry. Results are cast
+// to plain integer and float types so dynamic rows scan them as numbers.
+var measureSQL = map[string]term{
+	"revenue":     {expr: "SUM(" + lineRevenue + ")::float8"},
+	"order_count": {expr: "COUNT(DISTINCT o.order_id)"},
+	"units_sold":  {expr: "SUM(od.quantity)::bigint"},
+	"freight":     {expr: "SUM(o.freight / ol.lines)::float8", source: "lines"},
+	"avg_order":   {expr: "(SUM(" + lineRevenue + ") / NULLIF(COUNT(DISTINCT o.order_id), 0))::float8"},
+}
+
+// dimensionSQL holds the SQL for every store.Dimensions entry.
+var dimensionSQL = map[string]term{
+	"year":     {expr: "EXTRACT(YEAR FROM o.order_date)::int"},
+	"month":    {expr: "to_char(o.order_date, 'YYYY-MM')"},
+	"country":  {expr: "COALESCE(o.ship_country, 'Unknown')"},
+	"category": {expr: "COALESCE(ca.category_name, 'Unknown')", source: "categories"},
+	"employee": {expr: "e.first_name || ' ' || e.last_name", source: "employees"},
+	"shipper":  {expr: "COALESCE(s.company_name, 'Unknown')", source: "shippers"},
+	"supplier": {expr: "su.company_name", source: "suppliers"},
+	"customer": {expr: "c.company_name", source: "customers"},
+}
+
+// selected is a term under its output name.
+type selected struct {
+	term
+	as string
+}
+
+// semantic is a store.Selection resolved to SQL for one set of filters.
+type semantic struct {
+	dims     []selected
+	measures []selected
+	// columns maps the filters the selection accepts to SQL.
+	columns filter.Columns
+	// filtered lists the sources the filters in use read.
+	filtered []string
+	order    string
+}
+
+// resolve looks up the SQL for sel. Sources needed only to filter are joined
+// when f uses them.
+func resolve(sel store.Selection, f filter.Set) (semantic, error) {
+	var sem semantic
+	for _, c := range sel.Dimensions {
+		t, ok := dimensionSQL[c.Name]
+		if !ok {
+			return sem, fmt.Errorf("unknown dimension %q", c.Name)
+		}
+		if c.Text {
+			t.expr = "(" + t.expr + ")::text"
+		}
+		sem.dims = append(sem.dims, selected{t, c.Output()})
+	}
+	for _, c := range sel.Measures {
+		t, ok := measureSQL[c.Name]
+		if !ok {
+			return sem, fmt.Errorf("unknown measure %q", c.Name)
+		}
+		if c.Text {
+			t.expr = "(" + t.expr + ")::text"
+		}
+		sem.measures = append(sem.measures, selected{t, c.Output()})
+	}
+
+	filters := sel.Filters
+	if filters == nil {
+		filters = map[string]string{}
+		for name := range dimensionSQL {
+			filters[name] = name
+		}
+	}
+	sem.columns = filter.Columns{
+		"year":       dimensionSQL["year"].expr,
+		"order_date": "o.order_date",
+	}
+	for name, dim := range filters {
+		t, ok := dimensionSQL[dim]
+		if !ok {
+			return sem, fmt.Errorf("unknown dimension %q", dim)
+		}
+		sem.columns[name] = t.expr
+		if f.Has(name) && t.source != "" {
+			sem.filtered = append(sem.filtered, t.source)
+		}
+	}
+
+	keys := []string{}
+	for _, k := range sel.Order {
+		dir := "ASC"
+		if k.Desc {
+			dir = "DESC"
+		}
+		keys = append(keys, fmt.Sprintf("%s %s NULLS LAST", k.Field, dir))
+	}
+	for _, d := range sem.dims {
+		keys = append(keys, d.as)
+	}
+	if len(keys) == 0 {
+		// Without dimensions there is a single row.
+		keys = append(keys, sem.measures[0].as)
+	}
+	sem.order = strings.Join(keys, ", ")
+	return sem, nil
+}
+
+// sql renders the aggregate over order lines, leaving whereToken for the
+// filters.
+func (sem semantic) sql() string {
+	need := map[string]bool{}
+	for _, name := range sem.filtered {
+		need[name] = true
+	}
+	cols := []string{}
+	groups := []string{}
+	for _, d := range sem.dims {
+		need[d.source] = true
+		cols = append(cols, fmt.Sprintf("%s AS %s", d.expr, d.as))
+		groups = append(groups, d.expr)
+	}
+	for _, m := range sem.measures {
+		need[m.source] = true
+		cols = append(cols, fmt.Sprintf("%s AS %s", m.expr, m.as))
+	}
+
+	joins := []string{"JOIN orders o ON o.order_id = od.order_id"}
+	for i := len(sources) - 1; i >= 0; i-- {
+		if s := sources[i]; need[s.name] && s.needs != "" {
+			need[s.needs] = true
+		}
+	}
+	for _, s := range sources {
+		if need[s.name] {
+			joins = append(joins, s.join)
+		}
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM order_details od %s %s", strings.Join(cols, ", "), strings.Join(joins, " "), whereToken)
+	if len(groups) > 0 {
+		query += " GROUP BY " + strings.Join(groups, ", ")
+	}
+	return query
+}
+
+func (sem semantic) view() view {
+	return view{query: sem.sql(), inner: sem.columns, order: sem.order, owners: orderOwners}
+}
+
+// semanticView resolves sel into a view for filters f.
+func semanticView(sel store.Selection, f filter.Set) (view, error) {
+	sem, err := resolve(sel, f)
+	if err != nil {
+		return view{}, err
+	}
+	return sem.view(), nil
+}
+
+// Aggregate runs the query behind POST /query.
+func (s *Store) Aggregate(ctx context.Context, sel store.Selection, q store.Query) (store.Rows[fieldset.Object], error) {
+	v, err := semanticView(sel, q.Filters)
+	if err != nil {
+		return nil, err
+	}
+	return objects(ctx, s.db, v, q, sel.Outputs())
+}
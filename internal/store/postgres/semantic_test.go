@@ -0,0 +1,186 @@
+package postgres
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/page"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+// render returns the statement and arguments a page of sel runs under the
+// query string raw, parsed against spec. Arguments compare by their printed
+// form, as the filter package picks their integer width.
+func render(t *testing.T, sel store.Selection, spec filter.Spec, raw string) (string, []any) {
+	t.Helper()
+	values, _ := url.ParseQuery(raw)
+	f, err := spec.Parse(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := semanticView(sel, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := []any{}
+	q := store.Query{Filters: f, Page: page.Request{Limit: 10}}
+	return v.page(q, fieldsOf(reflect.TypeFor[models.SalesSummary]()).columns, &args), args
+}
+
+func TestSalesPresetsSQL(t *testing.T) {
+	tests := []struct {
+		name   string
+		preset store.Selection
+		spec   filter.Spec
+		query  string
+		want   []string
+		absent []string
+		args   []any
+	}{
+		{
+			name:   "year",
+			preset: store.SalesByYearPreset,
+			spec:   store.SalesByYearFilters,
+			// group_key is text, as in every other summary.
+			want:   []string{"(EXTRACT(YEAR FROM o.order_date)::int)::text AS group_key", "ORDER BY group_key LIMIT $1 OFFSET $2"},
+			absent: []string{"JOIN products", "JOIN employees", "JOIN shippers", "WHERE"},
+			args:   []any{10, 0},
+		},
+		{
+			name:   "category",
+			preset: store.SalesByCategoryPreset,
+			spec:   store.SalesByCategoryFilters,
+			query:  "year=1997&category_name=bev",
+			want: []string{
+				"(COALESCE(ca.category_name, 'Unknown'))::text AS group_key",
+				"JOIN products p ON p.product_id = od.product_id JOIN categories ca ON ca.category_id = p.category_id",
+				"WHERE (EXTRACT(YEAR FROM o.order_date)::int) = $1 AND LOWER((COALESCE(ca.category_name, 'Unknown'))) LIKE LOWER($2)",
+				"ORDER BY total_sales DESC NULLS LAST, group_key",
+			},
+			args: []any{1997, "%bev%", 10, 0},
+		},
+		{
+			name:   "country",
+			preset: store.SalesByCountryPreset,
+			spec:   store.SalesByCountryFilters,
+			query:  "order_date_from=1997-01&order_date_to=1997-03",
+			want:   []string{"COALESCE(o.ship_country, 'Unknown')", "o.order_date >= $1", "o.order_date < $2"},
+			absent: []string{"JOIN products", "JOIN customers"},
+		},
+		{
+			name:   "employee",
+			preset: store.SalesByEmployeePreset,
+			spec:   store.SalesByEmployeeFilters,
+			query:  "employee_name=king",
+			want:   []string{"JOIN employees e ON e.employee_id = o.employee_id", "LOWER((e.first_name || ' ' || e.last_name)) LIKE LOWER($1)"},
+			args:   []any{"%king%", 10, 0},
+		},
+		{
+			name:   "shipper",
+			preset: store.SalesByShipperPreset,
+			spec:   store.SalesByShipperFilters,
+			want:   []string{"LEFT JOIN shippers s ON s.shipper_id = o.ship_via", "GROUP BY (COALESCE(s.company_name, 'Unknown'))::text"},
+		},
+	}
+	for _, tt := range tests {
+		sql, args := render(t, tt.preset, tt.spec, tt.query)
+		for _, w := range tt.want {
+			if !strings.Contains(sql, w) {
+				t.Errorf("%s: SQL lacks %q:\n%s", tt.name, w, sql)
+			}
+		}
+		for _, a := range tt.absent {
+			if strings.Contains(sql, a) {
+				t.Errorf("%s: SQL has %q:\n%s", tt.name, a, sql)
+			}
+		}
+		if tt.args != nil && fmt.Sprint(args) != fmt.Sprint(tt.args) {
+			t.Errorf("%s: args %v, want %v", tt.name, args, tt.args)
+		}
+		if strings.Contains(sql, whereToken) {
+			t.Errorf("%s: SQL still holds %s", tt.name, whereToken)
+		}
+	}
+}
+
+// TestAggregateJoins checks that POST /query joins the sources of its
+// dimensions and filters, each after the one it depends on, and no others.
+func TestAggregateJoins(t *testing.T) {
+	sel := store.Selection{
+		Dimensions: []store.Column{{Name: "year"}, {Name: "country"}},
+		Measures:   []store.Column{{Name: "freight"}, {Name: "units_sold"}},
+	}
+	sql, args := render(t, sel, store.QueryFilters, "supplier=Exotic+Liquids,Tokyo+Traders&year=1997")
+
+	joins := []string{
+		"JOIN orders o",
+		"JOIN (SELECT order_id, COUNT(*) AS lines FROM order_details GROUP BY order_id) ol",
+		"JOIN products p",
+		"JOIN suppliers su",
+	}
+	at := -1
+	for _, j := range joins {
+		i := strings.Index(sql, j)
+		if i <= at {
+			t.Errorf("%q missing or out of order:\n%s", j, sql)
+		}
+		at = i
+	}
+	for _, j := range []string{"JOIN categories", "JOIN employees", "JOIN customers", "JOIN shippers"} {
+		if strings.Contains(sql, j) {
+			t.Errorf("SQL joins %q for nothing:\n%s", j, sql)
+		}
+	}
+	// Dimensions chosen by the caller keep their own types.
+	if !strings.Contains(sql, "EXTRACT(YEAR FROM o.order_date)::int AS year") {
+		t.Errorf("year is not an integer:\n%s", sql)
+	}
+	if want := []any{1997, "Exotic Liquids", "Tokyo Traders", 10, 0}; fmt.Sprint(args) != fmt.Sprint(want) {
+		t.Errorf("args %v, want %v", args, want)
+	}
+
+	if _, err := semanticView(store.Selection{Dimensions: []store.Column{{Name: "planet"}}}, filter.Set{}); err == nil {
+		t.Error("unknown dimension accepted")
+	}
+	if _, err := semanticView(store.Selection{Measures: []store.Column{{Name: "profit"}}}, filter.Set{}); err == nil {
+		t.Error("unknown measure accepted")
+	}
+}
+
+// TestSummariesConfined fails when a confined caller's condition is missing
+// from a summary or from either side of a comparison.
+func TestSummariesConfined(t *testing.T) {
+	v, err := semanticView(store.SalesByCountryPreset, filter.Set{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err = v.confine(models.RowScope{store.RowEmployee: "5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args := []any{}
+	sql := v.filtered(store.Query{}, nil, &args)
+	if !strings.Contains(sql, "WHERE o.employee_id = '5'") {
+		t.Errorf("summary not confined:\n%s", sql)
+	}
+
+	prior, _ := store.SalesByCountryFilters.Parse(url.Values{"year": {"1996"}})
+	current, _ := store.SalesByCountryFilters.Parse(url.Values{"year": {"1997"}})
+	args = []any{}
+	sql = comparedView(v, prior).filtered(store.Query{Filters: current}, nil, &args)
+	if n := strings.Count(sql, "o.employee_id = '5'"); n != 2 {
+		t.Errorf("comparison confined %d times, want 2:\n%s", n, sql)
+	}
+	if !strings.Contains(sql, "ON p.group_key = c.group_key") {
+		t.Errorf("comparison not joined on group_key:\n%s", sql)
+	}
+	if fmt.Sprint(args) != "[1997 1996]" {
+		t.Errorf("args %v, want current year then prior", args)
+	}
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+var suppliersTable = table{name: "suppliers", key: "supplier_id", view: suppliersView}
+
+func supplierColumns(sup models.Supplier) []column {
+	return []column{
+		{"company_name", sup.CompanyName, true},
+		{"contact_name", sup.ContactName, true},
+		{"contact_title", sup.ContactTitle, true},
+		{"city", sup.City, true},
+		{"country", sup.Country, true},
+		{"phone", sup.Phone, true},
+		{"fax", sup.Fax, true},
+		{"homepage", sup.HomePage, true},
+	}
+}
+
+// GetSupplier returns a single supplier as GET /suppliers would.
+func (s *Store) GetSupplier(ctx context.Context, id int) (models.Supplier, error) {
+	return get[models.Supplier](ctx, s.db, suppliersTable, id)
+}
+
+// CreateSupplier inserts a supplier under the next free id.
+func (s *Store) CreateSupplier(ctx context.Context, sup models.Supplier) (models.Supplier, error) {
+	var id int
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if id, err = nextID(ctx, tx, suppliersTable); err != nil {
+			return err
+		}
+		ok, err := insert(ctx, tx, suppliersTable, id, supplierColumns(sup))
+		if err == nil && !ok {
+			err = store.ErrExists
+		}
+		return err
+	})
+	if err != nil {
+		return models.Supplier{}, err
+	}
+	return s.GetSupplier(ctx, id)
+}
+
+// UpdateSupplier overwrites every field of a supplier.
+func (s *Store) UpdateSupplier(ctx context.Context, sup models.Supplier, ifMatch string) (models.Supplier, error) {
+	return update[models.Supplier](ctx, s, suppliersTable, sup.SupplierID, ifMatch, supplierColumns(sup), nil)
+}
+
+// DeleteSupplier hides a supplier. Their products keep listing them.
+func (s *Store) DeleteSupplier(ctx context.Context, id int, ifMatch string) error {
+	return softDelete[models.Supplier](ctx, s, suppliersTable, id, ifMatch)
+}
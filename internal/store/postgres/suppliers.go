@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/store"
+)
+
+var suppliersView = view{
+	query: `
+		SELECT supplier_id, company_name, contact_name, contact_title,
+		       city, country, phone, fax, homepage
+		FROM suppliers
+		{{where}}
+	`,
+	scope: []string{"deleted_at IS NULL"},
+	inner: filter.Columns{
+		"country":       "country",
+		"supplier_id":   "supplier_id",
+		"company_name":  "company_name",
+		"contact_name":  "contact_name",
+		"contact_title": "contact_title",
+		"city":          "city",
+		"phone":         "phone",
+		"fax":           "fax",
+	},
+	order:  "company_name, supplier_id",
+	owners: unowned,
+}
+
+// ListSuppliers runs the query behind GET /suppliers.
+func (s *Store) ListSuppliers(ctx context.Context, q store.Query) (store.Rows[models.Supplier], error) {
+	return list[models.Supplier](ctx, s.db, suppliersView, q)
+}
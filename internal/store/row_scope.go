@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nicholasraynes/northwind-api/internal/models"
+)
+
+// Dimensions a caller's rows can be confined to by a models.RowScope.
+const (
+	RowEmployee = "employee_id"
+	RowCustomer = "customer_id"
+)
+
+// RowDimensions lists every dimension, for validating input.
+var RowDimensions = []string{RowEmployee, RowCustomer}
+
+// ErrOutOfScope is returned when a confined caller asks for data that cannot
+// be narrowed to their rows, such as API keys.
+var ErrOutOfScope = errors.New("out of scope")
+
+type rowScopeKey struct{}
+
+// WithRowScope returns ctx carrying rs. Every read the store runs under ctx
+// sees only the rows rs allows, whatever the query's own filters say.
+func WithRowScope(ctx context.Context, rs models.RowScope) context.Context {
+	return context.WithValue(ctx, rowScopeKey{}, rs)
+}
+
+// RowScopeFrom returns the row scope ctx carries, or nil when the caller is
+// not confined.
+func RowScopeFrom(ctx context.Context) models.RowScope {
+	rs, _ := ctx.Value(rowScopeKey{}).(models.RowScope)
+	return rs
+}
+
+// CheckRowScope rejects unknown dimensions, employee IDs that are not
+// positive integers, and values the stored form cannot hold: empty ones,
+// ones padded with spaces and ones containing "," or "=".
+func CheckRowScope(rs models.RowScope) error {
+	for _, dim := range slices.Sorted(maps.Keys(rs)) {
+		value := rs[dim]
+		if !slices.Contains(RowDimensions, dim) {
+			return &InvalidError{Field: "row_scope", Message: "has unknown dimension " + strconv.Quote(dim) + "; use employee_id or customer_id"}
+		}
+		if n, err := strconv.Atoi(value); dim == RowEmployee && (err != nil || n < 1) {
+			return &InvalidError{Field: "row_scope", Message: "employee_id must be a positive integer"}
+		}
+		if value == "" || value != strings.TrimSpace(value) || strings.ContainsAny(value, ",=") {
+			return &InvalidError{Field: "row_scope", Message: dim + " must be non-empty, without surrounding spaces, \",\" or \"=\""}
+		}
+	}
+	return nil
+}
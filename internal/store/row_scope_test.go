@@ -0,0 +1,55 @@
+package store
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/nicholasraynes/northwind-api/internal/models"
+)
+
+// TestRowScopeRoundTrip fails when a row scope CheckRowScope accepts cannot
+// be read back as stored.
+func TestRowScopeRoundTrip(t *testing.T) {
+	valid := []models.RowScope{
+		{RowEmployee: "5"},
+		{RowCustomer: "ALFKI"},
+		{RowCustomer: "O'Brien & Co"},
+		{RowEmployee: "12", RowCustomer: "BONAP"},
+	}
+	for _, rs := range valid {
+		if err := CheckRowScope(rs); err != nil {
+			t.Errorf("CheckRowScope(%v): %v", rs, err)
+			continue
+		}
+		v, err := rs.Value()
+		if err != nil {
+			t.Errorf("%v.Value(): %v", rs, err)
+			continue
+		}
+		var got models.RowScope
+		if err := got.Scan(v); err != nil {
+			t.Errorf("Scan(%q): %v", v, err)
+			continue
+		}
+		if !maps.Equal(got, rs) {
+			t.Errorf("Scan(%q) = %v, want %v", v, got, rs)
+		}
+	}
+}
+
+func TestCheckRowScopeRejects(t *testing.T) {
+	invalid := []models.RowScope{
+		{"region": "WA"},
+		{RowEmployee: "0"},
+		{RowEmployee: "five"},
+		{RowCustomer: ""},
+		{RowCustomer: "ALFKI,X"},
+		{RowCustomer: "A=B"},
+		{RowCustomer: " ALFKI"},
+	}
+	for _, rs := range invalid {
+		if err := CheckRowScope(rs); err == nil {
+			t.Errorf("CheckRowScope(%v) = nil, want an error", rs)
+		}
+	}
+}
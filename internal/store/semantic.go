@@ -0,0 +1,144 @@
+package store
+
+import (
+	"strings"
+
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/sorting"
+)
+
+// The semantic layer names the measures and dimensions sales can be
+// aggregated by, so that POST /query and the /summary presets share one
+// definition of each. Backends supply the SQL for every term listed here.
+
+// Term is a measure or dimension of the semantic layer.
+type Term struct {
+	Name string
+	// Type is the value type of a dimension, used when filtering by it.
+	Type        filter.Type
+	Description string
+}
+
+// Measures are the aggregates the semantic layer can compute.
+var Measures = []Term{
+	{Name: "revenue", Description: "Sales after discounts"},
+	{Name: "order_count", Description: "Number of distinct orders"},
+	{Name: "units_sold", Description: "Units ordered"},
+	{Name: "freight", Description: "Freight charged; split evenly across an order's line items when grouping by product attributes"},
+	{Name: "avg_order", Description: "Revenue per order"},
+}
+
+// Dimensions are the attributes the semantic layer can group and filter by.
+var Dimensions = []Term{
+	{Name: "year", Type: filter.Int, Description: "Order year"},
+	{Name: "month", Type: filter.Text, Description: "Order month as YYYY-MM"},
+	{Name: "country", Type: filter.Text, Description: "Ship country"},
+	{Name: "category", Type: filter.Text, Description: "Product category name"},
+	{Name: "employee", Type: filter.Text, Description: "Employee full name"},
+	{Name: "shipper", Type: filter.Text, Description: "Shipper company name"},
+	{Name: "supplier", Type: filter.Text, Description: "Supplier company name"},
+	{Name: "customer", Type: filter.Text, Description: "Customer company name"},
+}
+
+// FindTerm returns the term called name in terms.
+func FindTerm(terms []Term, name string) (Term, bool) {
+	for _, t := range terms {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Term{}, false
+}
+
+// QueryFilters are accepted by Aggregate: every dimension, matched against
+// any of a comma-separated list, plus bounds on the order date.
+var QueryFilters = func() filter.Spec {
+	spec := filter.Spec{}
+	for _, d := range Dimensions {
+		spec = append(spec, filter.Field{Name: d.Name, Type: d.Type, Mode: filter.In, Description: "Filter by " + lowerFirst(d.Description)})
+	}
+	return append(spec, orderDateFilter)
+}()
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// Column selects a measure or dimension, named As in the output or by its
+// own name when As is empty.
+type Column struct {
+	Name string
+	As   string
+	// Text casts the values to text, for outputs read into strings whatever
+	// the term's type, such as the group_key of every sales summary.
+	Text bool
+}
+
+// Output is the name of c in the result.
+func (c Column) Output() string {
+	if c.As != "" {
+		return c.As
+	}
+	return c.Name
+}
+
+// Selection describes one aggregate over the semantic layer: a row per
+// combination of its dimensions, with a column for each of its measures.
+type Selection struct {
+	Dimensions []Column
+	Measures   []Column
+	// Filters maps the filter names a caller accepts to the dimensions they
+	// narrow. A nil map filters every dimension under its own name. The year
+	// and order_date filters are always available.
+	Filters map[string]string
+	// Order is the default sort over output names. The dimensions follow it
+	// so that the order is total.
+	Order sorting.Order
+}
+
+// Outputs lists the column names of s's result, dimensions first.
+func (s Selection) Outputs() []string {
+	names := []string{}
+	for _, c := range s.Dimensions {
+		names = append(names, c.Output())
+	}
+	for _, c := range s.Measures {
+		names = append(names, c.Output())
+	}
+	return names
+}
+
+// salesPreset is a sales-by-* summary: one dimension as group_key with its
+// revenue and order count, largest first.
+func salesPreset(dimension, filterName string) Selection {
+	return Selection{
+		Dimensions: []Column{{Name: dimension, As: "group_key", Text: true}},
+		Measures:   []Column{{Name: "revenue", As: "total_sales"}, {Name: "order_count"}},
+		Filters:    map[string]string{filterName: dimension},
+		Order:      sorting.Order{{Field: "total_sales", Desc: true}},
+	}
+}
+
+// Presets behind the /summary routes.
+var (
+	SalesByCountryPreset  = salesPreset("country", "country")
+	SalesByCategoryPreset = salesPreset("category", "category_name")
+	SalesByEmployeePreset = salesPreset("employee", "employee_name")
+	SalesByShipperPreset  = salesPreset("shipper", "company_name")
+	SalesByYearPreset     = Selection{
+		Dimensions: []Column{{Name: "year", As: "group_key", Text: true}},
+		Measures:   []Column{{Name: "revenue", As: "total_sales"}, {Name: "order_count"}},
+		Filters:    map[string]string{},
+	}
+)
+
+// SalesPresets are the sales-by-* presets by the dimension they group by.
+var SalesPresets = map[SalesSplit]Selection{
+	SplitByCountry:  SalesByCountryPreset,
+	SplitByCategory: SalesByCategoryPreset,
+	SplitByEmployee: SalesByEmployeePreset,
+	SplitByShipper:  SalesByShipperPreset,
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"slices"
+
+	"github.com/nicholasraynes/northwind-api/internal/sorting"
+)
+
+// salesSorts is shared by the sales-by-* summaries.
+var salesSorts = sorting.Spec{"group_key", "total_sales", "order_count"}
+
+// SalesComparisonSorts are accepted by CompareSales.
+var SalesComparisonSorts = append(slices.Clone(salesSorts), "prior_total_sales", "change", "pct_growth")
+
+// CustomerSorts are accepted by ListCustomers.
+var CustomerSorts = sorting.Spec{"customer_id", "company_name", "contact_name", "contact_title", "city", "region", "postal_code", "country"}
+
+// OrderSorts are accepted by ListOrders.
+var OrderSorts = sorting.Spec{"order_id", "customer_id", "customer_name", "employee_name", "order_date", "required_date", "shipped_date", "ship_via", "shipper_name", "freight", "ship_name", "ship_city", "ship_region", "ship_postal", "ship_country"}
+
+// OrderDetailSorts are accepted by ListOrderDetails.
+var OrderDetailSorts = sorting.Spec{"order_id", "product_id", "product_name", "category_name", "supplier_name", "unit_price", "quantity", "discount", "extended_price"}
+
+// ProductSorts are accepted by ListProducts.
+var ProductSorts = sorting.Spec{"product_id", "product_name", "supplier_id", "supplier_name", "category_id", "category_name", "unit_price", "units_in_stock", "discontinued"}
+
+// SupplierSorts are accepted by ListSuppliers.
+var SupplierSorts = sorting.Spec{"supplier_id", "company_name", "contact_name", "contact_title", "city", "country"}
+
+// SalesByCountrySorts are accepted by SalesByCountry.
+var SalesByCountrySorts = salesSorts
+
+// SalesByCategorySorts are accepted by SalesByCategory.
+var SalesByCategorySorts = salesSorts
+
+// SalesByEmployeeSorts are accepted by SalesByEmployee.
+var SalesByEmployeeSorts = salesSorts
+
+// SalesByShipperSorts are accepted by SalesByShipper.
+var SalesByShipperSorts = salesSorts
+
+// SalesByYearSorts are accepted by SalesByYear.
+var SalesByYearSorts = salesSorts
+
+// SalesOverTimeSorts are accepted by SalesOverTime.
+var SalesOverTimeSorts = sorting.Spec{"period", "period_start", "group_key", "total_sales", "order_count"}
+
+// TopCustomersSorts are accepted by TopCustomers.
+var TopCustomersSorts = sorting.Spec{"customer_id", "company_name", "country", "total_sales", "order_count", "average_order"}
+
+// CustomerOrdersSorts are accepted by CustomerOrders.
+var CustomerOrdersSorts = sorting.Spec{"customer_id", "company_name", "order_id", "order_date", "total_amount", "shipped_date", "country"}
+
+// CustomerLTVSorts are accepted by CustomerLTV.
+var CustomerLTVSorts = sorting.Spec{"customer_id", "company_name", "country", "total_sales", "first_order", "last_order", "order_count", "avg_order"}
+
+// CustomerRetentionSorts are accepted by CustomerRetention.
+var CustomerRetentionSorts = sorting.Spec{"customer_id", "company_name", "country", "first_order_year", "last_order_year", "order_count", "active_years", "repeat_customer"}
+
+// TopProductsSorts are accepted by TopProducts.
+var TopProductsSorts = sorting.Spec{"product_id", "product_name", "category_name", "supplier_name", "units_sold", "total_revenue", "average_price"}
+
+// SupplierPerformanceSorts are accepted by SupplierPerformance.
+var SupplierPerformanceSorts = sorting.Spec{"supplier_id", "supplier_name", "country", "product_count", "units_sold", "total_revenue", "average_price", "top_category"}
+
+// InventoryStatusSorts are accepted by InventoryStatus.
+var InventoryStatusSorts = sorting.Spec{"product_id", "product_name", "supplier_name", "category_name", "units_in_stock", "reorder_level", "discontinued", "needs_reorder"}
+
+// EmployeePerformanceSorts are accepted by EmployeePerformance.
+var EmployeePerformanceSorts = sorting.Spec{"employee_id", "full_name", "title", "country", "order_count", "total_revenue", "avg_order"}
+
+// ShippingCostsSorts are accepted by ShippingCosts.
+var ShippingCostsSorts = sorting.Spec{"shipper_id", "company_name", "total_orders", "total_freight", "avg_freight", "top_destination"}
+
+// DeliveryTimesSorts are accepted by DeliveryTimes.
+var DeliveryTimesSorts = sorting.Spec{"shipper_id", "shipper_name", "employee_id", "employee_name", "total_orders", "shipped_orders", "avg_delivery_days", "min_delivery_days", "max_delivery_days", "late_shipments", "on_time_rate", "open_orders", "overdue_orders"}
+
+// APIKeySorts are accepted by ListAPIKeys.
+var APIKeySorts = sorting.Spec{"key_id", "name", "created_at", "rotated_at", "last_used_at", "revoked_at"}
@@ -0,0 +1,186 @@
+// Package store defines the data access interfaces the HTTP handlers depend
+// on. The Postgres implementation lives in store/postgres; tests can supply
+// their own fakes.
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/nicholasraynes/northwind-api/internal/fieldset"
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+	"github.com/nicholasraynes/northwind-api/internal/models"
+	"github.com/nicholasraynes/northwind-api/internal/page"
+	"github.com/nicholasraynes/northwind-api/internal/sorting"
+)
+
+// Query is a filtered, sorted, paginated request against one collection. Its
+// filter set, sort order and projection must be parsed from the matching
+// *Filters, *Sorts and *Fields specs in this package.
+type Query struct {
+	Filters filter.Set
+	// Expr, when set, further narrows the rows by an expression over the
+	// model's JSON fields, such as an OData $filter.
+	Expr   filter.Expr
+	Sort   sorting.Order
+	Fields fieldset.Set
+	Page   page.Request
+}
+
+// Rows is an open cursor over one page of a collection, read as the database
+// returns it so large pages can be streamed. Callers must Close it.
+type Rows[T any] interface {
+	// Next advances to the next row, returning false at the end of the page
+	// or on error.
+	Next() bool
+	// Row returns the current row.
+	Row() T
+	// Total is the size of the whole filtered collection. It is valid once
+	// Next has returned true, or once it has returned false without error.
+	Total() int
+	Err() error
+	Close() error
+}
+
+// Result is one page of a collection along with the size of the whole
+// filtered collection.
+type Result[T any] struct {
+	Rows  []T
+	Total int
+}
+
+// Collect reads every row of rs into memory and closes it.
+func Collect[T any](rs Rows[T]) (Result[T], error) {
+	defer rs.Close()
+
+	res := Result[T]{Rows: []T{}}
+	for rs.Next() {
+		res.Rows = append(res.Rows, rs.Row())
+	}
+	if err := rs.Err(); err != nil {
+		return res, err
+	}
+	res.Total = rs.Total()
+	return res, nil
+}
+
+// Store is the full set of repositories used by the API.
+type Store interface {
+	CustomerStore
+	OrderStore
+	ProductStore
+	SupplierStore
+	AnalyticsStore
+	KeyStore
+	HealthStore
+}
+
+// CustomerStore reads and writes customers. Deleted customers are hidden but
+// kept, so their orders still resolve. Updates and deletes take the If-Match
+// value the client sent and fail with ErrVersionMismatch unless it names the
+// current version.
+type CustomerStore interface {
+	ListCustomers(ctx context.Context, q Query) (Rows[models.Customer], error)
+	GetCustomer(ctx context.Context, id string) (models.Customer, error)
+	CreateCustomer(ctx context.Context, c models.Customer) (models.Customer, error)
+	UpdateCustomer(ctx context.Context, c models.Customer, ifMatch string) (models.Customer, error)
+	DeleteCustomer(ctx context.Context, id string, ifMatch string) error
+}
+
+// OrderStore reads and writes orders. Every write runs in one transaction and
+// keeps products.units_in_stock in step with the order's line items.
+type OrderStore interface {
+	ListOrders(ctx context.Context, q Query) (Rows[models.Order], error)
+	ListOrderDetails(ctx context.Context, q Query) (Rows[models.OrderDetail], error)
+
+	GetOrder(ctx context.Context, id int) (models.Order, error)
+	CreateOrder(ctx context.Context, in models.OrderInput) (models.Order, []models.OrderDetail, error)
+	ReplaceOrder(ctx context.Context, id int, in models.OrderInput) (models.Order, error)
+	PatchOrder(ctx context.Context, id int, in models.OrderInput) (models.Order, error)
+	// DeleteOrder removes the order and its line items, returning their units
+	// to stock.
+	DeleteOrder(ctx context.Context, id int) error
+
+	// AddOrderDetails adds products that are not yet on the order.
+	AddOrderDetails(ctx context.Context, id int, lines []models.OrderDetailInput) ([]models.OrderDetail, error)
+	// ReplaceOrderDetails makes lines the order's complete set of line items.
+	ReplaceOrderDetails(ctx context.Context, id int, lines []models.OrderDetailInput) ([]models.OrderDetail, error)
+	// PatchOrderDetails changes line items already on the order.
+	PatchOrderDetails(ctx context.Context, id int, lines []models.OrderDetailInput) ([]models.OrderDetail, error)
+	// DeleteOrderDetails removes the given products from the order, or every
+	// line item when productIDs is empty.
+	DeleteOrderDetails(ctx context.Context, id int, productIDs []int) ([]models.OrderDetail, error)
+}
+
+// ProductStore reads and writes products, with the same soft delete and
+// version checks as CustomerStore. Create assigns the product id.
+type ProductStore interface {
+	ListProducts(ctx context.Context, q Query) (Rows[models.Product], error)
+	GetProduct(ctx context.Context, id int) (models.Product, error)
+	CreateProduct(ctx context.Context, p models.Product) (models.Product, error)
+	UpdateProduct(ctx context.Context, p models.Product, ifMatch string) (models.Product, error)
+	DeleteProduct(ctx context.Context, id int, ifMatch string) error
+}
+
+// SupplierStore reads and writes suppliers, with the same soft delete and
+// version checks as CustomerStore. Create assigns the supplier id.
+type SupplierStore interface {
+	ListSuppliers(ctx context.Context, q Query) (Rows[models.Supplier], error)
+	GetSupplier(ctx context.Context, id int) (models.Supplier, error)
+	CreateSupplier(ctx context.Context, s models.Supplier) (models.Supplier, error)
+	UpdateSupplier(ctx context.Context, s models.Supplier, ifMatch string) (models.Supplier, error)
+	DeleteSupplier(ctx context.Context, id int, ifMatch string) error
+}
+
+// AnalyticsStore serves the /summary and /analytics aggregates.
+type AnalyticsStore interface {
+	// Aggregate computes sel over the semantic layer. q's filters must be
+	// parsed from QueryFilters, or from a spec whose names sel.Filters maps;
+	// its sort order uses sel's output names.
+	Aggregate(ctx context.Context, sel Selection, q Query) (Rows[fieldset.Object], error)
+	SalesByCountry(ctx context.Context, q Query) (Rows[models.SalesSummary], error)
+	SalesByCategory(ctx context.Context, q Query) (Rows[models.SalesSummary], error)
+	SalesByEmployee(ctx context.Context, q Query) (Rows[models.SalesSummary], error)
+	SalesByYear(ctx context.Context, q Query) (Rows[models.SalesSummary], error)
+	SalesByShipper(ctx context.Context, q Query) (Rows[models.SalesSummary], error)
+	CompareSales(ctx context.Context, by SalesSplit, q Query, prior filter.Set) (Rows[models.SalesComparison], error)
+	SalesOverTime(ctx context.Context, q Query, g Granularity, split SalesSplit) (Rows[models.SalesPeriod], error)
+	TopCustomers(ctx context.Context, q Query) (Rows[models.TopCustomer], error)
+	CustomerOrders(ctx context.Context, q Query) (Rows[models.CustomerOrderSummary], error)
+	CustomerLTV(ctx context.Context, q Query) (Rows[models.CustomerLTV], error)
+	CustomerRetention(ctx context.Context, q Query) (Rows[models.CustomerRetention], error)
+	CustomerRetentionSummary(ctx context.Context, f filter.Set) (models.RetentionSummary, error)
+	TopProducts(ctx context.Context, q Query) (Rows[models.TopProduct], error)
+	SupplierPerformance(ctx context.Context, q Query) (Rows[models.SupplierPerformance], error)
+	InventoryStatus(ctx context.Context, q Query) (Rows[models.InventoryStatus], error)
+	EmployeePerformance(ctx context.Context, q Query) (Rows[models.EmployeePerformance], error)
+	ShippingCosts(ctx context.Context, q Query) (Rows[models.ShippingCosts], error)
+	DeliveryTimes(ctx context.Context, q Query, by DeliveryGrouping) (Rows[models.DeliveryTimes], error)
+}
+
+// KeyStore keeps the API keys callers authenticate with. Only a hash of each
+// key is stored. Revoked keys are kept, so their use stays on record, but no
+// longer authenticate and cannot be rotated.
+type KeyStore interface {
+	ListAPIKeys(ctx context.Context, q Query) (Rows[models.APIKey], error)
+	// CreateAPIKey stores k under hash and returns it as stored.
+	CreateAPIKey(ctx context.Context, k models.APIKey, hash string) (models.APIKey, error)
+	// RotateAPIKey replaces the key of a live API key, keeping its name and
+	// scopes.
+	RotateAPIKey(ctx context.Context, id int, prefix, hash string) (models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int) error
+	// UseAPIKey returns the live key stored under hash and records that it
+	// was used. It fails with ErrNotFound when there is none.
+	UseAPIKey(ctx context.Context, hash string) (models.APIKey, error)
+}
+
+// HealthStore reports whether the database can serve the API, for the
+// readiness check.
+type HealthStore interface {
+	// Ping checks that the database answers.
+	Ping(ctx context.Context) error
+	// MissingTables returns the tables the API reads that the database lacks.
+	MissingTables(ctx context.Context) ([]string, error)
+	// PoolStats describes the connection pool.
+	PoolStats() sql.DBStats
+}
@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/nicholasraynes/northwind-api/internal/db"
+)
+
+// returnedRows is the attribute for the rows a statement read, or affected.
+const returnedRows = attribute.Key("db.response.returned_rows")
+
+// DB is a db.Observer recording a client span for each statement, as a
+// child of the request's span, with the sanitized statement text, its rows
+// and the request's filters.
+var DB dbObserver
+
+type dbObserver struct{}
+
+func (dbObserver) Statement(ctx context.Context, query string) func(int64, error) {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		// Only trace statements of traced requests, not migrations or
+		// requests the sampler dropped.
+		return func(int64, error) {}
+	}
+	text := db.Sanitize(query)
+	op := operation(text)
+	_, span := tracer.Start(ctx, op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationName(op), semconv.DBQueryText(text)),
+		trace.WithAttributes(filters(ctx)...),
+	)
+	return func(rows int64, err error) {
+		span.SetAttributes(returnedRows.Int64(rows))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "statement failed")
+		}
+		span.End()
+	}
+}
+
+// operation is the statement's first keyword, such as SELECT or WITH, which
+// names its span.
+func operation(text string) string {
+	op, _, _ := strings.Cut(strings.TrimLeft(text, "("), " ")
+	return strings.ToUpper(op)
+}
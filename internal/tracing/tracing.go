@@ -0,0 +1,134 @@
+// Package tracing records OpenTelemetry traces of the API: a server span
+// per request, continuing the caller's trace from its W3C traceparent
+// header, and a child span per SQL statement the request runs.
+//
+// Setup installs the exporter OTEL_TRACES_EXPORTER names. Without one, the
+// spans are not recorded.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/nicholasraynes/northwind-api/internal/auth"
+	"github.com/nicholasraynes/northwind-api/internal/filter"
+)
+
+// serviceName names the service in traces unless OTEL_SERVICE_NAME does.
+const serviceName = "northwind-api"
+
+var tracer = otel.Tracer("github.com/nicholasraynes/northwind-api")
+
+// Setup installs the W3C trace context propagator and the exporter
+// OTEL_TRACES_EXPORTER names:
+//
+//	otlp    OTLP over HTTP, configured by the standard OTEL_EXPORTER_OTLP_*
+//	        variables, e.g. OTEL_EXPORTER_OTLP_ENDPOINT=http://localhost:4318
+//	stdout  JSON spans written to w, for trying it out offline
+//	none    no export; the default
+//
+// The returned func flushes and stops the exporter.
+func Setup(ctx context.Context, w io.Writer) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch name := strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")); name {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "stdout", "console":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(w), stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown OTEL_TRACES_EXPORTER %q; use otlp, stdout or none", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create trace exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("describe trace resource: %w", err)
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// Middleware starts a server span for each request, as a child of the span
+// its traceparent header names, if any. A request made in process, such as
+// an MCP tool call, is a child of the span already on its context.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+				semconv.UserAgentOriginal(c.Request.UserAgent()),
+			),
+		)
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		// Handlers replace the request context as they learn more about it.
+		ctx = c.Request.Context()
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		span.SetAttributes(filters(ctx)...)
+		if p, ok := auth.FromContext(ctx); ok {
+			span.SetAttributes(semconv.EnduserID(p.Subject))
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// filters are the request filters ctx carries, as span attributes named
+// after their query parameters.
+func filters(ctx context.Context) []attribute.KeyValue {
+	set, ok := filter.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	var attrs []attribute.KeyValue
+	for param, value := range set.Echo() {
+		attrs = append(attrs, attribute.String("northwind.filter."+param, fmt.Sprint(value)))
+	}
+	return attrs
+}